@@ -30,6 +30,7 @@ var checkRepo = dun.CheckRepo
 var planRepo = dun.PlanRepo
 var respondFn = dun.Respond
 var installRepo = dun.InstallRepo
+var updateBenchBaselines = dun.UpdateBenchBaselines
 var callHarnessFn = callHarnessImpl
 var callHarnessStreamingFn = callHarnessStreamingImpl
 var harnessModel string
@@ -66,8 +67,12 @@ func run(args []string, stdout io.Writer, stderr io.Writer) int {
 		return runTask(args[1:], stdout, stderr)
 	case "stamp":
 		return runStamp(args[1:], stdout, stderr)
+	case "bench":
+		return runBench(args[1:], stdout, stderr)
 	case "install":
 		return runInstall(args[1:], stdout, stderr)
+	case "plugin":
+		return runPlugin(args[1:], stdout, stderr)
 	case "loop":
 		return runLoop(args[1:], stdout, stderr)
 	case "version":
@@ -75,6 +80,9 @@ func run(args []string, stdout io.Writer, stderr io.Writer) int {
 	case "update":
 		return runUpdate(args[1:], stdout, stderr)
 	default:
+		if code, ok := runPluginCommand(args, stdout, stderr); ok {
+			return code
+		}
 		fmt.Fprintf(stderr, "unknown command: %s\n", args[0])
 		return dun.ExitUsageError
 	}
@@ -95,7 +103,9 @@ COMMANDS:
   review     Run multi-agent review with synthesis
   doctor     Diagnose harness and helper availability
   stamp      Update doc review stamps
+  bench      Accept current benchmark numbers as the go-bench baseline
   install    Install dun config and agent documentation
+  plugin     Manage, lint, test and lock plugins
   loop       Run autonomous loop with an agent harness
   version    Show version information
   update     Update dun to the latest version
@@ -127,6 +137,8 @@ CHECK MODE:
     --format     Output format: prompt, llm, json
     --automation Mode: manual, plan, auto, yolo (default: auto)
     --ignore-version  Skip .ddx-version check
+    --changed    Run Go tests/vet/coverage only on packages affected by uncommitted
+                 changes; --changed=<ref> diffs against a git ref (e.g., origin/main)
 
 TASK MODE:
   dun task <task-id> [options]
@@ -152,6 +164,8 @@ LOOP MODE:
     --verbose     Print prompts sent to harnesses and responses received
     --only        Comma-separated check IDs to include (supports * suffix)
     --ignore-version  Skip .ddx-version check
+    --changed     Run Go tests/vet/coverage only on packages affected by changes
+                  (--changed=<ref> diffs against a git ref)
 
   Quorum Options (multi-agent consensus):
     --quorum      Strategy: any, majority, unanimous, or number (e.g., 2)
@@ -194,6 +208,51 @@ STAMP:
   Options:
     --all        Stamp all docs with dun frontmatter
 
+BENCH:
+  dun bench --update-baseline
+
+  Runs every go-bench check, including those of sub-projects, and writes the
+  results to .dun/bench/<check-id>.json in the check's project (or the check's
+  baseline_file). Regressions are reported by 'dun check'.
+
+  Options:
+    --update-baseline  Write measured numbers as the new baseline
+    --config           Config file path (default .dun/config.yaml; also loads user config)
+
+PLUGIN:
+  dun plugin list [--format text|json]
+  dun plugin info <plugin-id>
+  dun plugin new <id> [--dir <path>]
+  dun plugin install <path|git-url|tarball> [--user] [--force]
+  dun plugin remove <plugin-id> [--user]
+  dun plugin lint [plugin dirs...]
+  dun plugin test [plugin dir] [--update] [--case <name>]
+  dun plugin lock
+  dun plugin verify [--format text|json]
+
+  dun <plugin-id> <command> [args...]
+
+  Plugins load from builtin < ddx cache < ~/.dun/plugins < .dun/plugins; a
+  higher source replaces a plugin with the same id. 'list' and 'info' show
+  where each plugin comes from and what it overrides. 'install' and 'remove'
+  act on .dun/plugins (or ~/.dun/plugins with --user). 'lint' validates a
+  manifest: unknown keys, unknown check types, missing prompt files and
+  invalid regexes. 'test' runs the plugin against each testdata/<case>/repo
+  fixture and compares the JSON result with testdata/<case>/expected.json;
+  --update rewrites the golden files. 'lock' records content hashes of the
+  active cache, user and project plugins and of the prompt override
+  directories in .dun/plugins.lock; 'verify' reports what changed since. When the lockfile exists, every run
+  checks it and warns, or fails with plugins.integrity: fail in config.
+
+  Active plugins can also add commands, run as 'dun <plugin-id> <command>'
+  after the plugins.lock check, and prompt partials that any plugin's prompt
+  includes with {{ template "<plugin-id>/<name>" . }}. Both are listed at the
+  end of this help.
+
+  A prompt is overridden by .dun/prompts/<plugin-id>/<prompt path> (or
+  ~/.dun/prompts/...); an override includes the original with
+  {{ template "base" . }}.
+
 DOCTOR:
   dun doctor
 
@@ -210,6 +269,7 @@ EXIT CODES:
   7  Quorum aborted (user intervention)
 `
 	fmt.Fprint(stdout, help)
+	fmt.Fprint(stdout, pluginHelp())
 	return dun.ExitSuccess
 }
 
@@ -234,6 +294,8 @@ func runCheck(args []string, stdout io.Writer, stderr io.Writer) int {
 	allChecks := fs.Bool("all", false, "include passing checks in prompt output")
 	automation := fs.String("automation", opts.AutomationMode, "automation mode (manual|plan|auto|yolo)")
 	ignoreVersion := fs.Bool("ignore-version", false, "skip .ddx-version check")
+	changed := &changedFlag{}
+	fs.Var(changed, "changed", "run Go checks only on packages affected by changes (optionally =<git-ref>)")
 	if err := fs.Parse(args); err != nil {
 		return dun.ExitUsageError
 	}
@@ -241,6 +303,7 @@ func runCheck(args []string, stdout io.Writer, stderr io.Writer) int {
 
 	opts.AgentMode = "prompt"
 	opts.AutomationMode = *automation
+	changed.apply(&opts)
 	if !*ignoreVersion {
 		if warn := checkDDXVersion(root); warn != "" {
 			fmt.Fprintln(stderr, warn)
@@ -268,7 +331,7 @@ func runCheck(args []string, stdout io.Writer, stderr io.Writer) int {
 						passCount++
 					}
 				}
-				plugins, err := activePlugins(root)
+				plugins, err := activePlugins(root, opts)
 				pluginsLine := "unknown"
 				if err == nil {
 					pluginsLine = strings.Join(plugins, ", ")
@@ -314,12 +377,17 @@ func runList(args []string, stdout io.Writer, stderr io.Writer) int {
 		return dun.ExitUsageError
 	}
 
-	if _, _, err := dun.LoadConfig(root, *configPath); err != nil {
+	cfg, loaded, err := dun.LoadConfig(root, *configPath)
+	if err != nil {
 		fmt.Fprintf(stderr, "dun list failed: config error: %v\n", err)
 		return dun.ExitConfigError
 	}
+	opts := dun.DefaultOptions()
+	if loaded {
+		opts = dun.ApplyConfig(opts, cfg)
+	}
 
-	plan, err := planRepo(root)
+	plan, err := planRepo(root, opts)
 	if err != nil {
 		fmt.Fprintf(stderr, "dun list failed: %v\n", err)
 		return dun.ExitCheckFailed
@@ -355,12 +423,17 @@ func runExplain(args []string, stdout io.Writer, stderr io.Writer) int {
 	}
 	target := fs.Arg(0)
 
-	if _, _, err := dun.LoadConfig(root, *configPath); err != nil {
+	cfg, loaded, err := dun.LoadConfig(root, *configPath)
+	if err != nil {
 		fmt.Fprintf(stderr, "dun explain failed: config error: %v\n", err)
 		return dun.ExitConfigError
 	}
+	opts := dun.DefaultOptions()
+	if loaded {
+		opts = dun.ApplyConfig(opts, cfg)
+	}
 
-	plan, err := planRepo(root)
+	plan, err := planRepo(root, opts)
 	if err != nil {
 		fmt.Fprintf(stderr, "dun explain failed: %v\n", err)
 		return dun.ExitCheckFailed
@@ -380,6 +453,9 @@ func runExplain(args []string, stdout io.Writer, stderr io.Writer) int {
 			fmt.Fprintf(stdout, "id: %s\n", check.ID)
 			fmt.Fprintf(stdout, "description: %s\n", check.Description)
 			fmt.Fprintf(stdout, "plugin: %s\n", check.PluginID)
+			if check.Scope != "" {
+				fmt.Fprintf(stdout, "scope: %s\n", check.Scope)
+			}
 			fmt.Fprintf(stdout, "type: %s\n", check.Type)
 			if check.Phase != "" {
 				fmt.Fprintf(stdout, "phase: %s\n", check.Phase)
@@ -489,6 +565,45 @@ func runStamp(args []string, stdout io.Writer, stderr io.Writer) int {
 	return dun.ExitSuccess
 }
 
+func runBench(args []string, stdout io.Writer, stderr io.Writer) int {
+	root := resolveRoot(".")
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	update := fs.Bool("update-baseline", false, "write measured numbers as the new baseline")
+	configPath := fs.String("config", "", "path to config file (default .dun/config.yaml if present; also loads user config)")
+	if err := fs.Parse(args); err != nil {
+		return dun.ExitUsageError
+	}
+	if !*update || fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "usage: dun bench --update-baseline")
+		return dun.ExitUsageError
+	}
+
+	cfg, loaded, err := dun.LoadConfig(root, *configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "dun bench failed: config error: %v\n", err)
+		return dun.ExitConfigError
+	}
+	opts := dun.DefaultOptions()
+	if loaded {
+		opts = dun.ApplyConfig(opts, cfg)
+	}
+
+	updates, err := updateBenchBaselines(root, opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "dun bench failed: %v\n", err)
+		return dun.ExitRuntimeError
+	}
+	if len(updates) == 0 {
+		fmt.Fprintln(stdout, "no go-bench checks active")
+		return dun.ExitSuccess
+	}
+	for _, update := range updates {
+		fmt.Fprintf(stdout, "baseline: %s %s (%d benchmarks)\n", update.CheckID, update.Path, update.Benchmarks)
+	}
+	return dun.ExitSuccess
+}
+
 func runInstall(args []string, stdout io.Writer, stderr io.Writer) int {
 	fs := flag.NewFlagSet("install", flag.ContinueOnError)
 	fs.SetOutput(stderr)
@@ -540,6 +655,8 @@ func runLoop(args []string, stdout io.Writer, stderr io.Writer) int {
 	verbose := fs.Bool("verbose", false, "print prompts and harness responses")
 	only := fs.String("only", "", "comma-separated list of check IDs to include")
 	ignoreVersion := fs.Bool("ignore-version", false, "skip .ddx-version check")
+	changed := &changedFlag{}
+	fs.Var(changed, "changed", "run Go checks only on packages affected by changes (optionally =<git-ref>)")
 
 	// Quorum flags
 	quorumFlag := fs.String("quorum", "", "quorum strategy: any, majority, unanimous, or number")
@@ -554,6 +671,7 @@ func runLoop(args []string, stdout io.Writer, stderr io.Writer) int {
 	}
 	_ = *configPath
 	_ = *similarity // Reserved for future use in conflict detection
+	changed.apply(&opts)
 	if *harness == "" {
 		if opts.AgentHarness != "" {
 			*harness = opts.AgentHarness
@@ -1033,6 +1151,37 @@ func issuesToBeadsCandidates(issues []dun.Issue) []beadsCandidate {
 	return candidates
 }
 
+// changedFlag is --changed or --changed=<ref>. The bare form selects affected
+// mode against the working tree; a value names the git ref to diff against.
+type changedFlag struct {
+	set  bool
+	base string
+}
+
+func (f *changedFlag) String() string {
+	return f.base
+}
+
+func (f *changedFlag) Set(value string) error {
+	f.set = true
+	if value != "true" {
+		f.base = value
+	}
+	return nil
+}
+
+func (f *changedFlag) IsBoolFlag() bool {
+	return true
+}
+
+func (f *changedFlag) apply(opts *dun.Options) {
+	if !f.set || f.base == "false" {
+		return
+	}
+	opts.GoAffected = true
+	opts.ChangedBase = f.base
+}
+
 func filterChecksByID(checks []dun.CheckResult, only string) []dun.CheckResult {
 	parts := splitCSV(only)
 	if len(parts) == 0 {
@@ -1116,8 +1265,8 @@ func findConfigFlag(args []string) string {
 	return ""
 }
 
-func activePlugins(root string) ([]string, error) {
-	plan, err := planRepo(root)
+func activePlugins(root string, opts dun.Options) ([]string, error) {
+	plan, err := planRepo(root, opts)
 	if err != nil {
 		return nil, err
 	}
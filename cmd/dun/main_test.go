@@ -61,6 +61,39 @@ func TestCheckUsesConfigAgentAuto(t *testing.T) {
 	}
 }
 
+func TestCheckPassesChangedBaseToOptions(t *testing.T) {
+	root := setupEmptyRepo(t)
+
+	origCheck := checkRepo
+	checkRepo = func(_ string, opts dun.Options) (dun.Result, error) {
+		if opts.ChangedBase != "origin/main" || !opts.GoAffected {
+			t.Fatalf("expected affected mode against origin/main, got %+v", opts)
+		}
+		return dun.Result{}, nil
+	}
+	t.Cleanup(func() { checkRepo = origCheck })
+
+	runInDir(t, root, []string{"check", "--changed=origin/main"})
+}
+
+func TestChangedFlagBareSelectsWorkingTree(t *testing.T) {
+	var opts dun.Options
+	flag := &changedFlag{}
+	if err := flag.Set("true"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	flag.apply(&opts)
+	if !opts.GoAffected || opts.ChangedBase != "" {
+		t.Fatalf("expected affected mode against HEAD, got %+v", opts)
+	}
+
+	opts = dun.Options{}
+	(&changedFlag{}).apply(&opts)
+	if opts.GoAffected {
+		t.Fatalf("expected affected mode off without flag")
+	}
+}
+
 func TestCheckResolvesRepoRootFromSubdir(t *testing.T) {
 	root := setupRepoFromFixture(t, "helix-alignment")
 	agentCmd := "test-agent-cmd"
@@ -352,7 +385,7 @@ func TestRunListParseError(t *testing.T) {
 func TestRunListPlanError(t *testing.T) {
 	root := setupEmptyRepo(t)
 	orig := planRepo
-	planRepo = func(_ string) (dun.Plan, error) {
+	planRepo = func(_ string, _ dun.Options) (dun.Plan, error) {
 		return dun.Plan{}, errors.New("boom")
 	}
 	t.Cleanup(func() { planRepo = orig })
@@ -397,6 +430,27 @@ func TestRunListTextAndJSON(t *testing.T) {
 	}
 }
 
+func TestRunListAppliesConfig(t *testing.T) {
+	root := setupEmptyRepo(t)
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "drift.yaml"), []byte("go:\n  generate_drift: true\n"), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := runInDirWithWriters(t, root, []string{"list"}, &stdout, &stderr)
+	if code != 0 || strings.Contains(stdout.String(), "go-generate-drift") {
+		t.Fatalf("expected go-generate-drift off by default, got %d %q", code, stdout.String())
+	}
+	stdout.Reset()
+	code = runInDirWithWriters(t, root, []string{"list", "--config", "drift.yaml"}, &stdout, &stderr)
+	if code != 0 || !strings.Contains(stdout.String(), "go-generate-drift") {
+		t.Fatalf("expected go-generate-drift from --config, got %d %q (%s)", code, stdout.String(), stderr.String())
+	}
+}
+
 func TestRunListConfigError(t *testing.T) {
 	root := setupEmptyRepo(t)
 	cfgPath := filepath.Join(root, "bad.yaml")
@@ -438,7 +492,7 @@ func TestRunExplainConfigError(t *testing.T) {
 func TestRunExplainPlanError(t *testing.T) {
 	root := setupEmptyRepo(t)
 	orig := planRepo
-	planRepo = func(_ string) (dun.Plan, error) {
+	planRepo = func(_ string, _ dun.Options) (dun.Plan, error) {
 		return dun.Plan{}, errors.New("boom")
 	}
 	t.Cleanup(func() { planRepo = orig })
@@ -454,7 +508,7 @@ func TestRunExplainPlanError(t *testing.T) {
 func TestRunExplainJSONEncodeError(t *testing.T) {
 	root := setupEmptyRepo(t)
 	orig := planRepo
-	planRepo = func(_ string) (dun.Plan, error) {
+	planRepo = func(_ string, _ dun.Options) (dun.Plan, error) {
 		return dun.Plan{
 			Checks: []dun.PlannedCheck{{ID: "check", Description: "desc", Type: "rule-set"}},
 		}, nil
@@ -472,7 +526,7 @@ func TestRunExplainJSONEncodeError(t *testing.T) {
 func TestRunExplainOutputsExtraFields(t *testing.T) {
 	root := setupEmptyRepo(t)
 	orig := planRepo
-	planRepo = func(_ string) (dun.Plan, error) {
+	planRepo = func(_ string, _ dun.Options) (dun.Plan, error) {
 		return dun.Plan{
 			Checks: []dun.PlannedCheck{
 				{
@@ -654,6 +708,43 @@ func TestRunInstallDryRunAndError(t *testing.T) {
 	}
 }
 
+func TestRunBenchRequiresUpdateFlag(t *testing.T) {
+	root := setupEmptyRepo(t)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := runInDirWithWriters(t, root, []string{"bench"}, &stdout, &stderr)
+	if code != dun.ExitUsageError {
+		t.Fatalf("expected code %d, got %d", dun.ExitUsageError, code)
+	}
+}
+
+func TestRunBenchUpdatesBaselines(t *testing.T) {
+	orig := updateBenchBaselines
+	updateBenchBaselines = func(root string, _ dun.Options) ([]dun.BenchBaselineUpdate, error) {
+		return []dun.BenchBaselineUpdate{{CheckID: "go-bench", Path: ".dun/bench/go-bench.json", Benchmarks: 3}}, nil
+	}
+	t.Cleanup(func() { updateBenchBaselines = orig })
+
+	root := setupEmptyRepo(t)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := runInDirWithWriters(t, root, []string{"bench", "--update-baseline"}, &stdout, &stderr)
+	if code != dun.ExitSuccess {
+		t.Fatalf("expected success, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "baseline: go-bench .dun/bench/go-bench.json (3 benchmarks)") {
+		t.Fatalf("unexpected output %q", stdout.String())
+	}
+
+	updateBenchBaselines = func(root string, _ dun.Options) ([]dun.BenchBaselineUpdate, error) {
+		return nil, errors.New("boom")
+	}
+	code = runInDirWithWriters(t, root, []string{"bench", "--update-baseline"}, &stdout, &stderr)
+	if code != dun.ExitRuntimeError {
+		t.Fatalf("expected code %d, got %d", dun.ExitRuntimeError, code)
+	}
+}
+
 func TestFindConfigFlag(t *testing.T) {
 	if got := findConfigFlag([]string{"--config", "path.yaml"}); got != "path.yaml" {
 		t.Fatalf("expected config path, got %q", got)
@@ -849,7 +940,7 @@ func TestRunCheckPromptAllPassIncludesCountsAndPlugins(t *testing.T) {
 	t.Cleanup(func() { checkRepo = origCheck })
 
 	origPlan := planRepo
-	planRepo = func(_ string) (dun.Plan, error) {
+	planRepo = func(_ string, _ dun.Options) (dun.Plan, error) {
 		return dun.Plan{
 			Checks: []dun.PlannedCheck{
 				{ID: "pass-a", PluginID: "alpha"},
@@ -1397,6 +1488,32 @@ func TestPrintPromptOmitsPromptContent(t *testing.T) {
 	}
 }
 
+func TestPrintPromptMarksFlakyTests(t *testing.T) {
+	setRepoStateHash(t, "deadbeef")
+	checks := []dun.CheckResult{
+		{
+			ID:     "go-test",
+			Status: "fail",
+			Signal: "1 failing tests, 1 flaky",
+			Issues: []dun.Issue{
+				{Summary: "TestA failed", Path: "example.com/app", Type: dun.GoTestIssueFailing},
+				{Summary: "TestB is flaky (passed on retry 1)", Path: "example.com/app", Type: dun.GoTestIssueFlaky},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	printPrompt(&buf, checks, "auto", "/test/root")
+	output := buf.String()
+
+	if !strings.Contains(output, "go-test#1@deadbeef: TestA failed (example.com/app) (why: blocking") {
+		t.Fatalf("expected failing test as blocking task, got:\n%s", output)
+	}
+	if !strings.Contains(output, "[flaky] TestB is flaky") || !strings.Contains(output, "why: flaky: passed on retry") {
+		t.Fatalf("expected flaky test marked separately, got:\n%s", output)
+	}
+}
+
 func TestPrintPromptIncludesBeadsCandidates(t *testing.T) {
 	setRepoStateHash(t, "deadbeef")
 	checks := []dun.CheckResult{
@@ -2356,3 +2473,251 @@ func TestCallHarnessImplDefaultMode(t *testing.T) {
 		t.Fatalf("expected automation 'unknown-mode' passed through, got %q", capturedAutomation)
 	}
 }
+
+func TestRunPluginUsage(t *testing.T) {
+	root := setupEmptyRepo(t)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	if code := runInDirWithWriters(t, root, []string{"plugin"}, &stdout, &stderr); code != dun.ExitUsageError {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+	if code := runInDirWithWriters(t, root, []string{"plugin", "bogus"}, &stdout, &stderr); code != dun.ExitUsageError {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+	if code := runInDirWithWriters(t, root, []string{"plugin", "info"}, &stdout, &stderr); code != dun.ExitUsageError {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+}
+
+func TestRunPluginNewLintListRemove(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := setupEmptyRepo(t)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := runInDirWithWriters(t, root, []string{"plugin", "new", "demo"}, &stdout, &stderr)
+	if code != dun.ExitSuccess {
+		t.Fatalf("plugin new failed (%d): %s", code, stderr.String())
+	}
+	pluginDir := filepath.Join(root, ".dun", "plugins", "demo")
+	if _, err := os.Stat(filepath.Join(pluginDir, "plugin.yaml")); err != nil {
+		t.Fatalf("expected scaffolded manifest: %v", err)
+	}
+
+	stdout.Reset()
+	code = runInDirWithWriters(t, root, []string{"plugin", "lint", pluginDir}, &stdout, &stderr)
+	if code != dun.ExitSuccess || stdout.Len() != 0 {
+		t.Fatalf("expected clean lint, got %d: %s", code, stdout.String())
+	}
+
+	stdout.Reset()
+	code = runInDirWithWriters(t, root, []string{"plugin", "list"}, &stdout, &stderr)
+	if code != dun.ExitSuccess || !strings.Contains(stdout.String(), "demo\t0.1.0\tproject\tactive") {
+		t.Fatalf("expected demo in plugin list, got %d: %s", code, stdout.String())
+	}
+
+	stdout.Reset()
+	code = runInDirWithWriters(t, root, []string{"plugin", "info", "go"}, &stdout, &stderr)
+	if code != dun.ExitSuccess || !strings.Contains(stdout.String(), "source: builtin") {
+		t.Fatalf("expected builtin go plugin info, got %d: %s", code, stdout.String())
+	}
+
+	stdout.Reset()
+	code = runInDirWithWriters(t, root, []string{"plugin", "remove", "demo"}, &stdout, &stderr)
+	if code != dun.ExitSuccess || !strings.Contains(stdout.String(), "removed:") {
+		t.Fatalf("plugin remove failed (%d): %s", code, stderr.String())
+	}
+}
+
+func TestRunPluginLintFails(t *testing.T) {
+	root := setupEmptyRepo(t)
+	manifest := "id: bad\nversion: \"1\"\nchecks:\n  - id: x\n    type: nope\n"
+	if err := os.WriteFile(filepath.Join(root, "plugin.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := runInDirWithWriters(t, root, []string{"plugin", "lint"}, &stdout, &stderr)
+	if code != dun.ExitCheckFailed {
+		t.Fatalf("expected check failure, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), `unknown check type "nope"`) {
+		t.Fatalf("expected unknown type report, got %s", stdout.String())
+	}
+}
+
+func TestRunPluginInstallFlagsAfterSource(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := setupEmptyRepo(t)
+	src := t.TempDir()
+	manifest := "id: inst\nversion: \"1\"\nchecks:\n  - id: x\n    type: command\n    command: echo ok\n"
+	if err := os.WriteFile(filepath.Join(src, "plugin.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := runInDirWithWriters(t, root, []string{"plugin", "install", src, "--user"}, &stdout, &stderr)
+	if code != dun.ExitSuccess {
+		t.Fatalf("install failed (%d): %s", code, stderr.String())
+	}
+	home, _ := os.UserHomeDir()
+	if _, err := os.Stat(filepath.Join(home, ".dun", "plugins", "inst", "plugin.yaml")); err != nil {
+		t.Fatalf("expected user install: %v", err)
+	}
+}
+
+func TestRunPluginTestUpdateAndPass(t *testing.T) {
+	root := setupEmptyRepo(t)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	if code := runInDirWithWriters(t, root, []string{"plugin", "new", "fixtures"}, &stdout, &stderr); code != dun.ExitSuccess {
+		t.Fatalf("plugin new failed (%d): %s", code, stderr.String())
+	}
+	pluginDir := filepath.Join(root, ".dun", "plugins", "fixtures")
+
+	stdout.Reset()
+	code := runInDirWithWriters(t, root, []string{"plugin", "test", pluginDir}, &stdout, &stderr)
+	if code != dun.ExitCheckFailed || !strings.Contains(stdout.String(), "fail\tbasic") {
+		t.Fatalf("expected missing golden to fail, got %d: %s", code, stdout.String())
+	}
+
+	stdout.Reset()
+	code = runInDirWithWriters(t, root, []string{"plugin", "test", "--update", pluginDir}, &stdout, &stderr)
+	if code != dun.ExitSuccess || !strings.Contains(stdout.String(), "updated\tbasic") {
+		t.Fatalf("expected update, got %d: %s", code, stdout.String())
+	}
+
+	stdout.Reset()
+	code = runInDirWithWriters(t, root, []string{"plugin", "test", pluginDir}, &stdout, &stderr)
+	if code != dun.ExitSuccess || !strings.Contains(stdout.String(), "pass\tbasic") {
+		t.Fatalf("expected pass, got %d: %s", code, stdout.String())
+	}
+}
+
+func TestRunPluginLockAndVerify(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := setupEmptyRepo(t)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	if code := runInDirWithWriters(t, root, []string{"plugin", "verify"}, &stdout, &stderr); code != dun.ExitConfigError {
+		t.Fatalf("expected config error without lockfile, got %d", code)
+	}
+	if code := runInDirWithWriters(t, root, []string{"plugin", "new", "pinned"}, &stdout, &stderr); code != dun.ExitSuccess {
+		t.Fatalf("plugin new failed (%d): %s", code, stderr.String())
+	}
+
+	stdout.Reset()
+	code := runInDirWithWriters(t, root, []string{"plugin", "lock"}, &stdout, &stderr)
+	if code != dun.ExitSuccess || !strings.Contains(stdout.String(), "locked: pinned 0.1.0 (project) sha256:") {
+		t.Fatalf("expected lock output, got %d: %s", code, stdout.String())
+	}
+
+	stdout.Reset()
+	if code := runInDirWithWriters(t, root, []string{"plugin", "verify"}, &stdout, &stderr); code != dun.ExitSuccess {
+		t.Fatalf("expected clean verify, got %d: %s", code, stdout.String())
+	}
+
+	prompt := filepath.Join(root, ".dun", "plugins", "pinned", "prompts", "review.md")
+	if err := os.WriteFile(prompt, []byte("tampered"), 0644); err != nil {
+		t.Fatalf("write prompt: %v", err)
+	}
+	stdout.Reset()
+	code = runInDirWithWriters(t, root, []string{"plugin", "verify"}, &stdout, &stderr)
+	if code != dun.ExitCheckFailed || !strings.Contains(stdout.String(), "changed\tplugin pinned (project) changed since it was locked") {
+		t.Fatalf("expected drift, got %d: %s", code, stdout.String())
+	}
+}
+
+func TestRunPluginContributedCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := setupEmptyRepo(t)
+	pluginDir := filepath.Join(root, ".dun", "plugins", "tools")
+	if err := os.MkdirAll(pluginDir, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	manifest := `id: tools
+version: "1"
+commands:
+  - name: greet
+    description: Greet someone
+    usage: <name>
+    run: echo "hello $1"
+partials:
+  tone: tone.md
+`
+	if err := os.WriteFile(filepath.Join(pluginDir, "plugin.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(pluginDir, "tone.md"), []byte("Be brief."), 0644); err != nil {
+		t.Fatalf("write partial: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := runInDirWithWriters(t, root, []string{"tools", "greet", "dun"}, &stdout, &stderr)
+	if code != dun.ExitSuccess || strings.TrimSpace(stdout.String()) != "hello dun" {
+		t.Fatalf("expected greeting, got %d: %s %s", code, stdout.String(), stderr.String())
+	}
+
+	stderr.Reset()
+	code = runInDirWithWriters(t, root, []string{"tools", "wave"}, &stdout, &stderr)
+	if code != dun.ExitUsageError || !strings.Contains(stderr.String(), "unknown tools command: wave") ||
+		!strings.Contains(stderr.String(), "dun tools greet <name>  Greet someone") {
+		t.Fatalf("expected usage listing, got %d: %s", code, stderr.String())
+	}
+
+	stdout.Reset()
+	code = runInDirWithWriters(t, root, []string{"help"}, &stdout, &stderr)
+	if code != dun.ExitSuccess || !strings.Contains(stdout.String(), "PLUGIN COMMANDS:\n  dun tools greet <name>  Greet someone") ||
+		!strings.Contains(stdout.String(), `{{ template "tools/tone" . }}`) {
+		t.Fatalf("expected plugin commands and partials in help, got %s", stdout.String())
+	}
+}
+
+func TestPluginCommandsNeedActiveLockedPlugins(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := setupEmptyRepo(t)
+	writePlugin := func(id string, extra string) {
+		dir := filepath.Join(root, ".dun", "plugins", id)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		manifest := "id: " + id + "\nversion: \"1\"\n" + extra + "commands:\n  - name: hi\n    run: echo hi\n"
+		if err := os.WriteFile(filepath.Join(dir, "plugin.yaml"), []byte(manifest), 0644); err != nil {
+			t.Fatalf("write manifest: %v", err)
+		}
+	}
+	writePlugin("tools", "")
+	writePlugin("rusty", "triggers:\n  - type: path-exists\n    value: Cargo.toml\n")
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := runInDirWithWriters(t, root, []string{"rusty", "hi"}, &stdout, &stderr)
+	if code != dun.ExitUsageError || !strings.Contains(stderr.String(), "unknown command: rusty") {
+		t.Fatalf("expected inactive plugin command to be unknown, got %d: %s", code, stderr.String())
+	}
+
+	if code := runInDirWithWriters(t, root, []string{"plugin", "lock"}, &stdout, &stderr); code != dun.ExitSuccess {
+		t.Fatalf("plugin lock failed (%d): %s", code, stderr.String())
+	}
+	config := filepath.Join(root, ".dun", "config.yaml")
+	if err := os.WriteFile(config, []byte("version: \"1\"\nplugins:\n  integrity: fail\n"), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	writePlugin("tools", "description: tampered\n")
+	stdout.Reset()
+	stderr.Reset()
+	code = runInDirWithWriters(t, root, []string{"tools", "hi"}, &stdout, &stderr)
+	if code != dun.ExitUsageError || stdout.Len() != 0 || !strings.Contains(stderr.String(), "unknown command: tools") {
+		t.Fatalf("expected tampered plugin command to be refused, got %d: %s %s", code, stdout.String(), stderr.String())
+	}
+
+	stderr.Reset()
+	code = runInDirWithWriters(t, root, []string{"chek"}, &stdout, &stderr)
+	if code != dun.ExitUsageError || strings.TrimSpace(stderr.String()) != "unknown command: chek" {
+		t.Fatalf("expected a typo to stay an unknown command, got %d: %s", code, stderr.String())
+	}
+}
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/easel/dun/internal/dun"
+)
+
+const pluginUsage = "usage: dun plugin <list|info|new|install|remove|lint|test|lock|verify> [options]"
+
+func runPlugin(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) < 1 {
+		fmt.Fprintln(stderr, pluginUsage)
+		return dun.ExitUsageError
+	}
+	switch args[0] {
+	case "list":
+		return runPluginList(args[1:], stdout, stderr)
+	case "info":
+		return runPluginInfo(args[1:], stdout, stderr)
+	case "new":
+		return runPluginNew(args[1:], stdout, stderr)
+	case "install":
+		return runPluginInstall(args[1:], stdout, stderr)
+	case "remove":
+		return runPluginRemove(args[1:], stdout, stderr)
+	case "lint":
+		return runPluginLint(args[1:], stdout, stderr)
+	case "test":
+		return runPluginTest(args[1:], stdout, stderr)
+	case "lock":
+		return runPluginLock(args[1:], stdout, stderr)
+	case "verify":
+		return runPluginVerify(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "unknown plugin command: %s\n%s\n", args[0], pluginUsage)
+		return dun.ExitUsageError
+	}
+}
+
+func runPluginList(args []string, stdout io.Writer, stderr io.Writer) int {
+	root := resolveRoot(".")
+	fs := flag.NewFlagSet("plugin list", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output format (text|json)")
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return dun.ExitUsageError
+	}
+
+	infos, err := dun.ListPlugins(root)
+	if err != nil {
+		fmt.Fprintf(stderr, "dun plugin list failed: %v\n", err)
+		return dun.ExitRuntimeError
+	}
+	if *format == "json" {
+		if err := json.NewEncoder(stdout).Encode(infos); err != nil {
+			fmt.Fprintf(stderr, "encode json: %v\n", err)
+			return dun.ExitRuntimeError
+		}
+		return dun.ExitSuccess
+	}
+	for _, info := range infos {
+		fmt.Fprintf(stdout, "%s\t%s\t%s\t%s\n", info.ID, info.Version, info.Source, pluginState(info))
+	}
+	return dun.ExitSuccess
+}
+
+// pluginState describes whether a listed plugin is the one in use.
+func pluginState(info dun.PluginInfo) string {
+	switch {
+	case info.Error != "":
+		return "invalid: " + info.Error
+	case info.Shadowed:
+		return "shadowed"
+	case len(info.Overrides) > 0:
+		return "active (overrides " + strings.Join(info.Overrides, ", ") + ")"
+	default:
+		return "active"
+	}
+}
+
+func runPluginInfo(args []string, stdout io.Writer, stderr io.Writer) int {
+	root := resolveRoot(".")
+	fs := flag.NewFlagSet("plugin info", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output format (text|json)")
+	positionals, err := parseInterspersed(fs, args)
+	if err != nil {
+		return dun.ExitUsageError
+	}
+	if len(positionals) != 1 {
+		fmt.Fprintln(stderr, "usage: dun plugin info <plugin-id>")
+		return dun.ExitUsageError
+	}
+
+	active, chain, err := dun.FindPlugin(root, positionals[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "dun plugin info failed: %v\n", err)
+		return dun.ExitCheckFailed
+	}
+	if *format == "json" {
+		out := struct {
+			dun.PluginInfo
+			Sources []dun.PluginInfo `json:"sources"`
+		}{active, chain}
+		if err := json.NewEncoder(stdout).Encode(out); err != nil {
+			fmt.Fprintf(stderr, "encode json: %v\n", err)
+			return dun.ExitRuntimeError
+		}
+		return dun.ExitSuccess
+	}
+	fmt.Fprintf(stdout, "id: %s\n", active.ID)
+	if active.Version != "" {
+		fmt.Fprintf(stdout, "version: %s\n", active.Version)
+	}
+	if active.Description != "" {
+		fmt.Fprintf(stdout, "description: %s\n", active.Description)
+	}
+	fmt.Fprintf(stdout, "source: %s\n", active.Source)
+	if active.Path != "" {
+		fmt.Fprintf(stdout, "path: %s\n", active.Path)
+	}
+	if len(active.Checks) > 0 {
+		fmt.Fprintf(stdout, "checks: %s\n", strings.Join(active.Checks, ", "))
+	}
+	if len(active.CheckTypes) > 0 {
+		fmt.Fprintf(stdout, "check_types: %s\n", strings.Join(active.CheckTypes, ", "))
+	}
+	if len(active.Commands) > 0 {
+		fmt.Fprintf(stdout, "commands: %s\n", strings.Join(active.Commands, ", "))
+	}
+	if len(active.Partials) > 0 {
+		fmt.Fprintf(stdout, "partials: %s\n", strings.Join(active.Partials, ", "))
+	}
+	if len(active.Requires) > 0 {
+		fmt.Fprintf(stdout, "requires: %s\n", strings.Join(active.Requires, ", "))
+	}
+	fmt.Fprintln(stdout, "sources:")
+	for _, info := range chain {
+		location := info.Path
+		if location == "" {
+			location = "(embedded)"
+		}
+		fmt.Fprintf(stdout, "  %s\t%s\t%s\n", info.Source, location, pluginState(info))
+	}
+	return dun.ExitSuccess
+}
+
+func runPluginNew(args []string, stdout io.Writer, stderr io.Writer) int {
+	root := resolveRoot(".")
+	fs := flag.NewFlagSet("plugin new", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dir := fs.String("dir", "", "directory to create the plugin in (default .dun/plugins)")
+	positionals, err := parseInterspersed(fs, args)
+	if err != nil {
+		return dun.ExitUsageError
+	}
+	if len(positionals) != 1 {
+		fmt.Fprintln(stderr, "usage: dun plugin new <id> [--dir <path>]")
+		return dun.ExitUsageError
+	}
+	target := *dir
+	if target == "" {
+		target = dun.PluginDirs(root)[dun.PluginSourceProject]
+	}
+
+	created, err := dun.ScaffoldPlugin(target, positionals[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "dun plugin new failed: %v\n", err)
+		return dun.ExitRuntimeError
+	}
+	fmt.Fprintf(stdout, "created: %s\n", created)
+	fmt.Fprintf(stdout, "next: dun plugin test --update %s\n", created)
+	return dun.ExitSuccess
+}
+
+func runPluginInstall(args []string, stdout io.Writer, stderr io.Writer) int {
+	root := resolveRoot(".")
+	fs := flag.NewFlagSet("plugin install", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	user := fs.Bool("user", false, "install into ~/.dun/plugins instead of the project")
+	force := fs.Bool("force", false, "replace an installed plugin with the same id")
+	positionals, err := parseInterspersed(fs, args)
+	if err != nil {
+		return dun.ExitUsageError
+	}
+	if len(positionals) != 1 {
+		fmt.Fprintln(stderr, "usage: dun plugin install <path|git-url|tarball> [--user] [--force]")
+		return dun.ExitUsageError
+	}
+	target, ok := pluginInstallDir(root, *user)
+	if !ok {
+		fmt.Fprintln(stderr, "dun plugin install failed: cannot determine home directory")
+		return dun.ExitRuntimeError
+	}
+
+	info, err := dun.InstallPlugin(positionals[0], target, *force)
+	if err != nil {
+		fmt.Fprintf(stderr, "dun plugin install failed: %v\n", err)
+		return dun.ExitRuntimeError
+	}
+	fmt.Fprintf(stdout, "installed: %s %s -> %s\n", info.ID, info.Version, info.Path)
+	return dun.ExitSuccess
+}
+
+func runPluginRemove(args []string, stdout io.Writer, stderr io.Writer) int {
+	root := resolveRoot(".")
+	fs := flag.NewFlagSet("plugin remove", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	user := fs.Bool("user", false, "remove from ~/.dun/plugins instead of the project")
+	positionals, err := parseInterspersed(fs, args)
+	if err != nil {
+		return dun.ExitUsageError
+	}
+	if len(positionals) != 1 {
+		fmt.Fprintln(stderr, "usage: dun plugin remove <plugin-id> [--user]")
+		return dun.ExitUsageError
+	}
+	target, ok := pluginInstallDir(root, *user)
+	if !ok {
+		fmt.Fprintln(stderr, "dun plugin remove failed: cannot determine home directory")
+		return dun.ExitRuntimeError
+	}
+
+	removed, err := dun.RemovePlugin(target, positionals[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "dun plugin remove failed: %v\n", err)
+		return dun.ExitRuntimeError
+	}
+	fmt.Fprintf(stdout, "removed: %s\n", removed)
+	return dun.ExitSuccess
+}
+
+func runPluginLint(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("plugin lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output format (text|json)")
+	positionals, err := parseInterspersed(fs, args)
+	if err != nil {
+		return dun.ExitUsageError
+	}
+	if len(positionals) == 0 {
+		positionals = []string{"."}
+	}
+
+	code := dun.ExitSuccess
+	all := map[string][]dun.Issue{}
+	for _, dir := range positionals {
+		issues, err := dun.LintPlugin(dir)
+		if err != nil {
+			fmt.Fprintf(stderr, "dun plugin lint failed: %v\n", err)
+			return dun.ExitRuntimeError
+		}
+		all[dir] = issues
+		for _, issue := range issues {
+			if issue.Severity == "fail" {
+				code = dun.ExitCheckFailed
+			}
+			if *format != "json" {
+				fmt.Fprintf(stdout, "%s:%d: %s: %s\n", filepath.Join(dir, issue.Path), issue.Line, issue.Severity, issue.Summary)
+			}
+		}
+	}
+	if *format == "json" {
+		if err := json.NewEncoder(stdout).Encode(all); err != nil {
+			fmt.Fprintf(stderr, "encode json: %v\n", err)
+			return dun.ExitRuntimeError
+		}
+	}
+	return code
+}
+
+func runPluginTest(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("plugin test", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	update := fs.Bool("update", false, "rewrite expected.json from the current results")
+	only := fs.String("case", "", "run only this fixture case")
+	positionals, err := parseInterspersed(fs, args)
+	if err != nil {
+		return dun.ExitUsageError
+	}
+	if len(positionals) > 1 {
+		fmt.Fprintln(stderr, "usage: dun plugin test [plugin dir] [--update] [--case <name>]")
+		return dun.ExitUsageError
+	}
+	dir := "."
+	if len(positionals) == 1 {
+		dir = positionals[0]
+	}
+
+	cases, err := dun.RunPluginTests(dir, dun.PluginTestOptions{Update: *update, Case: *only})
+	if err != nil {
+		fmt.Fprintf(stderr, "dun plugin test failed: %v\n", err)
+		return dun.ExitRuntimeError
+	}
+	code := dun.ExitSuccess
+	for _, c := range cases {
+		fmt.Fprintf(stdout, "%s\t%s\n", c.Status, c.Name)
+		if c.Diff != "" {
+			fmt.Fprintf(stdout, "  %s\n", strings.ReplaceAll(c.Diff, "\n", "\n  "))
+		}
+		if c.Status == dun.PluginTestFail {
+			code = dun.ExitCheckFailed
+		}
+	}
+	return code
+}
+
+func runPluginLock(args []string, stdout io.Writer, stderr io.Writer) int {
+	root := resolveRoot(".")
+	fs := flag.NewFlagSet("plugin lock", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args); err != nil {
+		return dun.ExitUsageError
+	}
+
+	lock, err := dun.LockPlugins(root)
+	if err != nil {
+		fmt.Fprintf(stderr, "dun plugin lock failed: %v\n", err)
+		return dun.ExitRuntimeError
+	}
+	ids := make([]string, 0, len(lock.Plugins))
+	for id := range lock.Plugins {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		locked := lock.Plugins[id]
+		fmt.Fprintf(stdout, "locked: %s %s (%s) %s\n", id, locked.Version, locked.Source, locked.Hash)
+	}
+	fmt.Fprintf(stdout, "wrote %s (%d plugins)\n", dun.PluginLockPath, len(ids))
+	return dun.ExitSuccess
+}
+
+func runPluginVerify(args []string, stdout io.Writer, stderr io.Writer) int {
+	root := resolveRoot(".")
+	fs := flag.NewFlagSet("plugin verify", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output format (text|json)")
+	if err := fs.Parse(args); err != nil {
+		return dun.ExitUsageError
+	}
+
+	drift, err := dun.VerifyPlugins(root)
+	if err != nil {
+		fmt.Fprintf(stderr, "dun plugin verify failed: %v\n", err)
+		return dun.ExitConfigError
+	}
+	if *format == "json" {
+		if err := json.NewEncoder(stdout).Encode(drift); err != nil {
+			fmt.Fprintf(stderr, "encode json: %v\n", err)
+			return dun.ExitRuntimeError
+		}
+	} else {
+		for _, d := range drift {
+			fmt.Fprintf(stdout, "%s\t%s\n", d.Kind, d.String())
+		}
+		if len(drift) == 0 {
+			fmt.Fprintf(stdout, "all plugins match %s\n", dun.PluginLockPath)
+		}
+	}
+	if len(drift) > 0 {
+		return dun.ExitCheckFailed
+	}
+	return dun.ExitSuccess
+}
+
+// pluginInstallDir returns the project or user plugin directory.
+func pluginInstallDir(root string, user bool) (string, bool) {
+	source := dun.PluginSourceProject
+	if user {
+		source = dun.PluginSourceUser
+	}
+	dir, ok := dun.PluginDirs(root)[source]
+	return dir, ok
+}
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments and returns the positionals.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positionals []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positionals, nil
+		}
+		positionals = append(positionals, args[0])
+		args = args[1:]
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/easel/dun/internal/dun"
+)
+
+// loadCommandPlugins loads the plugins whose commands and partials dun
+// offers in root: the active ones, after the plugins.lock check.
+func loadCommandPlugins(root string) ([]dun.Plugin, error) {
+	opts := dun.DefaultOptions()
+	cfg, loaded, err := dun.LoadConfig(root, "")
+	if err != nil {
+		return nil, err
+	}
+	if loaded {
+		opts = dun.ApplyConfig(opts, cfg)
+	}
+	return dun.LoadActivePlugins(root, opts)
+}
+
+// runPluginCommand runs "dun <plugin-id> <command> [args...]". ok is false
+// when no active plugin with that ID contributes commands, including when
+// the plugins cannot be loaded or fail the plugins.lock check, so a mistyped
+// builtin command is still reported as unknown.
+func runPluginCommand(args []string, stdout io.Writer, stderr io.Writer) (code int, ok bool) {
+	root := resolveRoot(".")
+	plugins, err := loadCommandPlugins(root)
+	if err != nil {
+		return 0, false
+	}
+	var commands []dun.PluginCLICommand
+	for _, c := range dun.CollectPluginCommands(plugins) {
+		if c.PluginID == args[0] {
+			commands = append(commands, c)
+		}
+	}
+	if len(commands) == 0 {
+		return 0, false
+	}
+
+	if len(args) < 2 || args[1] == "help" || args[1] == "--help" || args[1] == "-h" {
+		fmt.Fprintf(stderr, "usage: dun %s <command> [args...]\n\n", args[0])
+		printPluginCommands(stderr, commands)
+		return dun.ExitUsageError, true
+	}
+	for _, c := range commands {
+		if c.Command.Name != args[1] {
+			continue
+		}
+		code, err := dun.RunPluginCommand(root, c, args[2:], dun.PluginCommandIO{Stdin: os.Stdin, Stdout: stdout, Stderr: stderr})
+		if err != nil {
+			fmt.Fprintf(stderr, "dun %s %s failed: %v\n", args[0], args[1], err)
+			return dun.ExitRuntimeError, true
+		}
+		return code, true
+	}
+	fmt.Fprintf(stderr, "unknown %s command: %s\n\n", args[0], args[1])
+	printPluginCommands(stderr, commands)
+	return dun.ExitUsageError, true
+}
+
+// printPluginCommands writes one aligned line per command.
+func printPluginCommands(w io.Writer, commands []dun.PluginCLICommand) {
+	usages := make([]string, len(commands))
+	width := 0
+	for i, c := range commands {
+		usages[i] = strings.TrimSpace(fmt.Sprintf("dun %s %s %s", c.PluginID, c.Command.Name, c.Command.Usage))
+		width = max(width, len(usages[i]))
+	}
+	for i, c := range commands {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, usages[i], c.Command.Description)
+	}
+}
+
+// pluginHelp describes the commands and prompt partials plugins contribute,
+// for 'dun help'.
+func pluginHelp() string {
+	plugins, err := loadCommandPlugins(resolveRoot("."))
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	if commands := dun.CollectPluginCommands(plugins); len(commands) > 0 {
+		b.WriteString("\nPLUGIN COMMANDS:\n")
+		printPluginCommands(&b, commands)
+	}
+	if partials := dun.CollectPromptPartials(plugins); len(partials) > 0 {
+		b.WriteString("\nPROMPT PARTIALS:\n")
+		for _, name := range partials {
+			fmt.Fprintf(&b, "  {{ template %q . }}\n", name)
+		}
+	}
+	return b.String()
+}
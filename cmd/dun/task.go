@@ -229,7 +229,7 @@ func buildTaskGroup(check dun.CheckResult, stateHash string) taskGroup {
 			group.Tasks = append(group.Tasks, taskItem{
 				ID:      taskIDForIssue(check.ID, i+1, stateHash),
 				Summary: truncateText(issueSummary(issue), maxTaskSummaryBytes),
-				Why:     truncateText(taskReasonForCheck(check), maxTaskReasonBytes),
+				Why:     truncateText(taskReasonForIssue(check, issue), maxTaskReasonBytes),
 			})
 		}
 		return group
@@ -264,9 +264,20 @@ func issueSummary(issue dun.Issue) string {
 	if summary == "" {
 		summary = "issue"
 	}
+	if issue.Type == dun.GoTestIssueFlaky {
+		summary = "[flaky] " + summary
+	}
 	return summary
 }
 
+// taskReasonForIssue keeps flaky tests from being mistaken for regressions.
+func taskReasonForIssue(check dun.CheckResult, issue dun.Issue) string {
+	if issue.Type == dun.GoTestIssueFlaky {
+		return "flaky: passed on retry; stabilize the test rather than changing behavior"
+	}
+	return taskReasonForCheck(check)
+}
+
 func taskSummaryForCheck(check dun.CheckResult) string {
 	if strings.TrimSpace(check.Detail) != "" {
 		return check.Detail
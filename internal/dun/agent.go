@@ -26,9 +26,13 @@ type AgentResponse struct {
 }
 
 type Issue struct {
-	ID      string `json:"id"`
-	Summary string `json:"summary"`
-	Path    string `json:"path"`
+	ID       string `json:"id"`
+	Summary  string `json:"summary"`
+	Path     string `json:"path"`
+	Line     int    `json:"line,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Type     string `json:"type,omitempty"`
 }
 
 type PromptInput struct {
@@ -138,7 +142,7 @@ func buildPromptEnvelope(root string, plugin Plugin, def CheckDefinition, config
 		return PromptEnvelope{}, err
 	}
 
-	promptText, schemaText, err := renderPromptText(plugin, config, def.ID, inputs, automationMode)
+	promptText, schemaText, err := renderPromptText(root, plugin, config, def.ID, inputs, automationMode)
 	if err != nil {
 		return PromptEnvelope{}, err
 	}
@@ -163,14 +167,12 @@ func buildPromptEnvelope(root string, plugin Plugin, def CheckDefinition, config
 	}, nil
 }
 
-func renderPromptText(plugin Plugin, config AgentCheckConfig, checkID string, inputs []PromptInput, automationMode string) (string, string, error) {
-	tmplText, err := loadPromptTemplate(plugin, config.Prompt)
-	if err != nil {
+func renderPromptText(root string, plugin Plugin, config AgentCheckConfig, checkID string, inputs []PromptInput, automationMode string) (string, string, error) {
+	tmpl := template.New("prompt")
+	if err := addPromptPartials(tmpl, plugin.partials); err != nil {
 		return "", "", err
 	}
-
-	tmpl, err := template.New("prompt").Parse(tmplText)
-	if err != nil {
+	if err := parsePromptTemplate(tmpl, root, plugin, config.Prompt); err != nil {
 		return "", "", err
 	}
 
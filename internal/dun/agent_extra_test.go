@@ -81,7 +81,7 @@ func TestRenderPromptTextWithSchema(t *testing.T) {
 	plugin := Plugin{FS: os.DirFS(dir), Base: "."}
 	check := Check{ID: "id", Prompt: "prompt.md", ResponseSchema: "schema.json"}
 
-	text, schema, err := renderPromptText(plugin, AgentCheckConfig{Prompt: check.Prompt, Inputs: check.Inputs, ResponseSchema: check.ResponseSchema}, check.ID, nil, "auto")
+	text, schema, err := renderPromptText(dir, plugin, AgentCheckConfig{Prompt: check.Prompt, Inputs: check.Inputs, ResponseSchema: check.ResponseSchema}, check.ID, nil, "auto")
 	if err != nil {
 		t.Fatalf("render prompt: %v", err)
 	}
@@ -392,7 +392,7 @@ func TestRenderPromptTextNoSchema(t *testing.T) {
 	writeFile(t, filepath.Join(dir, "prompt.md"), "hello")
 	plugin := Plugin{FS: os.DirFS(dir), Base: "."}
 	check := Check{ID: "id", Prompt: "prompt.md"}
-	text, schema, err := renderPromptText(plugin, AgentCheckConfig{Prompt: check.Prompt, Inputs: check.Inputs, ResponseSchema: check.ResponseSchema}, check.ID, nil, "auto")
+	text, schema, err := renderPromptText(dir, plugin, AgentCheckConfig{Prompt: check.Prompt, Inputs: check.Inputs, ResponseSchema: check.ResponseSchema}, check.ID, nil, "auto")
 	if err != nil {
 		t.Fatalf("render prompt: %v", err)
 	}
@@ -409,7 +409,7 @@ func TestRenderPromptTextParseError(t *testing.T) {
 	writeFile(t, filepath.Join(dir, "prompt.md"), "{{ .CheckID")
 	plugin := Plugin{FS: os.DirFS(dir), Base: "."}
 	check := Check{ID: "id", Prompt: "prompt.md"}
-	if _, _, err := renderPromptText(plugin, AgentCheckConfig{Prompt: check.Prompt, Inputs: check.Inputs, ResponseSchema: check.ResponseSchema}, check.ID, nil, "auto"); err == nil {
+	if _, _, err := renderPromptText(dir, plugin, AgentCheckConfig{Prompt: check.Prompt, Inputs: check.Inputs, ResponseSchema: check.ResponseSchema}, check.ID, nil, "auto"); err == nil {
 		t.Fatalf("expected parse error")
 	}
 }
@@ -419,7 +419,7 @@ func TestRenderPromptTextExecuteError(t *testing.T) {
 	writeFile(t, filepath.Join(dir, "prompt.md"), "{{ index .Inputs 0 }}")
 	plugin := Plugin{FS: os.DirFS(dir), Base: "."}
 	check := Check{ID: "id", Prompt: "prompt.md"}
-	if _, _, err := renderPromptText(plugin, AgentCheckConfig{Prompt: check.Prompt, Inputs: check.Inputs, ResponseSchema: check.ResponseSchema}, check.ID, nil, "auto"); err == nil {
+	if _, _, err := renderPromptText(dir, plugin, AgentCheckConfig{Prompt: check.Prompt, Inputs: check.Inputs, ResponseSchema: check.ResponseSchema}, check.ID, nil, "auto"); err == nil {
 		t.Fatalf("expected execute error")
 	}
 }
@@ -429,7 +429,7 @@ func TestRenderPromptTextSchemaError(t *testing.T) {
 	writeFile(t, filepath.Join(dir, "prompt.md"), "hello")
 	plugin := Plugin{FS: selectiveFS{root: dir, deny: "schema.json"}, Base: "."}
 	check := Check{ID: "id", Prompt: "prompt.md", ResponseSchema: "schema.json"}
-	if _, _, err := renderPromptText(plugin, AgentCheckConfig{Prompt: check.Prompt, Inputs: check.Inputs, ResponseSchema: check.ResponseSchema}, check.ID, nil, "auto"); err == nil {
+	if _, _, err := renderPromptText(dir, plugin, AgentCheckConfig{Prompt: check.Prompt, Inputs: check.Inputs, ResponseSchema: check.ResponseSchema}, check.ID, nil, "auto"); err == nil {
 		t.Fatalf("expected schema load error")
 	}
 }
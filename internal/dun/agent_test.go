@@ -21,7 +21,7 @@ func TestRenderPromptIncludesAutomationMode(t *testing.T) {
 	config := AgentCheckConfig{
 		Prompt: "prompt.md",
 	}
-	text, _, err := renderPromptText(plugin, config, "test", nil, "yolo")
+	text, _, err := renderPromptText(dir, plugin, config, "test", nil, "yolo")
 	if err != nil {
 		t.Fatalf("render prompt: %v", err)
 	}
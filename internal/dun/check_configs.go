@@ -33,8 +33,86 @@ type CommandConfig struct {
 	IssueFields  IssueFieldMap
 }
 
+type GoTestConfig struct {
+	Race         bool
+	Shuffle      string
+	Count        int
+	Failfast     bool
+	FlakeRetries int
+	Packages     []string
+}
+
+type GoVetConfig struct {
+	Packages []string
+}
+
+type GoBenchConfig struct {
+	Pattern      string
+	Packages     []string
+	Count        int
+	Tolerance    float64
+	BaselineFile string
+	Timeout      string
+}
+
+type GoBuildMatrixConfig struct {
+	Targets   []BuildTarget
+	GoCommand string
+	Timeout   string
+	Env       map[string]string
+}
+
 type GoCoverageConfig struct {
-	Rules []Rule
+	Rules    []Rule
+	Packages []string
+}
+
+type GoAPIDiffConfig struct {
+	Baseline string
+}
+
+type GoHygieneConfig struct {
+	Fix string
+}
+
+type GoVulncheckConfig struct {
+	Timeout string
+	Env     map[string]string
+}
+
+type ImportRulesConfig struct {
+	Rules []ImportRule
+}
+
+type NodeCheckConfig struct {
+	Timeout string
+	Env     map[string]string
+}
+
+type PythonCheckConfig struct {
+	Timeout string
+	Env     map[string]string
+}
+
+type ShellCheckConfig struct {
+	Timeout string
+	Env     map[string]string
+}
+
+type DockerfileCheckConfig struct {
+	Timeout string
+	Env     map[string]string
+}
+
+type TerraformCheckConfig struct {
+	Timeout string
+	Env     map[string]string
+}
+
+type RustCheckConfig struct {
+	Packages []string
+	Timeout  string
+	Env      map[string]string
 }
 
 type SpecBindingConfig struct {
@@ -47,6 +125,14 @@ type IntegrationContractConfig struct {
 	ContractRules []ContractRule
 }
 
+type OpenAPILintConfig struct {
+	Files []string
+}
+
+type ProtoLintConfig struct {
+	Files []string
+}
+
 type ConflictDetectionConfig struct {
 	Tracking      TrackingConfig
 	ConflictRules []ConflictRule
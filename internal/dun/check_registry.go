@@ -12,10 +12,17 @@ type CheckType interface {
 	Run(root string, def CheckDefinition, cfg CheckConfig, opts Options, plugin Plugin) (CheckResult, error)
 }
 
+// CheckExpander is implemented by check types that split one manifest check
+// into several planned checks, such as one per workspace member.
+type CheckExpander interface {
+	Expand(root string, spec Check, opts Options) []Check
+}
+
 type checkHandler struct {
 	typeName string
 	decode   func(Check) (CheckConfig, error)
 	run      func(string, CheckDefinition, CheckConfig, Options, Plugin) (CheckResult, error)
+	expand   func(string, Check, Options) []Check
 }
 
 func (h checkHandler) Type() string {
@@ -36,6 +43,13 @@ func (h checkHandler) Run(root string, def CheckDefinition, cfg CheckConfig, opt
 	return h.run(root, def, cfg, opts, plugin)
 }
 
+func (h checkHandler) Expand(root string, spec Check, opts Options) []Check {
+	if h.expand == nil {
+		return []Check{spec}
+	}
+	return h.expand(root, spec, opts)
+}
+
 var checkRegistry = map[string]CheckType{}
 
 func RegisterCheckType(handler CheckType) {
@@ -100,8 +100,24 @@ func init() {
 
 	RegisterCheckType(checkHandler{
 		typeName: "go-test",
-		run: func(root string, def CheckDefinition, _ CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
-			return runGoTestCheck(root, def)
+		decode: func(spec Check) (CheckConfig, error) {
+			return GoTestConfig{
+				Race:         spec.Race,
+				Shuffle:      spec.Shuffle,
+				Count:        spec.Count,
+				Failfast:     spec.Failfast,
+				FlakeRetries: spec.FlakeRetries,
+			}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, opts Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(GoTestConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("go-test config missing")
+			}
+			return runScopedGoCheck(root, def, opts, func(packages []string) (CheckResult, error) {
+				config.Packages = packages
+				return runGoTestCheck(root, def, config)
+			})
 		},
 	})
 
@@ -115,14 +131,19 @@ func init() {
 			if !ok {
 				return CheckResult{}, fmt.Errorf("go-coverage config missing")
 			}
-			return runGoCoverageCheck(root, def, config, opts)
+			return runScopedGoCheck(root, def, opts, func(packages []string) (CheckResult, error) {
+				config.Packages = packages
+				return runGoCoverageCheck(root, def, config, opts)
+			})
 		},
 	})
 
 	RegisterCheckType(checkHandler{
 		typeName: "go-vet",
-		run: func(root string, def CheckDefinition, _ CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
-			return runGoVetCheck(root, def)
+		run: func(root string, def CheckDefinition, _ CheckConfig, opts Options, _ Plugin) (CheckResult, error) {
+			return runScopedGoCheck(root, def, opts, func(packages []string) (CheckResult, error) {
+				return runGoVetCheck(root, def, GoVetConfig{Packages: packages})
+			})
 		},
 	})
 
@@ -133,6 +154,341 @@ func init() {
 		},
 	})
 
+	RegisterCheckType(checkHandler{
+		typeName: "go-fmt",
+		decode:   decodeGoHygieneConfig,
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(GoHygieneConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("go-fmt config missing")
+			}
+			return runGoFmtCheck(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "go-mod-tidy",
+		decode:   decodeGoHygieneConfig,
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(GoHygieneConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("go-mod-tidy config missing")
+			}
+			return runGoModTidyCheck(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "go-generate-drift",
+		decode:   decodeGoHygieneConfig,
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(GoHygieneConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("go-generate-drift config missing")
+			}
+			return runGoGenerateDriftCheck(root, def, config)
+		},
+		// Generators can be slow or need tools, so the check is opt-in.
+		expand: func(_ string, spec Check, opts Options) []Check {
+			if !opts.GoGenerateDrift {
+				return nil
+			}
+			return []Check{spec}
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "go-bench",
+		decode: func(spec Check) (CheckConfig, error) {
+			return GoBenchConfig{
+				Pattern:      spec.Bench,
+				Packages:     spec.Packages,
+				Count:        spec.Count,
+				Tolerance:    spec.Tolerance,
+				BaselineFile: spec.BaselineFile,
+				Timeout:      spec.Timeout,
+			}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(GoBenchConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("go-bench config missing")
+			}
+			return runGoBenchCheck(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "go-build-matrix",
+		decode: func(spec Check) (CheckConfig, error) {
+			switch spec.GoCommand {
+			case "", "build", "vet":
+			default:
+				return nil, fmt.Errorf("go-build-matrix go_command must be build or vet, got %q", spec.GoCommand)
+			}
+			return GoBuildMatrixConfig{
+				Targets:   spec.Targets,
+				GoCommand: spec.GoCommand,
+				Timeout:   spec.Timeout,
+				Env:       spec.Env,
+			}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(GoBuildMatrixConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("go-build-matrix config missing")
+			}
+			return runGoBuildMatrixCheck(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "go-apidiff",
+		decode: func(spec Check) (CheckConfig, error) {
+			return GoAPIDiffConfig{Baseline: spec.Baseline}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(GoAPIDiffConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("go-apidiff config missing")
+			}
+			return runGoAPIDiffCheck(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "import-rules",
+		decode: func(spec Check) (CheckConfig, error) {
+			return ImportRulesConfig{Rules: spec.ImportRules}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(ImportRulesConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("import-rules config missing")
+			}
+			return runImportRulesCheck(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "node-test",
+		decode: func(spec Check) (CheckConfig, error) {
+			return NodeCheckConfig{Timeout: spec.Timeout, Env: spec.Env}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(NodeCheckConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("node-test config missing")
+			}
+			return runNodeTest(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "node-lint",
+		decode: func(spec Check) (CheckConfig, error) {
+			return NodeCheckConfig{Timeout: spec.Timeout, Env: spec.Env}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(NodeCheckConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("node-lint config missing")
+			}
+			return runNodeLint(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "node-typecheck",
+		decode: func(spec Check) (CheckConfig, error) {
+			return NodeCheckConfig{Timeout: spec.Timeout, Env: spec.Env}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(NodeCheckConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("node-typecheck config missing")
+			}
+			return runNodeTypecheck(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "python-lint",
+		decode: func(spec Check) (CheckConfig, error) {
+			return PythonCheckConfig{Timeout: spec.Timeout, Env: spec.Env}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(PythonCheckConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("python-lint config missing")
+			}
+			return runPythonLint(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "python-typecheck",
+		decode: func(spec Check) (CheckConfig, error) {
+			return PythonCheckConfig{Timeout: spec.Timeout, Env: spec.Env}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(PythonCheckConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("python-typecheck config missing")
+			}
+			return runPythonTypecheck(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "python-test",
+		decode: func(spec Check) (CheckConfig, error) {
+			return PythonCheckConfig{Timeout: spec.Timeout, Env: spec.Env}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(PythonCheckConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("python-test config missing")
+			}
+			return runPythonTest(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "rust-check",
+		decode: func(spec Check) (CheckConfig, error) {
+			return RustCheckConfig{Packages: spec.Packages, Timeout: spec.Timeout, Env: spec.Env}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(RustCheckConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("rust-check config missing")
+			}
+			return runRustCheck(root, def, config)
+		},
+		expand: expandCargoWorkspace,
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "rust-clippy",
+		decode: func(spec Check) (CheckConfig, error) {
+			return RustCheckConfig{Packages: spec.Packages, Timeout: spec.Timeout, Env: spec.Env}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(RustCheckConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("rust-clippy config missing")
+			}
+			return runRustClippy(root, def, config)
+		},
+		expand: expandCargoWorkspace,
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "rust-test",
+		decode: func(spec Check) (CheckConfig, error) {
+			return RustCheckConfig{Packages: spec.Packages, Timeout: spec.Timeout, Env: spec.Env}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(RustCheckConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("rust-test config missing")
+			}
+			return runRustTest(root, def, config)
+		},
+		expand: expandCargoWorkspace,
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "shell-syntax",
+		decode: func(spec Check) (CheckConfig, error) {
+			return ShellCheckConfig{Timeout: spec.Timeout, Env: spec.Env}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(ShellCheckConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("shell-syntax config missing")
+			}
+			return runShellSyntaxCheck(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "github-workflows",
+		run: func(root string, def CheckDefinition, _ CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			return runGitHubWorkflowsCheck(root, def)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "dockerfile-lint",
+		decode: func(spec Check) (CheckConfig, error) {
+			return DockerfileCheckConfig{Timeout: spec.Timeout, Env: spec.Env}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(DockerfileCheckConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("dockerfile-lint config missing")
+			}
+			return runDockerfileLint(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "terraform-fmt",
+		decode: func(spec Check) (CheckConfig, error) {
+			return TerraformCheckConfig{Timeout: spec.Timeout, Env: spec.Env}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(TerraformCheckConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("terraform-fmt config missing")
+			}
+			return runTerraformFmt(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "terraform-validate",
+		decode: func(spec Check) (CheckConfig, error) {
+			return TerraformCheckConfig{Timeout: spec.Timeout, Env: spec.Env}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(TerraformCheckConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("terraform-validate config missing")
+			}
+			return runTerraformValidate(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "task-targets",
+		run: func(root string, def CheckDefinition, _ CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			// Planning replaces this check with one command check per target.
+			return CheckResult{
+				ID:     def.ID,
+				Status: "skip",
+				Signal: "no task runner targets",
+			}, nil
+		},
+		expand: expandTaskTargets,
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "go-vulncheck",
+		decode: func(spec Check) (CheckConfig, error) {
+			return GoVulncheckConfig{Timeout: spec.Timeout, Env: spec.Env}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(GoVulncheckConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("go-vulncheck config missing")
+			}
+			return runGoVulncheck(root, def, config)
+		},
+	})
+
 	RegisterCheckType(checkHandler{
 		typeName: "beads-ready",
 		run: func(root string, def CheckDefinition, _ CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
@@ -196,6 +552,34 @@ func init() {
 		},
 	})
 
+	RegisterCheckType(checkHandler{
+		typeName: "openapi-lint",
+		decode: func(spec Check) (CheckConfig, error) {
+			return OpenAPILintConfig{Files: spec.Files}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(OpenAPILintConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("openapi-lint config missing")
+			}
+			return runOpenAPILint(root, def, config)
+		},
+	})
+
+	RegisterCheckType(checkHandler{
+		typeName: "proto-lint",
+		decode: func(spec Check) (CheckConfig, error) {
+			return ProtoLintConfig{Files: spec.Files}, nil
+		},
+		run: func(root string, def CheckDefinition, cfg CheckConfig, _ Options, _ Plugin) (CheckResult, error) {
+			config, ok := cfg.(ProtoLintConfig)
+			if !ok {
+				return CheckResult{}, fmt.Errorf("proto-lint config missing")
+			}
+			return runProtoLint(root, def, config)
+		},
+	})
+
 	RegisterCheckType(checkHandler{
 		typeName: "conflict-detection",
 		decode: func(spec Check) (CheckConfig, error) {
@@ -242,3 +626,7 @@ func init() {
 		},
 	})
 }
+
+func decodeGoHygieneConfig(spec Check) (CheckConfig, error) {
+	return GoHygieneConfig{Fix: spec.Fix}, nil
+}
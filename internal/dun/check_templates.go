@@ -0,0 +1,192 @@
+package dun
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkTemplateData is what templated check fields are rendered with.
+type checkTemplateData struct {
+	Root   string            // Absolute path of the repo or sub-project
+	Module string            // Go module path, or package.json name
+	Env    map[string]string // Process environment
+	Config map[string]any    // Config settings over the defaults
+
+	base        string
+	changedOnce sync.Once
+	changed     []string
+}
+
+// ChangedFiles lists the files changed since the configured base (HEAD by
+// default) plus untracked files, relative to Root. It runs git on first use
+// and is empty outside a git repository.
+func (d *checkTemplateData) ChangedFiles() []string {
+	d.changedOnce.Do(func() {
+		files, err := goChangedFilesFunc(d.Root, d.base)
+		if err == nil {
+			d.changed = files
+		}
+	})
+	return d.changed
+}
+
+func newCheckTemplateData(root string, opts Options) *checkTemplateData {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		absRoot = root
+	}
+	var defaults map[string]any
+	_ = yaml.Unmarshal([]byte(DefaultConfigYAML), &defaults)
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+	return &checkTemplateData{
+		Root:   absRoot,
+		Module: projectModule(absRoot),
+		Env:    env,
+		Config: mergeConfigValues(defaults, opts.Config),
+		base:   opts.ChangedBase,
+	}
+}
+
+// projectModule names the project in root: its Go module path, or the name
+// in package.json.
+func projectModule(root string) string {
+	if module := readModulePath(filepath.Join(root, "go.mod")); module != "" {
+		return module
+	}
+	raw, err := os.ReadFile(filepath.Join(root, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(raw, &pkg) != nil {
+		return ""
+	}
+	return pkg.Name
+}
+
+var checkTemplateFuncs = template.FuncMap{
+	"join":       strings.Join,
+	"shellquote": shellQuoteAll,
+}
+
+// shellQuoteAll quotes each argument for sh and joins them with spaces.
+func shellQuoteAll(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+// renderCheck renders the templated fields of a check: command, env values,
+// inputs, gate files and rule and condition paths. An input or gate file that
+// renders to several lines becomes one entry per line, so
+// "{{range .ChangedFiles}}{{.}}\n{{end}}" lists the changed files. Fields
+// without "{{" are left alone. The manifest's slices are not modified.
+func renderCheck(check Check, data *checkTemplateData) (Check, error) {
+	var err error
+	render := func(field string, text string) string {
+		if err != nil || !strings.Contains(text, "{{") {
+			return text
+		}
+		var out string
+		out, err = renderCheckField(check.ID+" "+field, text, data)
+		return out
+	}
+	renderList := func(field string, items []string) []string {
+		if !templatedAny(items) {
+			return items
+		}
+		var out []string
+		for _, item := range items {
+			for _, line := range strings.Split(render(field, item), "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					out = append(out, line)
+				}
+			}
+		}
+		return out
+	}
+	renderRules := func(field string, rules []Rule) []Rule {
+		if len(rules) == 0 {
+			return rules
+		}
+		out := make([]Rule, len(rules))
+		for i, rule := range rules {
+			rule.Path = render(field, rule.Path)
+			out[i] = rule
+		}
+		return out
+	}
+
+	check.Command = render("command", check.Command)
+	if len(check.Env) > 0 {
+		env := make(map[string]string, len(check.Env))
+		for key, value := range check.Env {
+			env[key] = render("env "+key, value)
+		}
+		check.Env = env
+	}
+	check.Inputs = renderList("inputs", check.Inputs)
+	check.GateFiles = renderList("gate_files", check.GateFiles)
+	check.Rules = renderRules("rules", check.Rules)
+	check.Conditions = renderRules("conditions", check.Conditions)
+	return check, err
+}
+
+// envTemplateRef matches the {{.Env.X}} lookups of a template.
+var envTemplateRef = regexp.MustCompile(`\.Env\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+func renderCheckField(name string, text string, data *checkTemplateData) (string, error) {
+	tmpl, err := parseCheckTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	// Unset variables render empty rather than as missing keys.
+	for _, match := range envTemplateRef.FindAllStringSubmatch(text, -1) {
+		if _, ok := data.Env[match[1]]; !ok {
+			data.Env[match[1]] = ""
+		}
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// parseCheckTemplate parses a templated check field. missingkey=error makes a
+// config key that is not set, such as a typo in {{.Config.go.coverage_threshold}},
+// fail the plan instead of rendering an empty value into a command.
+func parseCheckTemplate(name string, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(checkTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+func templatedAny(items []string) bool {
+	for _, item := range items {
+		if strings.Contains(item, "{{") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package dun
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderCheckFields(t *testing.T) {
+	origChanged := goChangedFilesFunc
+	t.Cleanup(func() { goChangedFilesFunc = origChanged })
+	goChangedFilesFunc = func(string, string) ([]string, error) {
+		return []string{"cmd/main.go", "it's.go"}, nil
+	}
+	t.Setenv("DUN_TEMPLATE_TOKEN", "secret")
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/svc\n")
+	data := newCheckTemplateData(root, Options{Config: map[string]any{"go": map[string]any{"lint": "strict"}}})
+
+	check := Check{
+		ID:      "lint",
+		Command: `lint --module {{.Module}} --min {{.Config.go.coverage_threshold}} --mode {{.Config.go.lint}} {{shellquote .ChangedFiles}}`,
+		Env:     map[string]string{"TOKEN": "{{.Env.DUN_TEMPLATE_TOKEN}}", "UNSET": "{{.Env.DUN_TEMPLATE_UNSET}}", "PLAIN": "x"},
+		Inputs:  []string{"README.md", "{{range .ChangedFiles}}{{.}}\n{{end}}"},
+		Rules:   []Rule{{Type: "path-exists", Path: "{{.Root}}/go.mod"}},
+	}
+	rendered, err := renderCheck(check, data)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	want := `lint --module example.com/svc --min 80 --mode strict 'cmd/main.go' 'it'\''s.go'`
+	if rendered.Command != want {
+		t.Fatalf("unexpected command:\n%s\nwant\n%s", rendered.Command, want)
+	}
+	if rendered.Env["TOKEN"] != "secret" || rendered.Env["UNSET"] != "" || rendered.Env["PLAIN"] != "x" {
+		t.Fatalf("unexpected env: %v", rendered.Env)
+	}
+	if !reflect.DeepEqual(rendered.Inputs, []string{"README.md", "cmd/main.go", "it's.go"}) {
+		t.Fatalf("unexpected inputs: %v", rendered.Inputs)
+	}
+	if rendered.Rules[0].Path != data.Root+"/go.mod" {
+		t.Fatalf("unexpected rule path: %s", rendered.Rules[0].Path)
+	}
+	if check.Rules[0].Path != "{{.Root}}/go.mod" || check.Env["TOKEN"] != "{{.Env.DUN_TEMPLATE_TOKEN}}" {
+		t.Fatalf("expected the manifest check to be left alone")
+	}
+}
+
+func TestRenderCheckMissingConfigKey(t *testing.T) {
+	data := newCheckTemplateData(t.TempDir(), Options{})
+	for key, want := range map[string]string{
+		"go.missing":              `map has no entry for key "missing"`,
+		"nope.deeper":             `map has no entry for key "nope"`,
+		"go.coverage_threshold.x": "can't evaluate field x",
+	} {
+		_, err := renderCheck(Check{ID: "x", Command: "run {{.Config." + key + "}}"}, data)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected missing key error, got %v", key, err)
+		}
+	}
+}
+
+func TestBuildPlanRendersTemplates(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "package.json"), `{"name": "web"}`)
+	plugin := Plugin{Manifest: Manifest{ID: "p", Checks: []Check{{
+		ID:         "greet",
+		Type:       "command",
+		Command:    "echo {{.Module}}",
+		Conditions: []Rule{{Type: "path-exists", Path: "{{if .Module}}package.json{{end}}"}},
+	}}}}
+	plan, err := buildPlan(root, []Plugin{plugin}, Options{}, true)
+	if err != nil {
+		t.Fatalf("build plan: %v", err)
+	}
+	if len(plan) != 1 || plan[0].Check.Command != "echo web" {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+
+	plugin.Manifest.Checks[0].Command = "echo {{.Module"
+	if _, err := buildPlan(root, []Plugin{plugin}, Options{}, true); err == nil || !strings.Contains(err.Error(), "plugin p: parse greet command") {
+		t.Fatalf("expected template error, got %v", err)
+	}
+}
+
+func TestLintPluginInvalidTemplate(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), `id: tmpl
+version: "1"
+checks:
+  - id: x
+    type: command
+    command: "echo {{.Root"
+`)
+	issues, err := LintPlugin(dir)
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	if len(issues) != 1 || issues[0].ID != "plugin:plugin.yaml:6:invalid-template" {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+}
+
+func TestLintPluginSkipsTemplatedPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), `id: tmpl
+version: "1"
+checks:
+  - id: x
+    type: agent
+    prompt: "{{.Root}}/prompts/review.md"
+    gate_files: ["{{.Root}}/GATES.md"]
+`)
+	issues, err := LintPlugin(dir)
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Fatalf("expected templated paths to be skipped, got %+v", issues)
+	}
+}
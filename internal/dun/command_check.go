@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -277,6 +278,8 @@ func parseRegexOutput(config CommandConfig, output []byte) ([]Issue, string) {
 			switch name {
 			case "file":
 				issue.Path = value
+			case "line":
+				issue.Line, _ = strconv.Atoi(value)
 			case "message":
 				issue.Summary = value
 			case "id":
@@ -2,6 +2,7 @@ package dun
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
@@ -10,9 +11,15 @@ import (
 )
 
 type Config struct {
-	Version string      `yaml:"version"`
-	Agent   AgentConfig `yaml:"agent"`
-	Go      GoConfig    `yaml:"go"`
+	Version string        `yaml:"version"`
+	Agent   AgentConfig   `yaml:"agent"`
+	Go      GoConfig      `yaml:"go"`
+	Tasks   TasksConfig   `yaml:"tasks"`
+	Plugins PluginsConfig `yaml:"plugins"`
+
+	// Values holds every setting as parsed, including keys dun itself does
+	// not use, so plugin templates can read them as {{.Config.<key>}}.
+	Values map[string]any `yaml:"-"`
 }
 
 type AgentConfig struct {
@@ -26,7 +33,28 @@ type AgentConfig struct {
 }
 
 type GoConfig struct {
-	CoverageThreshold int `yaml:"coverage_threshold"`
+	CoverageThreshold int  `yaml:"coverage_threshold"`
+	Affected          bool `yaml:"affected"`
+	GenerateDrift     bool `yaml:"generate_drift"`
+}
+
+// TasksConfig controls which Makefile/justfile/Taskfile targets become
+// checks. Enabled turns targets on or off; unset, they only run in projects
+// without a go.mod, package.json, Cargo.toml, pyproject.toml or setup.cfg,
+// whose builtin plugins already test and lint. Phases maps target names to phases
+// (adding non-conventional targets); Ignore drops targets by name or by check
+// ID such as "make-lint".
+type TasksConfig struct {
+	Enabled *bool             `yaml:"enabled"`
+	Phases  map[string]string `yaml:"phases"`
+	Ignore  []string          `yaml:"ignore"`
+}
+
+// PluginsConfig controls plugin loading. Integrity is what happens when an
+// active plugin no longer matches .dun/plugins.lock: off, warn (default) or
+// fail.
+type PluginsConfig struct {
+	Integrity string `yaml:"integrity"`
 }
 
 const DefaultConfigPath = ".dun/config.yaml"
@@ -82,6 +110,19 @@ func ApplyConfig(opts Options, cfg Config) Options {
 	if cfg.Go.CoverageThreshold > 0 {
 		opts.CoverageThreshold = cfg.Go.CoverageThreshold
 	}
+	if cfg.Go.Affected {
+		opts.GoAffected = true
+	}
+	if cfg.Go.GenerateDrift {
+		opts.GoGenerateDrift = true
+	}
+	if cfg.Plugins.Integrity != "" {
+		opts.PluginIntegrity = cfg.Plugins.Integrity
+	}
+	opts.Tasks = cfg.Tasks
+	if cfg.Values != nil {
+		opts.Config = cfg.Values
+	}
 	return opts
 }
 
@@ -130,6 +171,14 @@ func loadConfigFile(path string) (Config, error) {
 	if err := yaml.Unmarshal(raw, &cfg); err != nil {
 		return Config{}, err
 	}
+	if err := yaml.Unmarshal(raw, &cfg.Values); err != nil {
+		return Config{}, err
+	}
+	switch cfg.Plugins.Integrity {
+	case "", PluginIntegrityOff, PluginIntegrityWarn, PluginIntegrityFail:
+	default:
+		return Config{}, fmt.Errorf("%s: plugins.integrity must be off, warn or fail, got %q", path, cfg.Plugins.Integrity)
+	}
 	return cfg, nil
 }
 
@@ -210,6 +259,55 @@ func mergeConfig(base Config, override Config) Config {
 	if override.Go.CoverageThreshold > 0 {
 		merged.Go.CoverageThreshold = override.Go.CoverageThreshold
 	}
+	if override.Go.Affected {
+		merged.Go.Affected = true
+	}
+	if override.Go.GenerateDrift {
+		merged.Go.GenerateDrift = true
+	}
+	if override.Plugins.Integrity != "" {
+		merged.Plugins.Integrity = override.Plugins.Integrity
+	}
+
+	if override.Tasks.Enabled != nil {
+		merged.Tasks.Enabled = override.Tasks.Enabled
+	}
+	if len(override.Tasks.Phases) > 0 {
+		phases := make(map[string]string, len(merged.Tasks.Phases)+len(override.Tasks.Phases))
+		for target, phase := range merged.Tasks.Phases {
+			phases[target] = phase
+		}
+		for target, phase := range override.Tasks.Phases {
+			phases[target] = phase
+		}
+		merged.Tasks.Phases = phases
+	}
+	if len(override.Tasks.Ignore) > 0 {
+		merged.Tasks.Ignore = append(append([]string{}, merged.Tasks.Ignore...), override.Tasks.Ignore...)
+	}
+	merged.Values = mergeConfigValues(base.Values, override.Values)
 
 	return merged
 }
+
+// mergeConfigValues merges raw settings; nested mappings merge key by key
+// and any other override value replaces the base one.
+func mergeConfigValues(base map[string]any, override map[string]any) map[string]any {
+	if len(base) == 0 && len(override) == 0 {
+		return base
+	}
+	merged := make(map[string]any, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		baseMap, baseOK := merged[key].(map[string]any)
+		overrideMap, overrideOK := value.(map[string]any)
+		if baseOK && overrideOK {
+			merged[key] = mergeConfigValues(baseMap, overrideMap)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
@@ -3,6 +3,7 @@ package dun
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -23,7 +24,7 @@ func TestLoadConfigDefaultPath(t *testing.T) {
 		t.Fatalf("mkdir config dir: %v", err)
 	}
 	content := "agent:\n  cmd: echo hi\n  harness: codex\n  model: o3\n  models:\n    claude: sonnet\n  timeout_ms: 120000\n  mode: auto\n  automation: plan\n" +
-		"go:\n  coverage_threshold: 95\n"
+		"go:\n  coverage_threshold: 95\n  affected: true\n  generate_drift: true\n"
 	if err := os.WriteFile(cfgPath, []byte(content), 0644); err != nil {
 		t.Fatalf("write config: %v", err)
 	}
@@ -61,6 +62,24 @@ func TestLoadConfigDefaultPath(t *testing.T) {
 	if opts.CoverageThreshold != 95 {
 		t.Fatalf("expected coverage threshold 95, got %d", opts.CoverageThreshold)
 	}
+	if !opts.GoAffected {
+		t.Fatalf("expected go affected mode")
+	}
+	if !opts.GoGenerateDrift {
+		t.Fatalf("expected go generate drift enabled")
+	}
+}
+
+func TestLoadConfigRejectsUnknownIntegrity(t *testing.T) {
+	_ = setTempUserConfig(t)
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte("plugins:\n  integrity: Fail\n"), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, _, err := LoadConfig(dir, cfgPath); err == nil || !strings.Contains(err.Error(), "plugins.integrity") {
+		t.Fatalf("expected integrity error, got %v", err)
+	}
 }
 
 func TestLoadConfigAbsent(t *testing.T) {
@@ -270,3 +289,30 @@ func TestLoadConfigUserInvalid(t *testing.T) {
 		t.Fatalf("expected user config parse error")
 	}
 }
+
+func TestPluginIntegrityConfig(t *testing.T) {
+	merged := mergeConfig(Config{Plugins: PluginsConfig{Integrity: "warn"}}, Config{Plugins: PluginsConfig{Integrity: "fail"}})
+	if merged.Plugins.Integrity != "fail" {
+		t.Fatalf("expected project integrity to win, got %q", merged.Plugins.Integrity)
+	}
+	opts := ApplyConfig(DefaultOptions(), merged)
+	if opts.PluginIntegrity != PluginIntegrityFail {
+		t.Fatalf("expected fail integrity option, got %q", opts.PluginIntegrity)
+	}
+}
+
+func TestMergeConfigValues(t *testing.T) {
+	user := map[string]any{"go": map[string]any{"coverage_threshold": 70, "lint": "strict"}, "team": "core"}
+	project := map[string]any{"go": map[string]any{"coverage_threshold": 90}}
+	merged := mergeConfig(Config{Values: user}, Config{Values: project})
+	goValues := merged.Values["go"].(map[string]any)
+	if goValues["coverage_threshold"] != 90 || goValues["lint"] != "strict" || merged.Values["team"] != "core" {
+		t.Fatalf("unexpected merged values: %v", merged.Values)
+	}
+	if user["go"].(map[string]any)["coverage_threshold"] != 70 {
+		t.Fatalf("expected base values to be left alone")
+	}
+	if opts := ApplyConfig(DefaultOptions(), merged); opts.Config["team"] != "core" {
+		t.Fatalf("expected raw values in options, got %v", opts.Config)
+	}
+}
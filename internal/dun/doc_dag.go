@@ -481,7 +481,7 @@ func (g *DocGraph) buildPromptEnvelope(root string, plugin Plugin, def CheckDefi
 		return nil, err
 	}
 
-	promptText, schemaText, err := renderDocPromptText(plugin, promptPath, docPromptContext{
+	promptText, schemaText, err := renderDocPromptText(root, plugin, promptPath, docPromptContext{
 		DocID:   targetID,
 		DocPath: g.expectedPath(targetID),
 		Reason:  reason,
@@ -558,14 +558,12 @@ func loadPromptInputs(root string, paths []string) ([]PromptInput, error) {
 	return resolved, nil
 }
 
-func renderDocPromptText(plugin Plugin, promptPath string, ctx docPromptContext) (string, string, error) {
-	tmplText, err := loadPromptTemplate(plugin, promptPath)
-	if err != nil {
+func renderDocPromptText(root string, plugin Plugin, promptPath string, ctx docPromptContext) (string, string, error) {
+	tmpl := template.New("doc-prompt")
+	if err := addPromptPartials(tmpl, plugin.partials); err != nil {
 		return "", "", err
 	}
-
-	tmpl, err := template.New("doc-prompt").Parse(tmplText)
-	if err != nil {
+	if err := parsePromptTemplate(tmpl, root, plugin, promptPath); err != nil {
 		return "", "", err
 	}
 
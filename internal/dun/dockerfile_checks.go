@@ -0,0 +1,274 @@
+package dun
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// dockerInstruction is one logical Dockerfile instruction, with line
+// continuations joined.
+type dockerInstruction struct {
+	Command string
+	Args    string
+	Line    int
+}
+
+type hadolintFinding struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Code    string `json:"code"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+func runDockerfileLint(root string, def CheckDefinition, config DockerfileCheckConfig) (CheckResult, error) {
+	files, err := findDockerfiles(root)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if len(files) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "skip",
+			Signal: "no Dockerfiles",
+		}, nil
+	}
+
+	var issues []Issue
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(root, file))
+		if err != nil {
+			return CheckResult{}, err
+		}
+		issues = append(issues, lintDockerfile(file, parseDockerfile(string(data)))...)
+	}
+
+	hadolintNote := ""
+	if _, err := exec.LookPath("hadolint"); err == nil {
+		found, err := runHadolint(root, config, files)
+		if err != nil {
+			return CheckResult{}, err
+		}
+		issues = append(issues, found...)
+	} else {
+		hadolintNote = "hadolint not found; only builtin rules were checked"
+	}
+
+	if len(issues) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: fmt.Sprintf("%d Dockerfiles passed", len(files)),
+			Detail: hadolintNote,
+		}, nil
+	}
+	status := "warn"
+	for _, issue := range issues {
+		if issue.Severity == "fail" {
+			status = "fail"
+			break
+		}
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: status,
+		Signal: fmt.Sprintf("%d Dockerfile problems", len(issues)),
+		Detail: fmt.Sprintf("%s:%d: %s", issues[0].Path, issues[0].Line, issues[0].Summary),
+		Next:   "Fix the reported Dockerfile instructions.",
+		Issues: issues,
+	}, nil
+}
+
+// findDockerfiles lists Dockerfile, Dockerfile.* and *.dockerfile files,
+// skipping hidden, vendor, testdata and node_modules directories and
+// sub-projects.
+func findDockerfiles(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if skipTreeDir(root, path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name := d.Name()
+		if strings.HasPrefix(name, "Dockerfile") || strings.HasSuffix(strings.ToLower(name), ".dockerfile") {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// parseDockerfile splits content into instructions, skipping comments and
+// joining backslash continuations.
+func parseDockerfile(content string) []dockerInstruction {
+	var out []dockerInstruction
+	var current strings.Builder
+	start := 0
+	for i, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(strings.TrimRight(raw, "\r"))
+		if strings.HasPrefix(line, "#") || (line == "" && current.Len() == 0) {
+			continue
+		}
+		if current.Len() == 0 {
+			start = i + 1
+		} else {
+			current.WriteByte(' ')
+		}
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(strings.TrimSpace(strings.TrimSuffix(line, "\\")))
+			continue
+		}
+		current.WriteString(line)
+		fields := strings.SplitN(current.String(), " ", 2)
+		inst := dockerInstruction{Command: strings.ToUpper(fields[0]), Line: start}
+		if len(fields) == 2 {
+			inst.Args = strings.TrimSpace(fields[1])
+		}
+		out = append(out, inst)
+		current.Reset()
+	}
+	return out
+}
+
+// lintDockerfile applies the builtin rules: pinned base images, apt-get list
+// cleanup, no remote ADD and a non-root USER in the final stage.
+func lintDockerfile(path string, instructions []dockerInstruction) []Issue {
+	var issues []Issue
+	add := func(line int, rule string, summary string) {
+		issues = append(issues, Issue{
+			ID:       fmt.Sprintf("docker:%s:%d:%s", path, line, rule),
+			Summary:  summary,
+			Path:     path,
+			Line:     line,
+			Severity: "warn",
+		})
+	}
+
+	stages := map[string]bool{}
+	lastFrom := 0
+	user := ""
+	for _, inst := range instructions {
+		switch inst.Command {
+		case "FROM":
+			lastFrom = inst.Line
+			user = ""
+			image, alias := dockerFromImage(inst.Args)
+			if !dockerImagePinned(image, stages) {
+				add(inst.Line, "unpinned-base", fmt.Sprintf("base image %q is not pinned to a version tag or digest", image))
+			}
+			if alias != "" {
+				stages[strings.ToLower(alias)] = true
+			}
+		case "RUN":
+			if strings.Contains(inst.Args, "apt-get install") && !strings.Contains(inst.Args, "/var/lib/apt/lists") {
+				add(inst.Line, "apt-cleanup", "apt-get install without rm -rf /var/lib/apt/lists/*")
+			}
+		case "ADD":
+			for _, field := range strings.Fields(inst.Args) {
+				if strings.HasPrefix(field, "http://") || strings.HasPrefix(field, "https://") {
+					add(inst.Line, "add-remote", fmt.Sprintf("ADD of remote URL %s; use curl or wget in RUN and verify the download", field))
+					break
+				}
+			}
+		case "USER":
+			user = strings.SplitN(strings.TrimSpace(inst.Args), ":", 2)[0]
+		}
+	}
+	if lastFrom > 0 && (user == "" || user == "root" || user == "0") {
+		add(lastFrom, "missing-user", "final stage runs as root; add a USER instruction")
+	}
+	return issues
+}
+
+// dockerFromImage returns the image and stage alias of FROM arguments,
+// ignoring flags such as --platform.
+func dockerFromImage(args string) (string, string) {
+	var fields []string
+	for _, field := range strings.Fields(args) {
+		if !strings.HasPrefix(field, "--") {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return "", ""
+	}
+	if len(fields) >= 3 && strings.EqualFold(fields[1], "as") {
+		return fields[0], fields[2]
+	}
+	return fields[0], ""
+}
+
+// dockerImagePinned reports whether image names a digest or a non-latest tag.
+// scratch, earlier build stages and ARG-substituted images are not checked.
+func dockerImagePinned(image string, stages map[string]bool) bool {
+	if image == "" || image == "scratch" || stages[strings.ToLower(image)] || strings.Contains(image, "$") {
+		return true
+	}
+	if strings.Contains(image, "@") {
+		return true
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	idx := strings.LastIndex(name, ":")
+	if idx < 0 {
+		return false
+	}
+	return name[idx+1:] != "latest"
+}
+
+// runHadolint reports hadolint errors (fail) and warnings (warn).
+func runHadolint(root string, config DockerfileCheckConfig, files []string) ([]Issue, error) {
+	args := append([]string{"--format", "json", "--no-fail"}, files...)
+	run, err := runToolCommand(root, config.Timeout, config.Env, "hadolint", args...)
+	if err != nil {
+		return nil, err
+	}
+	var findings []hadolintFinding
+	if err := json.Unmarshal(run.Stdout, &findings); err != nil {
+		if run.ExitCode == 0 && len(strings.TrimSpace(string(run.Stdout))) == 0 {
+			return nil, nil
+		}
+		return []Issue{{
+			ID:       "hadolint",
+			Summary:  "hadolint failed",
+			Severity: "warn",
+			Detail:   trimOutput(run.Combined()),
+		}}, nil
+	}
+	var issues []Issue
+	for _, f := range findings {
+		severity := ""
+		switch f.Level {
+		case "error":
+			severity = "fail"
+		case "warning":
+			severity = "warn"
+		default:
+			continue
+		}
+		path := toolRelPath(root, f.File)
+		issues = append(issues, Issue{
+			ID:       fmt.Sprintf("hadolint:%s:%d:%s", path, f.Line, f.Code),
+			Summary:  f.Code + ": " + f.Message,
+			Path:     path,
+			Line:     f.Line,
+			Severity: severity,
+		})
+	}
+	return issues, nil
+}
@@ -0,0 +1,93 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLintDockerfileRules(t *testing.T) {
+	content := `# build stage
+FROM golang:1.22 AS build
+RUN apt-get update && \
+    apt-get install -y git
+FROM build AS test
+FROM ubuntu
+ADD https://example.com/tool.tgz /opt/
+FROM alpine@sha256:abc
+USER root
+`
+	issues := lintDockerfile("Dockerfile", parseDockerfile(content))
+	want := []string{
+		"docker:Dockerfile:3:apt-cleanup",
+		"docker:Dockerfile:6:unpinned-base",
+		"docker:Dockerfile:7:add-remote",
+		"docker:Dockerfile:8:missing-user",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %+v", len(want), issues)
+	}
+	for i, id := range want {
+		if issues[i].ID != id {
+			t.Fatalf("issue %d: expected %s, got %s", i, id, issues[i].ID)
+		}
+	}
+}
+
+func TestLintDockerfileClean(t *testing.T) {
+	content := `ARG BASE=debian:12
+FROM ${BASE}
+RUN apt-get update && apt-get install -y curl \
+ && rm -rf /var/lib/apt/lists/*
+FROM --platform=linux/amd64 scratch
+USER 1000:1000
+`
+	if issues := lintDockerfile("Dockerfile", parseDockerfile(content)); len(issues) != 0 {
+		t.Fatalf("expected no issues, got %+v", issues)
+	}
+}
+
+func TestDockerfileLintCheck(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	root := t.TempDir()
+	res, err := runDockerfileLint(root, CheckDefinition{ID: "dockerfile-lint"}, DockerfileCheckConfig{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Status != "skip" {
+		t.Fatalf("expected skip, got %+v", res)
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, "deploy"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, filepath.Join(root, "deploy", "Dockerfile.prod"), "FROM nginx:latest\nUSER nginx\n")
+	res, err = runDockerfileLint(root, CheckDefinition{ID: "dockerfile-lint"}, DockerfileCheckConfig{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Status != "warn" || len(res.Issues) != 1 || res.Issues[0].ID != "docker:deploy/Dockerfile.prod:1:unpinned-base" {
+		t.Fatalf("expected unpinned base warning, got %+v", res)
+	}
+}
+
+func TestDockerfileLintUsesHadolint(t *testing.T) {
+	binDir := t.TempDir()
+	stub := `#!/bin/sh
+echo '[{"file":"Dockerfile","line":2,"code":"DL3008","level":"warning","message":"Pin versions"},{"file":"Dockerfile","line":1,"code":"DL3000","level":"error","message":"Use absolute WORKDIR"},{"file":"Dockerfile","line":1,"code":"DL3059","level":"info","message":"Consolidate"}]'
+`
+	if err := os.WriteFile(filepath.Join(binDir, "hadolint"), []byte(stub), 0755); err != nil {
+		t.Fatalf("write stub: %v", err)
+	}
+	t.Setenv("PATH", binDir)
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "Dockerfile"), "FROM alpine:3.20\nUSER app\n")
+
+	res, err := runDockerfileLint(root, CheckDefinition{ID: "dockerfile-lint"}, DockerfileCheckConfig{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 2 || res.Issues[0].ID != "hadolint:Dockerfile:2:DL3008" {
+		t.Fatalf("expected hadolint issues, got %+v", res)
+	}
+}
@@ -166,6 +166,7 @@ func checkGoHelpers() []HelperStatus {
 
 	helpers = append(helpers,
 		toolHelper("go", "staticcheck", false),
+		toolHelper("go", "goimports", false),
 		toolHelper("go", "govulncheck", false),
 		toolHelper("go", "gosec", false),
 	)
@@ -2,16 +2,20 @@ package dun
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
+	"strings"
 )
 
-var loadBuiltins = LoadBuiltins
+var loadPlugins = LoadPlugins
 
 type plannedCheck struct {
 	Plugin Plugin
 	Check  Check
+	Scope  string // Sub-project path relative to the repo root; empty for the root
 }
 
 type Plan struct {
@@ -24,6 +28,7 @@ type PlannedCheck struct {
 	Type        string
 	Phase       string
 	PluginID    string
+	Scope       string
 	Inputs      []string
 	Conditions  []Rule
 	Prompt      string
@@ -32,7 +37,10 @@ type PlannedCheck struct {
 }
 
 func CheckRepo(root string, opts Options) (Result, error) {
-	plan, err := buildPlanForRoot(root)
+	defer closeWasmModules()
+	cacheGoPackageScopes()
+	defer clearGoPackageScopes()
+	plan, err := buildPlanForRoot(root, opts, true)
 	if err != nil {
 		return Result{}, err
 	}
@@ -49,8 +57,9 @@ func CheckRepo(root string, opts Options) (Result, error) {
 	return Result{Checks: results}, nil
 }
 
-func PlanRepo(root string) (Plan, error) {
-	plan, err := buildPlanForRoot(root)
+// PlanRepo lists the checks CheckRepo would run with opts.
+func PlanRepo(root string, opts Options) (Plan, error) {
+	plan, err := buildPlanForRoot(root, opts, true)
 	if err != nil {
 		return Plan{}, err
 	}
@@ -62,6 +71,7 @@ func PlanRepo(root string) (Plan, error) {
 			Type:        pc.Check.Type,
 			Phase:       pc.Check.Phase,
 			PluginID:    pc.Plugin.Manifest.ID,
+			Scope:       pc.Scope,
 			Inputs:      pc.Check.Inputs,
 			Conditions:  pc.Check.Conditions,
 			Prompt:      pc.Check.Prompt,
@@ -72,22 +82,69 @@ func PlanRepo(root string) (Plan, error) {
 	return Plan{Checks: out}, nil
 }
 
-func buildPlanForRoot(root string) ([]plannedCheck, error) {
-	plugins, err := loadBuiltins()
+// buildPlanForRoot plans the checks of the repo at root and its sub-projects.
+// Unless checkConditions is set, checks are planned whatever their
+// conditions say.
+func buildPlanForRoot(root string, opts Options, checkConditions bool) ([]plannedCheck, error) {
+	plugins, err := loadPlugins(root)
 	if err != nil {
 		return nil, err
 	}
+	if err := enforcePluginLock(root, plugins, opts.PluginIntegrity); err != nil {
+		return nil, err
+	}
+	if err := registerPluginCheckTypes(plugins); err != nil {
+		return nil, err
+	}
 
 	active := filterActivePlugins(root, plugins)
-	plan, err := buildPlan(root, active)
+	plan, err := buildPlan(root, active, opts, checkConditions)
 	if err != nil {
 		return nil, err
 	}
+	for _, sub := range DiscoverSubprojects(root) {
+		scoped, err := buildSubprojectPlan(root, sub, plugins, opts, checkConditions)
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan, scoped...)
+	}
 
 	sortPlan(plan)
 	return plan, nil
 }
 
+// buildSubprojectPlan plans the checks of plugins triggered in the sub-project
+// sub, with IDs namespaced as "sub:id". Plugins without triggers apply to the
+// whole repo and only run at the root.
+func buildSubprojectPlan(root string, sub string, plugins []Plugin, opts Options, checkConditions bool) ([]plannedCheck, error) {
+	dir := filepath.Join(root, filepath.FromSlash(sub))
+	var triggered []Plugin
+	for _, plugin := range plugins {
+		if len(plugin.Manifest.Triggers) > 0 && isPluginActive(dir, plugin) {
+			triggered = append(triggered, plugin)
+		}
+	}
+	plan, err := buildPlan(dir, triggered, opts, checkConditions)
+	if err != nil {
+		return nil, err
+	}
+	for i := range plan {
+		plan[i].Scope = sub
+		plan[i].Check.ID = sub + ":" + plan[i].Check.ID
+	}
+	return plan, nil
+}
+
+// localCheckID strips the sub-project prefix buildSubprojectPlan adds to a
+// check ID, giving the ID the plugin declares.
+func localCheckID(id string, scope string) string {
+	if scope == "" {
+		return id
+	}
+	return strings.TrimPrefix(id, scope+":")
+}
+
 func filterActivePlugins(root string, plugins []Plugin) []Plugin {
 	var active []Plugin
 	for _, plugin := range plugins {
@@ -116,6 +173,9 @@ func evalTrigger(root string, trigger Trigger) bool {
 		_, err := os.Stat(filepath.Join(root, trigger.Value))
 		return err == nil
 	case "glob-exists":
+		if strings.Contains(trigger.Value, "**") {
+			return globExistsInTree(root, trigger.Value)
+		}
 		matches, _ := filepath.Glob(filepath.Join(root, trigger.Value))
 		return len(matches) > 0
 	default:
@@ -123,23 +183,97 @@ func evalTrigger(root string, trigger Trigger) bool {
 	}
 }
 
-func buildPlan(root string, plugins []Plugin) ([]plannedCheck, error) {
+// globExistsInTree reports whether a file below root matches pattern, where
+// a "**" element matches any number of directories. The walk skips the same
+// directories as the checks that walk the tree (see skipTreeDir).
+func globExistsInTree(root string, pattern string) bool {
+	elems := strings.Split(pattern, "/")
+	found := false
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if skipTreeDir(root, path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		if matchGlobElems(elems, strings.Split(filepath.ToSlash(rel), "/")) {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+func matchGlobElems(pattern []string, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchGlobElems(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return matchGlobElems(pattern[1:], name[1:])
+}
+
+// buildPlan renders the templated fields of each check (see renderCheck) and,
+// when checkConditions is set, keeps those whose conditions hold.
+func buildPlan(root string, plugins []Plugin, opts Options, checkConditions bool) ([]plannedCheck, error) {
 	var plan []plannedCheck
+	data := newCheckTemplateData(root, opts)
 	for _, plugin := range plugins {
 		for _, check := range plugin.Manifest.Checks {
-			ok, err := conditionsMet(root, check.Conditions)
+			check, err := renderCheck(check, data)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("plugin %s: %w", plugin.Manifest.ID, err)
 			}
-			if !ok {
-				continue
+			if checkConditions {
+				ok, err := conditionsMet(root, check.Conditions)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+			}
+			for _, expanded := range expandCheck(root, check, opts) {
+				plan = append(plan, plannedCheck{Plugin: plugin, Check: expanded})
 			}
-			plan = append(plan, plannedCheck{Plugin: plugin, Check: check})
 		}
 	}
 	return plan, nil
 }
 
+// expandCheck lets the check type split a check, e.g. per workspace member.
+func expandCheck(root string, check Check, opts Options) []Check {
+	handler, ok := LookupCheckType(check.Type)
+	if !ok {
+		return []Check{check}
+	}
+	expander, ok := handler.(CheckExpander)
+	if !ok {
+		return []Check{check}
+	}
+	return expander.Expand(root, check, opts)
+}
+
 func conditionsMet(root string, rules []Rule) (bool, error) {
 	for _, rule := range rules {
 		res, err := evalRule(root, rule)
@@ -198,7 +332,12 @@ func sortPlan(plan []plannedCheck) {
 	})
 }
 
+// runCheck runs a planned check; checks of a sub-project run with the
+// sub-project as their root.
 func runCheck(root string, pc plannedCheck, opts Options) (CheckResult, error) {
+	if pc.Scope != "" {
+		root = filepath.Join(root, filepath.FromSlash(pc.Scope))
+	}
 	handler, ok := LookupCheckType(pc.Check.Type)
 	if !ok {
 		return CheckResult{}, fmt.Errorf("unknown check type: %s", pc.Check.Type)
@@ -215,10 +354,14 @@ func runCheck(root string, pc plannedCheck, opts Options) (CheckResult, error) {
 		Priority:    pc.Check.Priority,
 		Conditions:  pc.Check.Conditions,
 		PluginID:    pc.Plugin.Manifest.ID,
+		Scope:       pc.Scope,
 	}
 	result, err := handler.Run(root, def, cfg, opts, pc.Plugin)
 	if err != nil {
 		return CheckResult{}, err
 	}
+	if pc.Scope != "" && !strings.HasPrefix(result.ID, pc.Scope+":") {
+		result.ID = pc.Scope + ":" + result.ID
+	}
 	return summarizeResult(result), nil
 }
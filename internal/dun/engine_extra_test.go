@@ -50,20 +50,20 @@ func TestConditionsMetError(t *testing.T) {
 }
 
 func TestBuildPlanForRootError(t *testing.T) {
-	orig := loadBuiltins
-	loadBuiltins = func() ([]Plugin, error) {
+	orig := loadPlugins
+	loadPlugins = func(string) ([]Plugin, error) {
 		return nil, errors.New("boom")
 	}
-	t.Cleanup(func() { loadBuiltins = orig })
+	t.Cleanup(func() { loadPlugins = orig })
 
-	if _, err := buildPlanForRoot(t.TempDir()); err == nil {
+	if _, err := buildPlanForRoot(t.TempDir(), Options{}, true); err == nil {
 		t.Fatalf("expected buildPlanForRoot error")
 	}
 }
 
 func TestBuildPlanForRootConditionError(t *testing.T) {
-	orig := loadBuiltins
-	loadBuiltins = func() ([]Plugin, error) {
+	orig := loadPlugins
+	loadPlugins = func(string) ([]Plugin, error) {
 		return []Plugin{
 			{
 				Manifest: Manifest{
@@ -81,21 +81,21 @@ func TestBuildPlanForRootConditionError(t *testing.T) {
 			},
 		}, nil
 	}
-	t.Cleanup(func() { loadBuiltins = orig })
+	t.Cleanup(func() { loadPlugins = orig })
 
 	root := t.TempDir()
 	writeFile(t, filepath.Join(root, "pattern.txt"), "x")
-	if _, err := buildPlanForRoot(root); err == nil {
+	if _, err := buildPlanForRoot(root, Options{}, true); err == nil {
 		t.Fatalf("expected buildPlan error")
 	}
 }
 
 func TestCheckRepoReturnsError(t *testing.T) {
-	orig := loadBuiltins
-	loadBuiltins = func() ([]Plugin, error) {
+	orig := loadPlugins
+	loadPlugins = func(string) ([]Plugin, error) {
 		return nil, errors.New("boom")
 	}
-	t.Cleanup(func() { loadBuiltins = orig })
+	t.Cleanup(func() { loadPlugins = orig })
 
 	if _, err := CheckRepo(t.TempDir(), Options{}); err == nil {
 		t.Fatalf("expected error from CheckRepo")
@@ -120,8 +120,8 @@ func TestCheckRepoDocsHelixEmpty(t *testing.T) {
 }
 
 func TestCheckRepoRunCheckError(t *testing.T) {
-	orig := loadBuiltins
-	loadBuiltins = func() ([]Plugin, error) {
+	orig := loadPlugins
+	loadPlugins = func(string) ([]Plugin, error) {
 		return []Plugin{
 			{
 				Manifest: Manifest{
@@ -134,7 +134,7 @@ func TestCheckRepoRunCheckError(t *testing.T) {
 			},
 		}, nil
 	}
-	t.Cleanup(func() { loadBuiltins = orig })
+	t.Cleanup(func() { loadPlugins = orig })
 
 	if _, err := CheckRepo(t.TempDir(), Options{}); err == nil {
 		t.Fatalf("expected runCheck error")
@@ -142,13 +142,13 @@ func TestCheckRepoRunCheckError(t *testing.T) {
 }
 
 func TestPlanRepoReturnsError(t *testing.T) {
-	orig := loadBuiltins
-	loadBuiltins = func() ([]Plugin, error) {
+	orig := loadPlugins
+	loadPlugins = func(string) ([]Plugin, error) {
 		return nil, errors.New("boom")
 	}
-	t.Cleanup(func() { loadBuiltins = orig })
+	t.Cleanup(func() { loadPlugins = orig })
 
-	if _, err := PlanRepo(t.TempDir()); err == nil {
+	if _, err := PlanRepo(t.TempDir(), DefaultOptions()); err == nil {
 		t.Fatalf("expected error from PlanRepo")
 	}
 }
@@ -186,7 +186,7 @@ func TestBuildPlanSkipsConditions(t *testing.T) {
 			},
 		},
 	}
-	plan, err := buildPlan(root, []Plugin{plugin})
+	plan, err := buildPlan(root, []Plugin{plugin}, Options{}, true)
 	if err != nil {
 		t.Fatalf("build plan: %v", err)
 	}
@@ -351,7 +351,7 @@ func TestGoPluginActiveWhenGoModExists(t *testing.T) {
 	root := t.TempDir()
 	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/test")
 
-	plan, err := PlanRepo(root)
+	plan, err := PlanRepo(root, DefaultOptions())
 	if err != nil {
 		t.Fatalf("plan repo: %v", err)
 	}
@@ -370,7 +370,7 @@ func TestGoPluginInactiveWhenGoModMissing(t *testing.T) {
 	root := t.TempDir()
 	// No go.mod file
 
-	plan, err := PlanRepo(root)
+	plan, err := PlanRepo(root, DefaultOptions())
 	if err != nil {
 		t.Fatalf("plan repo: %v", err)
 	}
@@ -390,7 +390,7 @@ func TestHelixPluginActiveWhenDocsHelixExists(t *testing.T) {
 		t.Fatalf("mkdir: %v", err)
 	}
 
-	plan, err := PlanRepo(root)
+	plan, err := PlanRepo(root, DefaultOptions())
 	if err != nil {
 		t.Fatalf("plan repo: %v", err)
 	}
@@ -409,7 +409,7 @@ func TestHelixPluginInactiveWhenDocsHelixMissing(t *testing.T) {
 	root := t.TempDir()
 	// No docs/helix directory
 
-	plan, err := PlanRepo(root)
+	plan, err := PlanRepo(root, DefaultOptions())
 	if err != nil {
 		t.Fatalf("plan repo: %v", err)
 	}
@@ -433,7 +433,7 @@ func TestDeterministicOrdering(t *testing.T) {
 	// Run PlanRepo multiple times
 	var previousIDs []string
 	for i := 0; i < 5; i++ {
-		plan, err := PlanRepo(root)
+		plan, err := PlanRepo(root, DefaultOptions())
 		if err != nil {
 			t.Fatalf("plan repo run %d: %v", i, err)
 		}
@@ -495,7 +495,7 @@ func TestBuildPlanMultipleConditionsAllMustPass(t *testing.T) {
 			},
 		},
 	}
-	plan, err := buildPlan(root, []Plugin{plugin})
+	plan, err := buildPlan(root, []Plugin{plugin}, Options{}, true)
 	if err != nil {
 		t.Fatalf("build plan: %v", err)
 	}
@@ -660,3 +660,32 @@ func TestRunCheckSelfTest(t *testing.T) {
 		t.Fatalf("expected pass, got %s: %v", res.Status, res.Issues)
 	}
 }
+
+func TestEvalTriggerGlobExistsRecursive(t *testing.T) {
+	root := t.TempDir()
+	trigger := Trigger{Type: "glob-exists", Value: "**/*.sh"}
+	for _, dir := range []string{"node_modules/pkg", "svc", "scripts/ci"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	writeFile(t, filepath.Join(root, "node_modules", "pkg", "build.sh"), "")
+	writeFile(t, filepath.Join(root, "svc", "go.mod"), "module example.com/svc\n")
+	writeFile(t, filepath.Join(root, "svc", "run.sh"), "")
+	if evalTrigger(root, trigger) {
+		t.Fatalf("expected skipped directories and sub-projects not to match")
+	}
+	writeFile(t, filepath.Join(root, "scripts", "ci", "test.sh"), "")
+	if !evalTrigger(root, trigger) {
+		t.Fatalf("expected nested script to match")
+	}
+
+	top := t.TempDir()
+	writeFile(t, filepath.Join(top, "run.sh"), "")
+	if !evalTrigger(top, trigger) {
+		t.Fatalf("expected ** to match zero directories")
+	}
+	if evalTrigger(top, Trigger{Type: "glob-exists", Value: "**/ci/*.sh"}) {
+		t.Fatalf("expected ci element to be required")
+	}
+}
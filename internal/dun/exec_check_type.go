@@ -0,0 +1,276 @@
+package dun
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// execProtocolVersions are the exec plugin protocol versions dun speaks,
+// newest last.
+var execProtocolVersions = []int{1}
+
+// execCheckRequest is written to the plugin executable's stdin.
+type execCheckRequest struct {
+	Protocol  int              `json:"protocol"`
+	Supported []int            `json:"supported"`
+	Type      string           `json:"type"`
+	Root      string           `json:"root"`
+	Check     execCheckSpec    `json:"check"`
+	Options   execCheckOptions `json:"options"`
+}
+
+type execCheckSpec struct {
+	ID          string         `json:"id"`
+	Description string         `json:"description,omitempty"`
+	Phase       string         `json:"phase,omitempty"`
+	Inputs      []string       `json:"inputs,omitempty"`
+	Config      map[string]any `json:"config,omitempty"`
+}
+
+type execCheckOptions struct {
+	AutomationMode    string `json:"automation_mode,omitempty"`
+	CoverageThreshold int    `json:"coverage_threshold,omitempty"`
+	ChangedBase       string `json:"changed_base,omitempty"`
+}
+
+// execCheckResponse is read from the plugin executable's stdout.
+type execCheckResponse struct {
+	Protocol int          `json:"protocol"`
+	Result   *CheckResult `json:"result"`
+	Error    string       `json:"error"`
+}
+
+// execCheckType runs a check type implemented by a plugin executable.
+type execCheckType struct {
+	name     string
+	path     string
+	args     []string
+	timeout  string
+	protocol int
+	pluginID string
+}
+
+type execCheckConfig struct {
+	Spec Check
+}
+
+func (t execCheckType) Type() string {
+	return t.name
+}
+
+func (t execCheckType) Decode(spec Check) (CheckConfig, error) {
+	return execCheckConfig{Spec: spec}, nil
+}
+
+func (t execCheckType) Run(root string, def CheckDefinition, cfg CheckConfig, opts Options, _ Plugin) (CheckResult, error) {
+	config, ok := cfg.(execCheckConfig)
+	if !ok {
+		return CheckResult{}, fmt.Errorf("%s config missing", t.name)
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	input, err := t.request(absRoot, def, config.Spec, opts)
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	timeout := t.timeout
+	if config.Spec.Timeout != "" {
+		timeout = config.Spec.Timeout
+	}
+	env := map[string]string{"DUN_PROTOCOL": strconv.Itoa(t.protocol)}
+	for k, v := range config.Spec.Env {
+		env[k] = v
+	}
+	run, err := runToolCommandInput(absRoot, timeout, env, input, t.path, t.args...)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	command := toolCommandLine(t.path, t.args...)
+	if run.TimedOut {
+		return CheckResult{ID: def.ID, Status: "fail", Signal: t.name + " plugin timed out", Next: command}, nil
+	}
+	return t.decodeResponse(def, run, command), nil
+}
+
+// request encodes the protocol request for a check; root is the path the
+// plugin sees the repository at.
+func (t execCheckType) request(root string, def CheckDefinition, spec Check, opts Options) ([]byte, error) {
+	request := execCheckRequest{
+		Protocol:  t.protocol,
+		Supported: execProtocolVersions,
+		Type:      t.name,
+		Root:      root,
+		Check: execCheckSpec{
+			ID:          def.ID,
+			Description: def.Description,
+			Phase:       def.Phase,
+			Inputs:      spec.Inputs,
+			Config:      spec.Config,
+		},
+		Options: execCheckOptions{
+			AutomationMode:    opts.AutomationMode,
+			CoverageThreshold: opts.CoverageThreshold,
+			ChangedBase:       opts.ChangedBase,
+		},
+	}
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s request: %w", t.name, err)
+	}
+	return input, nil
+}
+
+// decodeResponse validates the plugin's reply. Protocol errors are reported as
+// failed results rather than errors so one broken plugin does not stop a run.
+func (t execCheckType) decodeResponse(def CheckDefinition, run toolRun, command string) CheckResult {
+	invalid := func(signal string, detail string) CheckResult {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "fail",
+			Signal: signal,
+			Detail: detail,
+			Next:   "Fix plugin " + t.pluginID + " (" + command + ")",
+		}
+	}
+
+	var response execCheckResponse
+	if err := json.Unmarshal(run.Stdout, &response); err != nil {
+		detail := trimOutput(run.Stderr)
+		if len(run.Stderr) == 0 {
+			detail = trimOutput(run.Stdout)
+		}
+		return invalid(fmt.Sprintf("%s plugin returned invalid JSON (exit %d)", t.name, run.ExitCode), detail)
+	}
+	if response.Protocol != t.protocol {
+		return invalid(
+			fmt.Sprintf("%s plugin answered protocol %d, want %d", t.name, response.Protocol, t.protocol),
+			response.Error,
+		)
+	}
+	if response.Error != "" {
+		return invalid(t.name+" plugin error", response.Error)
+	}
+	if response.Result == nil {
+		return invalid(t.name+" plugin returned no result", trimOutput(run.Stderr))
+	}
+	result := *response.Result
+	result.ID = def.ID
+	switch result.Status {
+	case "pass", "fail", "warn", "skip":
+	default:
+		return invalid(fmt.Sprintf("%s plugin returned invalid status %q", t.name, result.Status), "")
+	}
+	return result
+}
+
+// pluginCheckTypes validates the check_types of a plugin loaded from
+// pluginDir and builds their handlers. Plugin types may replace other plugin
+// types (a project plugin overriding a user plugin) but never a compiled-in
+// type.
+func pluginCheckTypes(pluginDir string, manifest Manifest) ([]CheckType, error) {
+	var handlers []CheckType
+	for _, spec := range manifest.CheckTypes {
+		if spec.Name == "" || (spec.Exec == "") == (spec.Wasm == "") {
+			return nil, fmt.Errorf("check type needs a name and one of exec or wasm")
+		}
+		if existing, ok := checkRegistry[spec.Name]; ok && !isPluginCheckType(existing) {
+			return nil, fmt.Errorf("check type %s is builtin", spec.Name)
+		}
+		protocol := spec.Protocol
+		if protocol == 0 {
+			protocol = execProtocolVersions[len(execProtocolVersions)-1]
+		}
+		if !slices.Contains(execProtocolVersions, protocol) {
+			return nil, fmt.Errorf("check type %s: unsupported protocol %d (supported: %s)", spec.Name, protocol, joinInts(execProtocolVersions))
+		}
+		file := spec.Exec
+		if spec.Wasm != "" {
+			file = spec.Wasm
+		}
+		path, err := pluginFilePath(pluginDir, file)
+		if err != nil {
+			return nil, fmt.Errorf("check type %s: %w", spec.Name, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("check type %s: %w", spec.Name, err)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("check type %s: %s is a directory", spec.Name, file)
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		handler := execCheckType{
+			name:     spec.Name,
+			path:     path,
+			args:     spec.Args,
+			timeout:  spec.Timeout,
+			protocol: protocol,
+			pluginID: manifest.ID,
+		}
+		if spec.Wasm != "" {
+			handlers = append(handlers, wasmCheckType{execCheckType: handler})
+		} else {
+			handlers = append(handlers, handler)
+		}
+	}
+	return handlers, nil
+}
+
+// registerPluginCheckTypes makes the check types of plugins, a resolved
+// plugin set, the only plugin types in the registry. Two plugins declaring
+// the same type name is an error, and leaves the registry unchanged.
+func registerPluginCheckTypes(plugins []Plugin) error {
+	declaredBy := map[string]string{}
+	var handlers []CheckType
+	for _, p := range plugins {
+		if p.Dir == "" || len(p.Manifest.CheckTypes) == 0 {
+			continue
+		}
+		types, err := pluginCheckTypes(p.Dir, p.Manifest)
+		if err != nil {
+			return fmt.Errorf("plugin %s: %w", p.Manifest.ID, err)
+		}
+		for _, handler := range types {
+			if other, ok := declaredBy[handler.Type()]; ok {
+				return fmt.Errorf("check type %s is declared by plugins %s and %s", handler.Type(), other, p.Manifest.ID)
+			}
+			declaredBy[handler.Type()] = p.Manifest.ID
+		}
+		handlers = append(handlers, types...)
+	}
+	for name, handler := range checkRegistry {
+		if isPluginCheckType(handler) {
+			delete(checkRegistry, name)
+		}
+	}
+	for _, handler := range handlers {
+		RegisterCheckType(handler)
+	}
+	return nil
+}
+
+func isPluginCheckType(handler CheckType) bool {
+	switch handler.(type) {
+	case execCheckType, wasmCheckType:
+		return true
+	}
+	return false
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
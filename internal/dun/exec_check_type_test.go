@@ -0,0 +1,183 @@
+package dun
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeExecPlugin creates a plugin directory declaring one exec check type
+// backed by a shell script.
+func writeExecPlugin(t *testing.T, typeName string, extra string, script string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "bin"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	manifest := "id: exec-test\nversion: \"1\"\ncheck_types:\n  - name: " + typeName + "\n    exec: bin/check\n" + extra
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), manifest)
+	if err := os.WriteFile(filepath.Join(dir, "bin", "check"), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatalf("write exec: %v", err)
+	}
+	if _, exists := checkRegistry[typeName]; !exists {
+		t.Cleanup(func() { delete(checkRegistry, typeName) })
+	}
+	return dir
+}
+
+// loadCheckTypePlugin loads the plugin in dir and registers its check types.
+func loadCheckTypePlugin(t *testing.T, dir string) Plugin {
+	t.Helper()
+	plugin, err := loadPluginFromPath(dir)
+	if err != nil {
+		t.Fatalf("load plugin: %v", err)
+	}
+	if err := registerPluginCheckTypes([]Plugin{plugin}); err != nil {
+		t.Fatalf("register check types: %v", err)
+	}
+	return plugin
+}
+
+func runExecType(t *testing.T, typeName string, spec Check) CheckResult {
+	t.Helper()
+	handler, ok := LookupCheckType(typeName)
+	if !ok {
+		t.Fatalf("check type %s not registered", typeName)
+	}
+	cfg, err := handler.Decode(spec)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	res, err := handler.Run(t.TempDir(), CheckDefinition{ID: spec.ID, Phase: "test"}, cfg, Options{AutomationMode: "auto"}, Plugin{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return res
+}
+
+func TestExecCheckTypeRoundTrip(t *testing.T) {
+	dir := writeExecPlugin(t, "exec-roundtrip", "", `cat > "$OUT"
+echo '{"protocol":1,"result":{"id":"ignored","status":"warn","signal":"from plugin","issues":[{"id":"x","summary":"y"}]}}'
+`)
+	plugin := loadCheckTypePlugin(t, dir)
+	if plugin.Dir != dir || len(plugin.Manifest.CheckTypes) != 1 {
+		t.Fatalf("unexpected plugin %+v", plugin)
+	}
+
+	out := filepath.Join(t.TempDir(), "request.json")
+	res := runExecType(t, "exec-roundtrip", Check{
+		ID:     "custom",
+		Env:    map[string]string{"OUT": out},
+		Config: map[string]any{"threshold": 3},
+	})
+	if res.ID != "custom" || res.Status != "warn" || len(res.Issues) != 1 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read request: %v", err)
+	}
+	var request execCheckRequest
+	if err := json.Unmarshal(raw, &request); err != nil {
+		t.Fatalf("decode request: %v", err)
+	}
+	if request.Protocol != 1 || request.Type != "exec-roundtrip" || request.Check.ID != "custom" ||
+		request.Check.Config["threshold"] != float64(3) || request.Options.AutomationMode != "auto" || !filepath.IsAbs(request.Root) {
+		t.Fatalf("unexpected request %+v", request)
+	}
+}
+
+func TestExecCheckTypeProtocolErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		script string
+		signal string
+	}{
+		{"exec-bad-json", "echo not json; echo oops >&2; exit 2\n", "invalid JSON (exit 2)"},
+		{"exec-bad-protocol", `echo '{"protocol":2,"result":{"status":"pass"}}'` + "\n", "answered protocol 2"},
+		{"exec-bad-status", `echo '{"protocol":1,"result":{"status":"great"}}'` + "\n", "invalid status"},
+		{"exec-error", `echo '{"protocol":1,"error":"config.path is required"}'` + "\n", "plugin error"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeExecPlugin(t, tc.name, "", "cat >/dev/null\n"+tc.script)
+			loadCheckTypePlugin(t, dir)
+			res := runExecType(t, tc.name, Check{ID: "custom"})
+			if res.Status != "fail" || !strings.Contains(res.Signal, tc.signal) {
+				t.Fatalf("expected fail with %q, got %+v", tc.signal, res)
+			}
+		})
+	}
+}
+
+func TestExecCheckTypeTimeout(t *testing.T) {
+	dir := writeExecPlugin(t, "exec-slow", "    timeout: 100ms\n", "exec sleep 5\n")
+	loadCheckTypePlugin(t, dir)
+	res := runExecType(t, "exec-slow", Check{ID: "custom"})
+	if res.Status != "fail" || !strings.Contains(res.Signal, "timed out") {
+		t.Fatalf("expected timeout, got %+v", res)
+	}
+}
+
+func TestExecCheckTypeRejectsInvalidDeclarations(t *testing.T) {
+	builtin := writeExecPlugin(t, "command", "", "exit 0\n")
+	if _, err := loadPluginFromPath(builtin); err == nil || !strings.Contains(err.Error(), "is builtin") {
+		t.Fatalf("expected builtin shadowing error, got %v", err)
+	}
+	if _, ok := checkRegistry["command"].(execCheckType); ok {
+		t.Fatalf("builtin command type was replaced")
+	}
+
+	future := writeExecPlugin(t, "exec-future", "    protocol: 9\n", "exit 0\n")
+	if _, err := loadPluginFromPath(future); err == nil || !strings.Contains(err.Error(), "unsupported protocol 9") {
+		t.Fatalf("expected protocol error, got %v", err)
+	}
+
+	missing := writeExecPlugin(t, "exec-missing", "", "exit 0\n")
+	os.Remove(filepath.Join(missing, "bin", "check"))
+	if _, err := loadPluginFromPath(missing); err == nil {
+		t.Fatalf("expected missing executable error")
+	}
+}
+
+func TestRegisterPluginCheckTypesOnlyForResolvedSet(t *testing.T) {
+	first := writeExecPlugin(t, "exec-shared", "", "exit 0\n")
+	p1, err := loadPluginFromPath(first)
+	if err != nil {
+		t.Fatalf("load plugin: %v", err)
+	}
+	if _, ok := LookupCheckType("exec-shared"); ok {
+		t.Fatalf("loading a plugin must not register its check types")
+	}
+
+	second := writeExecPlugin(t, "exec-shared", "", "exit 0\n")
+	writeFile(t, filepath.Join(second, "plugin.yaml"), "id: other\nversion: \"1\"\ncheck_types:\n  - name: exec-shared\n    exec: bin/check\n")
+	p2, err := loadPluginFromPath(second)
+	if err != nil {
+		t.Fatalf("load plugin: %v", err)
+	}
+	err = registerPluginCheckTypes([]Plugin{p1, p2})
+	if err == nil || !strings.Contains(err.Error(), "declared by plugins exec-test and other") {
+		t.Fatalf("expected duplicate type error, got %v", err)
+	}
+	if _, ok := LookupCheckType("exec-shared"); ok {
+		t.Fatalf("failed registration changed the registry")
+	}
+
+	if err := registerPluginCheckTypes([]Plugin{p2}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	handler, _ := LookupCheckType("exec-shared")
+	if h, ok := handler.(execCheckType); !ok || h.pluginID != "other" {
+		t.Fatalf("expected type from plugin other, got %#v", handler)
+	}
+	if err := registerPluginCheckTypes(nil); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if _, ok := LookupCheckType("exec-shared"); ok {
+		t.Fatalf("types of plugins no longer loaded are still registered")
+	}
+}
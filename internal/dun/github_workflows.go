@@ -0,0 +1,238 @@
+package dun
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const workflowsDir = ".github/workflows"
+
+// actionRef matches "./local/path", "docker://image" and
+// "owner/repo[/path]@ref".
+var actionRef = regexp.MustCompile(`^(\./\S+|docker://\S+|[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+(/[^@\s]+)?@[^@\s]+)$`)
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+func runGitHubWorkflowsCheck(root string, def CheckDefinition) (CheckResult, error) {
+	files, err := workflowFiles(root)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if len(files) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "skip",
+			Signal: "no GitHub Actions workflows",
+		}, nil
+	}
+
+	var issues []Issue
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(root, file))
+		if err != nil {
+			return CheckResult{}, err
+		}
+		issues = append(issues, validateWorkflow(file, data)...)
+	}
+	if len(issues) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: fmt.Sprintf("%d workflows valid", len(files)),
+		}, nil
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("%d workflow problems", len(issues)),
+		Detail: fmt.Sprintf("%s:%d: %s", issues[0].Path, issues[0].Line, issues[0].Summary),
+		Next:   "Fix the workflow YAML at the reported lines.",
+		Issues: issues,
+	}, nil
+}
+
+func workflowFiles(root string) ([]string, error) {
+	var files []string
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		matches, err := filepath.Glob(filepath.Join(root, workflowsDir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			rel, err := filepath.Rel(root, match)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// workflowIssues collects problems for one workflow file.
+type workflowIssues struct {
+	path   string
+	issues []Issue
+}
+
+func (w *workflowIssues) add(node *yaml.Node, rule string, format string, args ...any) {
+	line := 0
+	if node != nil {
+		line = node.Line
+	}
+	w.issues = append(w.issues, Issue{
+		ID:       fmt.Sprintf("workflow:%s:%d:%s", w.path, line, rule),
+		Summary:  fmt.Sprintf(format, args...),
+		Path:     w.path,
+		Line:     line,
+		Severity: "fail",
+	})
+}
+
+// validateWorkflow checks structure offline: required keys, job and step
+// shape, uses: syntax, needs: references and ${{ }} balance.
+func validateWorkflow(path string, data []byte) []Issue {
+	w := &workflowIssues{path: path}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		line := 0
+		if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+			line, _ = strconv.Atoi(match[1])
+		}
+		w.issues = append(w.issues, Issue{
+			ID:       fmt.Sprintf("workflow:%s:%d:yaml", path, line),
+			Summary:  "invalid YAML: " + strings.TrimPrefix(err.Error(), "yaml: "),
+			Path:     path,
+			Line:     line,
+			Severity: "fail",
+		})
+		return w.issues
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		w.add(&doc, "structure", "workflow must be a mapping")
+		return w.issues
+	}
+	top := doc.Content[0]
+
+	if mappingValue(top, "on") == nil {
+		w.add(top, "required-key", "missing required key \"on\"")
+	}
+	jobs := mappingValue(top, "jobs")
+	if jobs == nil {
+		w.add(top, "required-key", "missing required key \"jobs\"")
+	} else if jobs.Kind != yaml.MappingNode || len(jobs.Content) == 0 {
+		w.add(jobs, "structure", "jobs must be a non-empty mapping")
+	} else {
+		validateWorkflowJobs(w, jobs)
+	}
+
+	checkExpressionBalance(w, top)
+	return w.issues
+}
+
+func validateWorkflowJobs(w *workflowIssues, jobs *yaml.Node) {
+	defined := map[string]bool{}
+	for i := 0; i+1 < len(jobs.Content); i += 2 {
+		defined[jobs.Content[i].Value] = true
+	}
+	for i := 0; i+1 < len(jobs.Content); i += 2 {
+		id := jobs.Content[i].Value
+		job := jobs.Content[i+1]
+		if job.Kind != yaml.MappingNode {
+			w.add(job, "structure", "job %q must be a mapping", id)
+			continue
+		}
+		uses := mappingValue(job, "uses")
+		if uses != nil {
+			// Reusable workflow call.
+			if !actionRef.MatchString(uses.Value) {
+				w.add(uses, "uses", "job %q: invalid uses: %q (want owner/repo/path@ref or ./path)", id, uses.Value)
+			}
+		} else {
+			if mappingValue(job, "runs-on") == nil {
+				w.add(jobs.Content[i], "required-key", "job %q: missing runs-on", id)
+			}
+			steps := mappingValue(job, "steps")
+			if steps == nil || steps.Kind != yaml.SequenceNode || len(steps.Content) == 0 {
+				w.add(jobs.Content[i], "required-key", "job %q: missing steps", id)
+			} else {
+				validateWorkflowSteps(w, id, steps)
+			}
+		}
+		if needs := mappingValue(job, "needs"); needs != nil {
+			refs := []*yaml.Node{needs}
+			if needs.Kind == yaml.SequenceNode {
+				refs = needs.Content
+			}
+			for _, ref := range refs {
+				if !defined[ref.Value] {
+					w.add(ref, "needs", "job %q needs undefined job %q", id, ref.Value)
+				} else if ref.Value == id {
+					w.add(ref, "needs", "job %q needs itself", id)
+				}
+			}
+		}
+	}
+}
+
+func validateWorkflowSteps(w *workflowIssues, jobID string, steps *yaml.Node) {
+	for n, step := range steps.Content {
+		if step.Kind != yaml.MappingNode {
+			w.add(step, "structure", "job %q step %d must be a mapping", jobID, n+1)
+			continue
+		}
+		uses := mappingValue(step, "uses")
+		run := mappingValue(step, "run")
+		switch {
+		case uses == nil && run == nil:
+			w.add(step, "step", "job %q step %d: needs uses or run", jobID, n+1)
+		case uses != nil && run != nil:
+			w.add(step, "step", "job %q step %d: has both uses and run", jobID, n+1)
+		case uses != nil && !actionRef.MatchString(uses.Value):
+			w.add(uses, "uses", "job %q step %d: invalid uses: %q (want owner/repo@ref, ./path or docker://image)", jobID, n+1, uses.Value)
+		}
+	}
+}
+
+// checkExpressionBalance reports scalars with a "${{" that is never closed.
+func checkExpressionBalance(w *workflowIssues, node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		rest := node.Value
+		for {
+			start := strings.Index(rest, "${{")
+			if start < 0 {
+				return
+			}
+			end := strings.Index(rest[start+3:], "}}")
+			if end < 0 {
+				w.add(node, "expression", "unterminated expression: %q", strings.TrimSpace(rest[start:]))
+				return
+			}
+			rest = rest[start+3+end+2:]
+		}
+	}
+	for _, child := range node.Content {
+		checkExpressionBalance(w, child)
+	}
+}
+
+// mappingValue returns the value node for key in a mapping node.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
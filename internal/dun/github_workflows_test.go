@@ -0,0 +1,97 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateWorkflowAcceptsValidWorkflow(t *testing.T) {
+	data := []byte(`name: CI
+on: [push]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: ./.github/actions/setup
+      - uses: docker://alpine:3
+      - run: echo ${{ github.sha }}
+  release:
+    needs: [build]
+    uses: octo/workflows/.github/workflows/release.yml@main
+`)
+	if issues := validateWorkflow("ci.yml", data); len(issues) != 0 {
+		t.Fatalf("expected no issues, got %+v", issues)
+	}
+}
+
+func TestValidateWorkflowReportsProblems(t *testing.T) {
+	data := []byte(`name: CI
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout
+      - name: nothing
+      - run: echo ${{ github.sha
+  deploy:
+    runs-on: ubuntu-latest
+    needs: [build, missing]
+    steps:
+      - uses: actions/checkout@v4
+        run: echo both
+`)
+	issues := validateWorkflow("ci.yml", data)
+	want := map[string]bool{
+		"workflow:ci.yml:1:required-key": false, // on
+		"workflow:ci.yml:3:required-key": false, // runs-on
+		"workflow:ci.yml:5:uses":         false,
+		"workflow:ci.yml:6:step":         false,
+		"workflow:ci.yml:7:expression":   false,
+		"workflow:ci.yml:10:needs":       false,
+		"workflow:ci.yml:12:step":        false,
+	}
+	for _, issue := range issues {
+		if _, ok := want[issue.ID]; !ok {
+			t.Fatalf("unexpected issue %+v", issue)
+		}
+		want[issue.ID] = true
+	}
+	for id, seen := range want {
+		if !seen {
+			t.Fatalf("missing issue %s in %+v", id, issues)
+		}
+	}
+}
+
+func TestValidateWorkflowReportsYAMLErrors(t *testing.T) {
+	issues := validateWorkflow("ci.yml", []byte("on: push\njobs:\n  build: [\n"))
+	if len(issues) != 1 || issues[0].Line == 0 {
+		t.Fatalf("expected one located YAML issue, got %+v", issues)
+	}
+}
+
+func TestGitHubWorkflowsCheck(t *testing.T) {
+	root := t.TempDir()
+	res, err := runGitHubWorkflowsCheck(root, CheckDefinition{ID: "github-workflows"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Status != "skip" {
+		t.Fatalf("expected skip, got %+v", res)
+	}
+
+	dir := filepath.Join(root, ".github", "workflows")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "ci.yaml"), "on: push\njobs:\n  a:\n    runs-on: x\n    steps:\n      - run: true\n")
+	writeFile(t, filepath.Join(dir, "bad.yml"), "on: push\njobs:\n  a:\n    runs-on: x\n    needs: b\n    steps:\n      - run: true\n")
+	res, err = runGitHubWorkflowsCheck(root, CheckDefinition{ID: "github-workflows"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 1 || res.Issues[0].Path != ".github/workflows/bad.yml" {
+		t.Fatalf("expected one needs issue, got %+v", res)
+	}
+}
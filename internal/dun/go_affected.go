@@ -0,0 +1,277 @@
+package dun
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// goListPackage is the subset of `go list -json` output used for test impact
+// analysis and import rules.
+type goListPackage struct {
+	ImportPath string
+	Dir        string
+	ForTest    string
+	Standard   bool
+	GoFiles    []string
+	CgoFiles   []string
+	Imports    []string
+	Deps       []string
+	Module     *goListModule
+}
+
+type goListModule struct {
+	Path string
+	Main bool
+}
+
+// goPackageScope is the set of packages the Go checks should run on. All means
+// ./...; otherwise Packages lists import paths (possibly none).
+type goPackageScope struct {
+	All      bool
+	Packages []string
+	Reason   string
+}
+
+// goChangedFilesFunc allows mocking in tests.
+var goChangedFilesFunc = goChangedFiles
+
+// goListFunc allows mocking in tests.
+var goListFunc = goListDeps
+
+// resolveGoPackageScope maps changed files to the packages that contain them
+// plus every main-module package that depends on those, including through
+// tests. It falls back to all packages when affected mode is off, when module
+// files changed, or when the analysis fails.
+func resolveGoPackageScope(root string, opts Options) goPackageScope {
+	if !opts.GoAffected && opts.ChangedBase == "" {
+		return goPackageScope{All: true}
+	}
+	changed, err := goChangedFilesFunc(root, opts.ChangedBase)
+	if err != nil {
+		return goPackageScope{All: true, Reason: "affected analysis failed: " + err.Error()}
+	}
+	for _, file := range changed {
+		switch filepath.Base(file) {
+		case "go.mod", "go.sum", "go.work", "go.work.sum":
+			return goPackageScope{All: true, Reason: file + " changed"}
+		}
+	}
+	if len(changed) == 0 {
+		return goPackageScope{Reason: "no changed files"}
+	}
+	pkgs, err := goListFunc(root)
+	if err != nil {
+		return goPackageScope{All: true, Reason: "affected analysis failed: " + err.Error()}
+	}
+	affected := affectedGoPackages(root, changed, pkgs)
+	return goPackageScope{
+		Packages: affected,
+		Reason:   fmt.Sprintf("%d affected packages", len(affected)),
+	}
+}
+
+// affectedGoPackages returns the main-module packages whose directory holds a
+// changed file (testdata included), closed over reverse dependencies.
+func affectedGoPackages(root string, changed []string, pkgs []goListPackage) []string {
+	mainPkgs := map[string]bool{}
+	byDir := map[string]string{}
+	reverse := map[string][]string{}
+	for _, pkg := range pkgs {
+		path := goListBasePath(pkg)
+		if pkg.Module != nil && pkg.Module.Main && pkg.ForTest == "" && !strings.Contains(pkg.ImportPath, " ") && !strings.HasSuffix(pkg.ImportPath, ".test") {
+			mainPkgs[path] = true
+			byDir[filepath.Clean(pkg.Dir)] = path
+		}
+		for _, imp := range pkg.Imports {
+			imp = goListStripVariant(imp)
+			if imp != path {
+				reverse[imp] = append(reverse[imp], path)
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	var queue []string
+	for _, file := range changed {
+		dir := filepath.Dir(filepath.Join(root, filepath.FromSlash(file)))
+		if idx := strings.Index(filepath.ToSlash(dir)+"/", "/testdata/"); idx >= 0 {
+			dir = filepath.FromSlash(filepath.ToSlash(dir)[:idx])
+		}
+		if path, ok := byDir[filepath.Clean(dir)]; ok && !seen[path] {
+			seen[path] = true
+			queue = append(queue, path)
+		}
+	}
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		for _, importer := range reverse[path] {
+			if !seen[importer] {
+				seen[importer] = true
+				queue = append(queue, importer)
+			}
+		}
+	}
+
+	var out []string
+	for path := range seen {
+		if mainPkgs[path] {
+			out = append(out, path)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// goListBasePath maps test variants ("p [p.test]", "p_test [p.test]") back to
+// the package under test.
+func goListBasePath(pkg goListPackage) string {
+	if pkg.ForTest != "" {
+		return pkg.ForTest
+	}
+	return goListStripVariant(pkg.ImportPath)
+}
+
+func goListStripVariant(importPath string) string {
+	if idx := strings.Index(importPath, " ["); idx >= 0 {
+		return importPath[:idx]
+	}
+	return importPath
+}
+
+func goListDeps(root string) ([]goListPackage, error) {
+	return runGoList(root, "-deps", "-test", "-json", "./...")
+}
+
+func runGoList(root string, args ...string) ([]goListPackage, error) {
+	cmd := exec.Command("go", append([]string{"list"}, args...)...)
+	cmd.Dir = root
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("go list: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("go list: %w", err)
+	}
+	return parseGoListPackages(output)
+}
+
+func parseGoListPackages(output []byte) ([]goListPackage, error) {
+	var pkgs []goListPackage
+	dec := json.NewDecoder(strings.NewReader(string(output)))
+	for {
+		var pkg goListPackage
+		if err := dec.Decode(&pkg); err != nil {
+			if err == io.EOF {
+				return pkgs, nil
+			}
+			return nil, fmt.Errorf("parse go list output: %w", err)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+}
+
+// goChangedFiles lists tracked files that differ from base (HEAD by default,
+// working tree included) plus untracked files, relative to root.
+func goChangedFiles(root string, base string) ([]string, error) {
+	if base == "" {
+		base = "HEAD"
+	}
+	diff := exec.Command("git", "diff", "--name-only", "--relative", base)
+	diff.Dir = root
+	output, err := diff.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s: %w", base, err)
+	}
+	untracked := exec.Command("git", "ls-files", "--others", "--exclude-standard")
+	untracked.Dir = root
+	extra, err := untracked.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(output)+"\n"+string(extra), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// goPackageArgs returns the package arguments for a go command.
+func goPackageArgs(packages []string) []string {
+	if len(packages) == 0 {
+		return []string{"./..."}
+	}
+	return packages
+}
+
+// goPackageScopes holds the scopes resolved during a run, keyed by root and
+// base, so go-test, go-vet and go-coverage share one diff and go list. It is
+// nil outside a run.
+var goPackageScopes = struct {
+	sync.Mutex
+	byKey map[string]goPackageScope
+}{}
+
+// cacheGoPackageScopes starts caching package scopes; runs defer
+// clearGoPackageScopes to drop them when their checks are done.
+func cacheGoPackageScopes() {
+	goPackageScopes.Lock()
+	defer goPackageScopes.Unlock()
+	goPackageScopes.byKey = map[string]goPackageScope{}
+}
+
+func clearGoPackageScopes() {
+	goPackageScopes.Lock()
+	defer goPackageScopes.Unlock()
+	goPackageScopes.byKey = nil
+}
+
+// cachedGoPackageScope returns the run's scope for root, resolving it on
+// first use.
+func cachedGoPackageScope(root string, opts Options) goPackageScope {
+	goPackageScopes.Lock()
+	defer goPackageScopes.Unlock()
+	if goPackageScopes.byKey == nil {
+		return resolveGoPackageScope(root, opts)
+	}
+	key := root + "\x00" + opts.ChangedBase
+	scope, ok := goPackageScopes.byKey[key]
+	if !ok {
+		scope = resolveGoPackageScope(root, opts)
+		goPackageScopes.byKey[key] = scope
+	}
+	return scope
+}
+
+// runScopedGoCheck resolves the package scope and runs check on it, passing
+// without running anything when no package is affected.
+func runScopedGoCheck(root string, def CheckDefinition, opts Options, check func(packages []string) (CheckResult, error)) (CheckResult, error) {
+	scope := cachedGoPackageScope(root, opts)
+	if !scope.All && len(scope.Packages) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: "no affected Go packages",
+			Detail: scope.Reason,
+		}, nil
+	}
+	res, err := check(scope.Packages)
+	if err != nil {
+		return res, err
+	}
+	if scope.Reason != "" && res.Signal != "" {
+		res.Signal = fmt.Sprintf("%s (%s)", res.Signal, scope.Reason)
+	}
+	return res, nil
+}
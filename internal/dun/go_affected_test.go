@@ -0,0 +1,186 @@
+package dun
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func affectedFixture(root string) []goListPackage {
+	main := &goListModule{Path: "example.com/app", Main: true}
+	return []goListPackage{
+		{ImportPath: "fmt"},
+		{ImportPath: "example.com/app/internal/b", Dir: filepath.Join(root, "internal", "b"), Module: main, Imports: []string{"fmt"}},
+		{ImportPath: "example.com/app/internal/a", Dir: filepath.Join(root, "internal", "a"), Module: main, Imports: []string{"example.com/app/internal/b"}},
+		{ImportPath: "example.com/app/internal/c", Dir: filepath.Join(root, "internal", "c"), Module: main},
+		{
+			ImportPath: "example.com/app/internal/c_test [example.com/app/internal/c.test]",
+			Dir:        filepath.Join(root, "internal", "c"),
+			ForTest:    "example.com/app/internal/c",
+			Module:     main,
+			Imports:    []string{"example.com/app/internal/b [example.com/app/internal/c.test]"},
+		},
+		{ImportPath: "example.com/app/internal/c.test", Dir: filepath.Join(root, "internal", "c"), Module: main},
+		{ImportPath: "example.com/app", Dir: root, Module: main, Imports: []string{"example.com/app/internal/a"}},
+	}
+}
+
+func TestAffectedGoPackagesFollowsReverseDeps(t *testing.T) {
+	root := t.TempDir()
+	got := affectedGoPackages(root, []string{"internal/b/b.go"}, affectedFixture(root))
+	want := []string{"example.com/app", "example.com/app/internal/a", "example.com/app/internal/b", "example.com/app/internal/c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestAffectedGoPackagesMapsTestdataAndIgnoresOtherFiles(t *testing.T) {
+	root := t.TempDir()
+	got := affectedGoPackages(root, []string{"internal/a/testdata/golden.txt", "docs/readme.md"}, affectedFixture(root))
+	want := []string{"example.com/app", "example.com/app/internal/a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestResolveGoPackageScope(t *testing.T) {
+	origChanged, origList := goChangedFilesFunc, goListFunc
+	t.Cleanup(func() { goChangedFilesFunc, goListFunc = origChanged, origList })
+	root := t.TempDir()
+	var changed []string
+	goChangedFilesFunc = func(string, string) ([]string, error) { return changed, nil }
+	goListFunc = func(string) ([]goListPackage, error) { return affectedFixture(root), nil }
+
+	if scope := resolveGoPackageScope(root, Options{}); !scope.All || scope.Reason != "" {
+		t.Fatalf("expected all packages without affected mode, got %+v", scope)
+	}
+
+	changed = []string{"internal/a/a.go", "go.sum"}
+	if scope := resolveGoPackageScope(root, Options{GoAffected: true}); !scope.All || scope.Reason != "go.sum changed" {
+		t.Fatalf("expected fallback on go.sum change, got %+v", scope)
+	}
+
+	changed = []string{"internal/a/a.go"}
+	scope := resolveGoPackageScope(root, Options{ChangedBase: "main"})
+	if scope.All || !reflect.DeepEqual(scope.Packages, []string{"example.com/app", "example.com/app/internal/a"}) {
+		t.Fatalf("expected affected packages, got %+v", scope)
+	}
+
+	changed = nil
+	if scope := resolveGoPackageScope(root, Options{GoAffected: true}); scope.All || len(scope.Packages) != 0 {
+		t.Fatalf("expected empty scope, got %+v", scope)
+	}
+}
+
+func TestRunScopedGoCheckPassesWhenNothingAffected(t *testing.T) {
+	origChanged := goChangedFilesFunc
+	t.Cleanup(func() { goChangedFilesFunc = origChanged })
+	goChangedFilesFunc = func(string, string) ([]string, error) { return nil, nil }
+
+	called := false
+	res, err := runScopedGoCheck(t.TempDir(), CheckDefinition{ID: "go-test"}, Options{GoAffected: true}, func([]string) (CheckResult, error) {
+		called = true
+		return CheckResult{}, nil
+	})
+	if err != nil {
+		t.Fatalf("scoped check: %v", err)
+	}
+	if called || res.Status != "pass" || res.Signal != "no affected Go packages" {
+		t.Fatalf("expected pass without running, got %+v", res)
+	}
+}
+
+func TestGoPackageScopeResolvedOncePerRun(t *testing.T) {
+	origChanged := goChangedFilesFunc
+	t.Cleanup(func() { goChangedFilesFunc = origChanged })
+	calls := 0
+	goChangedFilesFunc = func(string, string) ([]string, error) {
+		calls++
+		return nil, nil
+	}
+	run := func(root string) {
+		t.Helper()
+		if _, err := runScopedGoCheck(root, CheckDefinition{ID: "go-test"}, Options{GoAffected: true}, func([]string) (CheckResult, error) {
+			return CheckResult{}, nil
+		}); err != nil {
+			t.Fatalf("scoped check: %v", err)
+		}
+	}
+
+	root, other := t.TempDir(), t.TempDir()
+	cacheGoPackageScopes()
+	run(root)
+	run(root)
+	run(other)
+	clearGoPackageScopes()
+	if calls != 2 {
+		t.Fatalf("expected one analysis per root during a run, got %d", calls)
+	}
+	run(root)
+	if calls != 3 {
+		t.Fatalf("expected no caching outside a run, got %d analyses", calls)
+	}
+}
+
+func TestRunGoTestCheckUsesAffectedPackages(t *testing.T) {
+	binDir, argsFile := stubGoTestEvents(t, "", 0)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	config := GoTestConfig{Packages: []string{"example.com/app", "example.com/app/internal/a"}}
+	if _, err := runGoTestCheck(t.TempDir(), CheckDefinition{ID: "go-test"}, config); err != nil {
+		t.Fatalf("go test check: %v", err)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("read args: %v", err)
+	}
+	if string(args) != "test -json example.com/app example.com/app/internal/a\n" {
+		t.Fatalf("unexpected args %q", string(args))
+	}
+}
+
+func TestParseGoListPackages(t *testing.T) {
+	output := `{"ImportPath": "example.com/app", "Dir": "/src/app", "Module": {"Main": true}}
+{"ImportPath": "example.com/app [example.com/app.test]", "ForTest": "example.com/app", "Imports": ["fmt"]}
+`
+	pkgs, err := parseGoListPackages([]byte(output))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(pkgs) != 2 || !pkgs[0].Module.Main || goListBasePath(pkgs[1]) != "example.com/app" {
+		t.Fatalf("unexpected packages %+v", pkgs)
+	}
+	if _, err := parseGoListPackages([]byte("{bad")); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestGoChangedFilesIncludesUntracked(t *testing.T) {
+	root := tempGitRepo(t)
+	writeFile(t, filepath.Join(root, "tracked.go"), "package app\n")
+	for _, args := range [][]string{
+		{"add", "tracked.go"},
+		{"-c", "user.email=dun@example.com", "-c", "user.name=dun", "commit", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, output)
+		}
+	}
+	writeFile(t, filepath.Join(root, "tracked.go"), "package app\n\nvar x = 1\n")
+	writeFile(t, filepath.Join(root, "new.go"), "package app\n")
+
+	files, err := goChangedFiles(root, "")
+	if err != nil {
+		t.Fatalf("changed files: %v", err)
+	}
+	if !reflect.DeepEqual(files, []string{"tracked.go", "new.go"}) {
+		t.Fatalf("unexpected files %v", files)
+	}
+	if _, err := goChangedFiles(root, "no-such-ref"); err == nil {
+		t.Fatalf("expected error for unknown ref")
+	}
+}
@@ -0,0 +1,455 @@
+package dun
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const defaultAPIDiffBaseline = "HEAD~1"
+
+var majorVersionSuffix = regexp.MustCompile(`/v(\d+)$`)
+
+// apiObject is the exported surface of one package-level declaration. Members
+// holds struct fields, interface methods and methods by name.
+type apiObject struct {
+	Kind      string
+	Type      string
+	Members   map[string]string
+	Interface bool
+	Position  token.Position
+}
+
+// apiPackage maps exported names to their API.
+type apiPackage map[string]apiObject
+
+type apiChange struct {
+	Package      string
+	Name         string
+	Summary      string
+	Incompatible bool
+	Position     token.Position
+}
+
+func runGoAPIDiffCheck(root string, def CheckDefinition, config GoAPIDiffConfig) (CheckResult, error) {
+	baseline := config.Baseline
+	if baseline == "" {
+		baseline = defaultAPIDiffBaseline
+	}
+
+	baseDir, cleanup, err := checkoutBaseline(root, baseline)
+	if err != nil {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "skip",
+			Signal: "cannot load API baseline",
+			Detail: err.Error(),
+		}, nil
+	}
+	defer cleanup()
+
+	baseModule := readModulePath(filepath.Join(baseDir, "go.mod"))
+	headModule := readModulePath(filepath.Join(root, "go.mod"))
+	if headModule == "" {
+		return CheckResult{}, fmt.Errorf("go.mod missing module path")
+	}
+
+	before, err := loadModuleAPI(baseDir, baseModule)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	after, err := loadModuleAPI(root, headModule)
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	changes := diffModuleAPI(before, after, baseModule, headModule)
+	var incompatible []apiChange
+	compatible := 0
+	for _, change := range changes {
+		if change.Incompatible {
+			incompatible = append(incompatible, change)
+		} else {
+			compatible++
+		}
+	}
+
+	if len(incompatible) == 0 {
+		signal := "exported API unchanged since " + baseline
+		if compatible > 0 {
+			signal = fmt.Sprintf("%d compatible API changes since %s", compatible, baseline)
+		}
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: signal,
+		}, nil
+	}
+
+	if majorVersion(baseModule) != majorVersion(headModule) {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: fmt.Sprintf("%d incompatible API changes allowed by major version change", len(incompatible)),
+			Detail: fmt.Sprintf("module path changed from %s to %s", baseModule, headModule),
+		}, nil
+	}
+
+	var issues []Issue
+	var lines []string
+	for _, change := range incompatible {
+		issues = append(issues, Issue{
+			ID:       "api:" + change.Package + "." + change.Name,
+			Summary:  change.Summary,
+			Path:     change.Position.Filename,
+			Line:     change.Position.Line,
+			Severity: "fail",
+		})
+		lines = append(lines, change.Summary)
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("%d incompatible API changes since %s", len(incompatible), baseline),
+		Detail: trimOutput([]byte(strings.Join(lines, "\n"))),
+		Next:   "Restore the exported API (add new symbols instead of changing old ones) or bump the module major version.",
+		Issues: issues,
+	}, nil
+}
+
+// checkoutBaseline materializes ref in a temporary git worktree and returns
+// the directory in it that corresponds to root.
+func checkoutBaseline(root string, ref string) (string, func(), error) {
+	prefixCmd := exec.Command("git", "rev-parse", "--show-prefix")
+	prefixCmd.Dir = root
+	prefix, err := prefixCmd.Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("git rev-parse --show-prefix: %w", err)
+	}
+	tmpDir, err := os.MkdirTemp("", "dun-apidiff-*")
+	if err != nil {
+		return "", nil, err
+	}
+	cmd := exec.Command("git", "worktree", "add", "--detach", tmpDir, ref)
+	cmd.Dir = root
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", nil, fmt.Errorf("git worktree add %s: %s", ref, strings.TrimSpace(string(output)))
+	}
+	cleanup := func() {
+		remove := exec.Command("git", "worktree", "remove", "--force", tmpDir)
+		remove.Dir = root
+		_ = remove.Run()
+		os.RemoveAll(tmpDir)
+	}
+	// The worktree holds the whole repository; the module may live below
+	// its top level.
+	dir := filepath.Join(tmpDir, filepath.FromSlash(strings.TrimSpace(string(prefix))))
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		cleanup()
+		return "", nil, fmt.Errorf("%s does not exist at %s", strings.TrimSpace(string(prefix)), ref)
+	}
+	return dir, cleanup, nil
+}
+
+func readModulePath(goModPath string) string {
+	f, err := os.Open(goModPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+		}
+	}
+	return ""
+}
+
+func majorVersion(modulePath string) string {
+	if match := majorVersionSuffix.FindStringSubmatch(modulePath); match != nil {
+		return match[1]
+	}
+	return "1"
+}
+
+// loadModuleAPI type-checks every public (non-internal, non-main) package in
+// the module rooted at dir, keyed by import path relative to the module.
+func loadModuleAPI(dir string, modulePath string) (map[string]apiPackage, error) {
+	fset := token.NewFileSet()
+	imp := importer.ForCompiler(fset, "source", nil)
+	api := map[string]apiPackage{}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && (isIgnoredGoDir(d.Name()) || d.Name() == "internal") {
+			return filepath.SkipDir
+		}
+		if path != dir {
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		importPath := modulePath
+		if rel != "." {
+			importPath = modulePath + "/" + rel
+		}
+		pkg, err := loadPackageAPI(fset, imp, dir, path, importPath)
+		if err != nil || pkg == nil {
+			return err
+		}
+		api[rel] = pkg
+		return nil
+	})
+	return api, err
+}
+
+func loadPackageAPI(fset *token.FileSet, imp types.Importer, root string, dir string, importPath string) (apiPackage, error) {
+	// Directories without buildable Go files have no API.
+	bp, err := build.Default.ImportDir(dir, 0)
+	if err != nil || bp.Name == "main" {
+		return nil, nil
+	}
+
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+		files = append(files, file)
+	}
+
+	// Type errors (for example unresolvable imports) are tolerated: the
+	// checker still records every declaration, with invalid types where needed.
+	conf := types.Config{Importer: imp, Error: func(error) {}}
+	pkg, _ := conf.Check(importPath, fset, files, nil)
+	if pkg == nil {
+		return nil, nil
+	}
+
+	qualifier := func(p *types.Package) string { return p.Path() }
+	api := apiPackage{}
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		entry := apiObject{Position: relPosition(root, fset.Position(obj.Pos()))}
+		switch o := obj.(type) {
+		case *types.Func:
+			entry.Kind = "func"
+			entry.Type = types.TypeString(o.Type(), qualifier)
+		case *types.Var:
+			entry.Kind = "var"
+			entry.Type = types.TypeString(o.Type(), qualifier)
+		case *types.Const:
+			entry.Kind = "const"
+			entry.Type = types.TypeString(o.Type(), qualifier)
+		case *types.TypeName:
+			entry.Kind = "type"
+			entry.Members = map[string]string{}
+			describeNamedType(o, qualifier, &entry)
+		default:
+			continue
+		}
+		api[name] = entry
+	}
+	return api, nil
+}
+
+func describeNamedType(obj *types.TypeName, qualifier types.Qualifier, entry *apiObject) {
+	typ := obj.Type()
+	switch u := typ.Underlying().(type) {
+	case *types.Struct:
+		entry.Type = "struct"
+		for i := 0; i < u.NumFields(); i++ {
+			field := u.Field(i)
+			if field.Exported() {
+				entry.Members["field "+field.Name()] = types.TypeString(field.Type(), qualifier)
+			}
+		}
+	case *types.Interface:
+		entry.Type = "interface"
+		entry.Interface = true
+		for i := 0; i < u.NumMethods(); i++ {
+			method := u.Method(i)
+			// Unexported methods make the interface impossible to implement
+			// outside the package, so adding one is still a breaking change.
+			entry.Members["method "+method.Name()] = types.TypeString(method.Type(), qualifier)
+		}
+	default:
+		entry.Type = types.TypeString(u, qualifier)
+	}
+	if obj.IsAlias() {
+		entry.Type = "= " + types.TypeString(typ, qualifier)
+	}
+	if _, ok := typ.Underlying().(*types.Interface); ok {
+		return
+	}
+	mset := types.NewMethodSet(types.NewPointer(typ))
+	for i := 0; i < mset.Len(); i++ {
+		method := mset.At(i).Obj()
+		if method.Exported() {
+			entry.Members["method "+method.Name()] = types.TypeString(method.Type(), qualifier)
+		}
+	}
+}
+
+func relPosition(root string, pos token.Position) token.Position {
+	if rel, err := filepath.Rel(root, pos.Filename); err == nil {
+		pos.Filename = filepath.ToSlash(rel)
+	}
+	return pos
+}
+
+// diffModuleAPI compares two module APIs. Type strings mention the module
+// path, so the baseline path is rewritten to the current one first; a major
+// version bump is judged separately.
+func diffModuleAPI(before map[string]apiPackage, after map[string]apiPackage, baseModule string, headModule string) []apiChange {
+	var changes []apiChange
+	for _, rel := range sortedAPIPackages(before) {
+		pkgPath := joinModulePath(headModule, rel)
+		current, ok := after[rel]
+		if !ok {
+			changes = append(changes, apiChange{
+				Package:      pkgPath,
+				Summary:      fmt.Sprintf("package %s removed", pkgPath),
+				Incompatible: true,
+			})
+			continue
+		}
+		changes = append(changes, diffPackageAPI(pkgPath, before[rel], current, baseModule, headModule)...)
+	}
+	for _, rel := range sortedAPIPackages(after) {
+		if _, ok := before[rel]; !ok {
+			changes = append(changes, apiChange{
+				Package: joinModulePath(headModule, rel),
+				Summary: fmt.Sprintf("package %s added", joinModulePath(headModule, rel)),
+			})
+		}
+	}
+	return changes
+}
+
+func diffPackageAPI(pkgPath string, before apiPackage, after apiPackage, baseModule string, headModule string) []apiChange {
+	rewrite := func(s string) string {
+		if baseModule == headModule || baseModule == "" {
+			return s
+		}
+		return strings.ReplaceAll(s, baseModule, headModule)
+	}
+
+	var changes []apiChange
+	add := func(name string, incompatible bool, pos token.Position, format string, args ...any) {
+		changes = append(changes, apiChange{
+			Package:      pkgPath,
+			Name:         name,
+			Summary:      fmt.Sprintf("%s.%s: %s", pkgPath, name, fmt.Sprintf(format, args...)),
+			Incompatible: incompatible,
+			Position:     pos,
+		})
+	}
+
+	for _, name := range sortedAPINames(before) {
+		old := before[name]
+		cur, ok := after[name]
+		if !ok {
+			add(name, true, old.Position, "%s removed", old.Kind)
+			continue
+		}
+		if old.Kind != cur.Kind {
+			add(name, true, cur.Position, "changed from %s to %s", old.Kind, cur.Kind)
+			continue
+		}
+		if rewrite(old.Type) != cur.Type {
+			if old.Kind == "func" {
+				add(name, true, cur.Position, "signature changed from %s to %s", rewrite(old.Type), cur.Type)
+			} else {
+				add(name, true, cur.Position, "type changed from %s to %s", rewrite(old.Type), cur.Type)
+			}
+			continue
+		}
+		for _, member := range sortedMemberNames(old.Members) {
+			curType, ok := cur.Members[member]
+			if !ok {
+				add(name, true, cur.Position, "%s removed", member)
+			} else if rewrite(old.Members[member]) != curType {
+				add(name, true, cur.Position, "%s changed from %s to %s", member, rewrite(old.Members[member]), curType)
+			}
+		}
+		for _, member := range sortedMemberNames(cur.Members) {
+			if _, ok := old.Members[member]; ok {
+				continue
+			}
+			// Implementations outside the package stop satisfying an
+			// interface that gained a method.
+			add(name, cur.Interface, cur.Position, "%s added", member)
+		}
+	}
+	for _, name := range sortedAPINames(after) {
+		if _, ok := before[name]; !ok {
+			add(name, false, after[name].Position, "%s added", after[name].Kind)
+		}
+	}
+	return changes
+}
+
+func joinModulePath(modulePath string, rel string) string {
+	if rel == "." {
+		return modulePath
+	}
+	return modulePath + "/" + rel
+}
+
+func sortedAPIPackages(api map[string]apiPackage) []string {
+	keys := make([]string, 0, len(api))
+	for key := range api {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedAPINames(pkg apiPackage) []string {
+	keys := make([]string, 0, len(pkg))
+	for key := range pkg {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedMemberNames(members map[string]string) []string {
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,216 @@
+package dun
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func apiDiffRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := tempGitRepo(t)
+	writeAPIDiffFiles(t, root, files)
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"-c", "user.email=dun@example.com", "-c", "user.name=dun", "commit", "-m", "base"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, output)
+		}
+	}
+	return root
+}
+
+func writeAPIDiffFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		writeFile(t, path, content)
+	}
+}
+
+const apiDiffBase = `package lib
+
+// Store is implemented by callers.
+type Store interface {
+	Get(key string) (string, error)
+}
+
+type Options struct {
+	Name string
+	size int
+}
+
+func Open(name string) (*Options, error) { return &Options{Name: name}, nil }
+
+func (o *Options) Size() int { return o.size }
+
+func Close() {}
+
+const Version = "1"
+`
+
+func TestGoAPIDiffReportsIncompatibleChanges(t *testing.T) {
+	root := apiDiffRepo(t, map[string]string{
+		"go.mod":          "module example.com/app\n\ngo 1.21\n",
+		"lib/lib.go":      apiDiffBase,
+		"internal/x/x.go": "package x\n\nfunc Gone() {}\n",
+	})
+	writeAPIDiffFiles(t, root, map[string]string{
+		"lib/lib.go": `package lib
+
+type Store interface {
+	Get(key string) (string, error)
+	Put(key, value string) error
+}
+
+type Options struct {
+	Name    string
+	Verbose bool
+}
+
+func Open(name string, verbose bool) (*Options, error) { return &Options{Name: name}, nil }
+
+func (o *Options) Size() int { return 0 }
+
+func Reset() {}
+
+const Version = "2"
+`,
+		"internal/x/x.go": "package x\n",
+	})
+
+	res, err := runGoAPIDiffCheck(root, CheckDefinition{ID: "go-apidiff"}, GoAPIDiffConfig{Baseline: "HEAD"})
+	if err != nil {
+		t.Fatalf("apidiff: %v", err)
+	}
+	if res.Status != "fail" {
+		t.Fatalf("expected fail, got %s %s", res.Status, res.Signal)
+	}
+	var summaries []string
+	for _, issue := range res.Issues {
+		summaries = append(summaries, issue.Summary)
+	}
+	want := []string{
+		"example.com/app/lib.Close: func removed",
+		"example.com/app/lib.Open: signature changed from func(name string) (*example.com/app/lib.Options, error) to func(name string, verbose bool) (*example.com/app/lib.Options, error)",
+		"example.com/app/lib.Store: method Put added",
+	}
+	if strings.Join(summaries, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected issues:\n%s", strings.Join(summaries, "\n"))
+	}
+	open := res.Issues[1]
+	if open.Path != "lib/lib.go" || open.Line != 13 || open.ID != "api:example.com/app/lib.Open" {
+		t.Fatalf("unexpected issue location %+v", open)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".git", "worktrees")); err == nil {
+		entries, _ := os.ReadDir(filepath.Join(root, ".git", "worktrees"))
+		if len(entries) != 0 {
+			t.Fatalf("expected baseline worktree to be removed")
+		}
+	}
+}
+
+func TestGoAPIDiffPassesOnCompatibleChanges(t *testing.T) {
+	root := apiDiffRepo(t, map[string]string{
+		"go.mod":     "module example.com/app\n\ngo 1.21\n",
+		"lib/lib.go": apiDiffBase,
+	})
+	writeAPIDiffFiles(t, root, map[string]string{
+		"lib/extra.go": "package lib\n\nfunc Reset() {}\n",
+	})
+
+	res, err := runGoAPIDiffCheck(root, CheckDefinition{ID: "go-apidiff"}, GoAPIDiffConfig{Baseline: "HEAD"})
+	if err != nil {
+		t.Fatalf("apidiff: %v", err)
+	}
+	if res.Status != "pass" || res.Signal != "1 compatible API changes since HEAD" {
+		t.Fatalf("expected compatible pass, got %s %q", res.Status, res.Signal)
+	}
+}
+
+func TestGoAPIDiffAllowsBreakingChangesWithMajorVersion(t *testing.T) {
+	root := apiDiffRepo(t, map[string]string{
+		"go.mod":     "module example.com/app\n\ngo 1.21\n",
+		"lib/lib.go": apiDiffBase,
+	})
+	writeAPIDiffFiles(t, root, map[string]string{
+		"go.mod":     "module example.com/app/v2\n\ngo 1.21\n",
+		"lib/lib.go": "package lib\n\nfunc Open() {}\n",
+	})
+
+	res, err := runGoAPIDiffCheck(root, CheckDefinition{ID: "go-apidiff"}, GoAPIDiffConfig{Baseline: "HEAD"})
+	if err != nil {
+		t.Fatalf("apidiff: %v", err)
+	}
+	if res.Status != "pass" || !strings.Contains(res.Signal, "allowed by major version change") {
+		t.Fatalf("expected pass on major bump, got %s %q", res.Status, res.Signal)
+	}
+}
+
+func TestGoAPIDiffComparesNestedModule(t *testing.T) {
+	repo := apiDiffRepo(t, map[string]string{
+		"go.mod":             "module example.com/top\n\ngo 1.21\n",
+		"top.go":             "package top\n\nfunc Top() {}\n",
+		"svc/go.mod":         "module example.com/svc\n\ngo 1.21\n",
+		"svc/lib/lib.go":     apiDiffBase,
+		"svc/other/other.go": "package other\n\nfunc Keep() {}\n",
+	})
+	root := filepath.Join(repo, "svc")
+	writeAPIDiffFiles(t, root, map[string]string{
+		"lib/lib.go": strings.Replace(apiDiffBase, "func Close() {}\n", "", 1),
+	})
+
+	res, err := runGoAPIDiffCheck(root, CheckDefinition{ID: "go-apidiff"}, GoAPIDiffConfig{Baseline: "HEAD"})
+	if err != nil {
+		t.Fatalf("apidiff: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 1 || res.Issues[0].Summary != "example.com/svc/lib.Close: func removed" {
+		t.Fatalf("expected only the nested module's removed func, got %s %q %+v", res.Status, res.Signal, res.Issues)
+	}
+
+	added := filepath.Join(repo, "added")
+	writeAPIDiffFiles(t, added, map[string]string{"go.mod": "module example.com/added\n\ngo 1.21\n"})
+	res, err = runGoAPIDiffCheck(added, CheckDefinition{ID: "go-apidiff"}, GoAPIDiffConfig{Baseline: "HEAD"})
+	if err != nil {
+		t.Fatalf("apidiff: %v", err)
+	}
+	if res.Status != "skip" || !strings.Contains(res.Detail, "does not exist at HEAD") {
+		t.Fatalf("expected skip for a module added since the baseline, got %s %q", res.Status, res.Detail)
+	}
+}
+
+func TestGoAPIDiffSkipsWithoutBaseline(t *testing.T) {
+	root := apiDiffRepo(t, map[string]string{
+		"go.mod":     "module example.com/app\n\ngo 1.21\n",
+		"lib/lib.go": apiDiffBase,
+	})
+	res, err := runGoAPIDiffCheck(root, CheckDefinition{ID: "go-apidiff"}, GoAPIDiffConfig{})
+	if err != nil {
+		t.Fatalf("apidiff: %v", err)
+	}
+	if res.Status != "skip" {
+		t.Fatalf("expected skip without HEAD~1, got %s %q", res.Status, res.Signal)
+	}
+}
+
+func TestMajorVersion(t *testing.T) {
+	cases := map[string]string{
+		"example.com/app":     "1",
+		"example.com/app/v2":  "2",
+		"example.com/app/v10": "10",
+		"gopkg.in/yaml.v3":    "1",
+	}
+	for path, want := range cases {
+		if got := majorVersion(path); got != want {
+			t.Fatalf("majorVersion(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
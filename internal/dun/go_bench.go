@@ -0,0 +1,406 @@
+package dun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultBenchCount     = 5
+	defaultBenchTolerance = 10.0
+	benchBaselineDir      = ".dun/bench"
+)
+
+var benchProcsSuffix = regexp.MustCompile(`-\d+$`)
+
+// benchSamples holds every run of one benchmark, keyed by unit.
+type benchSamples struct {
+	NsPerOp     []float64 `json:"ns_per_op"`
+	AllocsPerOp []float64 `json:"allocs_per_op,omitempty"`
+}
+
+type benchBaseline struct {
+	Benchmarks map[string]benchSamples `json:"benchmarks"`
+}
+
+// BenchBaselineUpdate records a baseline written by UpdateBenchBaselines.
+type BenchBaselineUpdate struct {
+	CheckID    string
+	Path       string
+	Benchmarks int
+}
+
+type benchRegression struct {
+	Name     string
+	Package  string
+	Unit     string
+	Baseline float64
+	Current  float64
+	Delta    float64
+}
+
+func runGoBenchCheck(root string, def CheckDefinition, config GoBenchConfig) (CheckResult, error) {
+	baselinePath := benchBaselinePath(root, localCheckID(def.ID, def.Scope), config)
+	baseline, err := readBenchBaseline(baselinePath)
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	current, output, err := runGoBench(root, config)
+	if err != nil {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "fail",
+			Signal: "go test -bench failed",
+			Detail: trimOutput(output),
+			Next:   "go test " + strings.Join(goBenchArgs(config)[1:], " "),
+		}, nil
+	}
+	if len(current) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "warn",
+			Signal: "no benchmarks matched",
+			Detail: fmt.Sprintf("pattern %q matched no benchmarks", benchPattern(config)),
+		}, nil
+	}
+	if baseline == nil {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "warn",
+			Signal: "no benchmark baseline",
+			Detail: fmt.Sprintf("%s not found; %d benchmarks measured", benchDisplayPath(root, baselinePath), len(current)),
+			Next:   "dun bench --update-baseline",
+		}, nil
+	}
+
+	regressions, added := compareBenchmarks(baseline.Benchmarks, current, benchTolerance(config))
+	if len(regressions) == 0 {
+		signal := fmt.Sprintf("%d benchmarks within %.0f%% of baseline", len(current)-len(added), benchTolerance(config))
+		if len(added) > 0 {
+			signal = fmt.Sprintf("%s, %d new", signal, len(added))
+		}
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: signal,
+		}, nil
+	}
+
+	var issues []Issue
+	var lines []string
+	for _, reg := range regressions {
+		summary := fmt.Sprintf("%s %s +%.1f%% (%s -> %s)", reg.Name, reg.Unit, reg.Delta, formatBenchValue(reg.Baseline), formatBenchValue(reg.Current))
+		issues = append(issues, Issue{
+			ID:       "bench:" + reg.Package + "." + reg.Name + ":" + reg.Unit,
+			Summary:  summary,
+			Path:     reg.Package,
+			Severity: "fail",
+		})
+		lines = append(lines, summary)
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("%d benchmark regressions", len(regressions)),
+		Detail: strings.Join(lines, "\n"),
+		Next:   "dun bench --update-baseline (if the slowdown is intended)",
+		Issues: issues,
+	}, nil
+}
+
+// UpdateBenchBaselines runs every go-bench check CheckRepo would plan with
+// opts, in the repo and its sub-projects, and writes the measured numbers as
+// the new baselines. Check conditions are ignored because they usually
+// require the baseline this creates.
+func UpdateBenchBaselines(root string, opts Options) ([]BenchBaselineUpdate, error) {
+	defer closeWasmModules()
+	plan, err := buildPlanForRoot(root, opts, false)
+	if err != nil {
+		return nil, err
+	}
+	var updates []BenchBaselineUpdate
+	for _, pc := range plan {
+		if pc.Check.Type != "go-bench" {
+			continue
+		}
+		update, err := updateBenchBaseline(root, pc)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, update)
+	}
+	return updates, nil
+}
+
+func updateBenchBaseline(root string, pc plannedCheck) (BenchBaselineUpdate, error) {
+	handler, ok := LookupCheckType(pc.Check.Type)
+	if !ok {
+		return BenchBaselineUpdate{}, fmt.Errorf("unknown check type: %s", pc.Check.Type)
+	}
+	cfg, err := handler.Decode(pc.Check)
+	if err != nil {
+		return BenchBaselineUpdate{}, err
+	}
+	config, ok := cfg.(GoBenchConfig)
+	if !ok {
+		return BenchBaselineUpdate{}, fmt.Errorf("go-bench config missing")
+	}
+	dir := filepath.Join(root, filepath.FromSlash(pc.Scope))
+	current, output, err := runGoBench(dir, config)
+	if err != nil {
+		return BenchBaselineUpdate{}, fmt.Errorf("%s: go test -bench: %w\n%s", pc.Check.ID, err, trimOutput(output))
+	}
+	path := benchBaselinePath(dir, localCheckID(pc.Check.ID, pc.Scope), config)
+	if err := writeBenchBaseline(path, benchBaseline{Benchmarks: current}); err != nil {
+		return BenchBaselineUpdate{}, err
+	}
+	return BenchBaselineUpdate{
+		CheckID:    pc.Check.ID,
+		Path:       benchDisplayPath(root, path),
+		Benchmarks: len(current),
+	}, nil
+}
+
+func runGoBench(root string, config GoBenchConfig) (map[string]benchSamples, []byte, error) {
+	timeout := commandTimeout(CommandConfig{Timeout: config.Timeout})
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", goBenchArgs(config)...)
+	cmd.Dir = root
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, output, err
+	}
+	return parseBenchOutput(output), output, nil
+}
+
+func goBenchArgs(config GoBenchConfig) []string {
+	count := config.Count
+	if count <= 0 {
+		count = defaultBenchCount
+	}
+	args := []string{"test", "-run", "^$", "-bench", benchPattern(config), "-benchmem", "-count", strconv.Itoa(count)}
+	if len(config.Packages) == 0 {
+		return append(args, "./...")
+	}
+	return append(args, config.Packages...)
+}
+
+func benchPattern(config GoBenchConfig) string {
+	if config.Pattern == "" {
+		return "."
+	}
+	return config.Pattern
+}
+
+func benchTolerance(config GoBenchConfig) float64 {
+	if config.Tolerance <= 0 {
+		return defaultBenchTolerance
+	}
+	return config.Tolerance
+}
+
+func benchBaselinePath(root string, checkID string, config GoBenchConfig) string {
+	if config.BaselineFile != "" {
+		return filepath.Join(root, config.BaselineFile)
+	}
+	return filepath.Join(root, benchBaselineDir, checkID+".json")
+}
+
+// parseBenchOutput collects ns/op and allocs/op samples from benchmark lines,
+// keyed by "<package>.<name>" with the GOMAXPROCS suffix removed so baselines
+// stay comparable across machines.
+func parseBenchOutput(output []byte) map[string]benchSamples {
+	results := map[string]benchSamples{}
+	pkg := ""
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "pkg: ") {
+			pkg = strings.TrimSpace(strings.TrimPrefix(line, "pkg: "))
+			continue
+		}
+		if !strings.HasPrefix(line, "Benchmark") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+		name := benchProcsSuffix.ReplaceAllString(fields[0], "")
+		key := name
+		if pkg != "" {
+			key = pkg + "." + name
+		}
+		samples := results[key]
+		found := false
+		for i := 2; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				continue
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				samples.NsPerOp = append(samples.NsPerOp, value)
+				found = true
+			case "allocs/op":
+				samples.AllocsPerOp = append(samples.AllocsPerOp, value)
+			}
+		}
+		if found {
+			results[key] = samples
+		}
+	}
+	return results
+}
+
+// compareBenchmarks reports a regression when the median grew by more than
+// tolerance percent and the current samples are all slower than every
+// baseline sample, so a single noisy run does not fail the check.
+func compareBenchmarks(baseline map[string]benchSamples, current map[string]benchSamples, tolerance float64) ([]benchRegression, []string) {
+	var regressions []benchRegression
+	var added []string
+	for _, key := range sortedBenchKeys(current) {
+		base, ok := baseline[key]
+		if !ok {
+			added = append(added, key)
+			continue
+		}
+		cur := current[key]
+		pkg, name := splitBenchKey(key)
+		for _, unit := range []struct {
+			name string
+			base []float64
+			cur  []float64
+		}{
+			{"ns/op", base.NsPerOp, cur.NsPerOp},
+			{"allocs/op", base.AllocsPerOp, cur.AllocsPerOp},
+		} {
+			if len(unit.base) == 0 || len(unit.cur) == 0 {
+				continue
+			}
+			baseMedian := median(unit.base)
+			curMedian := median(unit.cur)
+			delta := percentChange(baseMedian, curMedian)
+			if delta <= tolerance || minFloat(unit.cur) <= maxFloat(unit.base) {
+				continue
+			}
+			regressions = append(regressions, benchRegression{
+				Name:     name,
+				Package:  pkg,
+				Unit:     unit.name,
+				Baseline: baseMedian,
+				Current:  curMedian,
+				Delta:    delta,
+			})
+		}
+	}
+	return regressions, added
+}
+
+func splitBenchKey(key string) (string, string) {
+	idx := strings.LastIndex(key, ".Benchmark")
+	if idx < 0 {
+		return "", key
+	}
+	return key[:idx], key[idx+1:]
+}
+
+func percentChange(base float64, cur float64) float64 {
+	if base == 0 {
+		if cur == 0 {
+			return 0
+		}
+		return 100
+	}
+	return (cur - base) / base * 100
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func minFloat(values []float64) float64 {
+	out := values[0]
+	for _, v := range values[1:] {
+		if v < out {
+			out = v
+		}
+	}
+	return out
+}
+
+func maxFloat(values []float64) float64 {
+	out := values[0]
+	for _, v := range values[1:] {
+		if v > out {
+			out = v
+		}
+	}
+	return out
+}
+
+func formatBenchValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func sortedBenchKeys(values map[string]benchSamples) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func readBenchBaseline(path string) (*benchBaseline, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var baseline benchBaseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("parse bench baseline %s: %w", path, err)
+	}
+	return &baseline, nil
+}
+
+func writeBenchBaseline(path string, baseline benchBaseline) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func benchDisplayPath(root string, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
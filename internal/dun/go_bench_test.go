@@ -0,0 +1,245 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const benchOutputFixture = `goos: linux
+goarch: amd64
+pkg: example.com/app
+cpu: Test CPU
+BenchmarkParse-8   	  100000	      1000 ns/op	     128 B/op	       2 allocs/op
+BenchmarkParse-8   	  100000	      1100 ns/op	     128 B/op	       2 allocs/op
+BenchmarkEncode/small-8         	  500000	       300 ns/op
+PASS
+ok  	example.com/app	1.234s
+`
+
+func TestParseBenchOutput(t *testing.T) {
+	results := parseBenchOutput([]byte(benchOutputFixture))
+	parse, ok := results["example.com/app.BenchmarkParse"]
+	if !ok {
+		t.Fatalf("expected BenchmarkParse without procs suffix, got %+v", results)
+	}
+	if len(parse.NsPerOp) != 2 || parse.NsPerOp[1] != 1100 {
+		t.Fatalf("unexpected ns/op samples %+v", parse.NsPerOp)
+	}
+	if len(parse.AllocsPerOp) != 2 || parse.AllocsPerOp[0] != 2 {
+		t.Fatalf("unexpected allocs/op samples %+v", parse.AllocsPerOp)
+	}
+	if _, ok := results["example.com/app.BenchmarkEncode/small"]; !ok {
+		t.Fatalf("expected sub-benchmark, got %+v", results)
+	}
+}
+
+func TestCompareBenchmarksRequiresSignificantChange(t *testing.T) {
+	baseline := map[string]benchSamples{
+		"example.com/app.BenchmarkA": {NsPerOp: []float64{100, 105, 110}, AllocsPerOp: []float64{2, 2, 2}},
+		"example.com/app.BenchmarkB": {NsPerOp: []float64{100, 150}},
+	}
+	current := map[string]benchSamples{
+		// Median +30% with no overlap: regression.
+		"example.com/app.BenchmarkA": {NsPerOp: []float64{130, 136, 140}, AllocsPerOp: []float64{2, 2, 2}},
+		// Median +25% but overlapping samples: noise.
+		"example.com/app.BenchmarkB": {NsPerOp: []float64{120, 190}},
+		"example.com/app.BenchmarkC": {NsPerOp: []float64{10}},
+	}
+	regressions, added := compareBenchmarks(baseline, current, 10)
+	if len(regressions) != 1 {
+		t.Fatalf("expected 1 regression, got %+v", regressions)
+	}
+	reg := regressions[0]
+	if reg.Package != "example.com/app" || reg.Name != "BenchmarkA" || reg.Unit != "ns/op" {
+		t.Fatalf("unexpected regression %+v", reg)
+	}
+	if len(added) != 1 || added[0] != "example.com/app.BenchmarkC" {
+		t.Fatalf("expected BenchmarkC as new, got %v", added)
+	}
+}
+
+func TestGoBenchCheckWarnsWithoutBaseline(t *testing.T) {
+	binDir, _ := stubGoBench(t, benchOutputFixture)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	res, err := runGoBenchCheck(t.TempDir(), CheckDefinition{ID: "go-bench"}, GoBenchConfig{})
+	if err != nil {
+		t.Fatalf("bench check: %v", err)
+	}
+	if res.Status != "warn" || res.Next != "dun bench --update-baseline" {
+		t.Fatalf("expected warn with update hint, got %s %q", res.Status, res.Next)
+	}
+	if !strings.Contains(res.Detail, ".dun/bench/go-bench.json") {
+		t.Fatalf("expected baseline path in detail, got %q", res.Detail)
+	}
+}
+
+func TestGoBenchCheckSubprojectBaselinePath(t *testing.T) {
+	binDir, _ := stubGoBench(t, benchOutputFixture)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	def := CheckDefinition{ID: "services/api:go-bench", Scope: "services/api"}
+	res, err := runGoBenchCheck(t.TempDir(), def, GoBenchConfig{})
+	if err != nil {
+		t.Fatalf("bench check: %v", err)
+	}
+	if !strings.Contains(res.Detail, ".dun/bench/go-bench.json not found") {
+		t.Fatalf("expected un-namespaced baseline path, got %q", res.Detail)
+	}
+}
+
+func TestGoBenchCheckReportsRegressions(t *testing.T) {
+	binDir, argsFile := stubGoBench(t, benchOutputFixture)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".dun", "bench"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, filepath.Join(root, ".dun", "bench", "go-bench.json"), `{"benchmarks": {
+  "example.com/app.BenchmarkParse": {"ns_per_op": [500, 510], "allocs_per_op": [2, 2]},
+  "example.com/app.BenchmarkEncode/small": {"ns_per_op": [300]}
+}}`)
+
+	config := GoBenchConfig{Pattern: "Parse|Encode", Count: 2, Packages: []string{"./internal/..."}}
+	res, err := runGoBenchCheck(root, CheckDefinition{ID: "go-bench"}, config)
+	if err != nil {
+		t.Fatalf("bench check: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 1 {
+		t.Fatalf("expected 1 regression, got %s %+v", res.Status, res.Issues)
+	}
+	issue := res.Issues[0]
+	if issue.ID != "bench:example.com/app.BenchmarkParse:ns/op" || issue.Path != "example.com/app" {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+	if !strings.Contains(issue.Summary, "(505 -> 1050)") {
+		t.Fatalf("expected medians in summary, got %q", issue.Summary)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("read args: %v", err)
+	}
+	if strings.TrimSpace(string(args)) != "test -run ^$ -bench Parse|Encode -benchmem -count 2 ./internal/..." {
+		t.Fatalf("unexpected args %q", string(args))
+	}
+}
+
+func TestGoBenchCheckPassesWithinTolerance(t *testing.T) {
+	binDir, _ := stubGoBench(t, benchOutputFixture)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "bench.json"), `{"benchmarks": {
+  "example.com/app.BenchmarkParse": {"ns_per_op": [1000, 1000]}
+}}`)
+
+	res, err := runGoBenchCheck(root, CheckDefinition{ID: "go-bench"}, GoBenchConfig{BaselineFile: "bench.json", Tolerance: 20})
+	if err != nil {
+		t.Fatalf("bench check: %v", err)
+	}
+	if res.Status != "pass" {
+		t.Fatalf("expected pass, got %s %+v", res.Status, res.Issues)
+	}
+	if !strings.Contains(res.Signal, "1 new") {
+		t.Fatalf("expected new benchmark count, got %q", res.Signal)
+	}
+}
+
+func TestGoBenchCheckFailsWhenBenchmarksFail(t *testing.T) {
+	binDir, _ := stubGoBench(t, "--- FAIL: BenchmarkParse\n")
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("DUN_BENCH_EXIT", "1")
+
+	res, err := runGoBenchCheck(t.TempDir(), CheckDefinition{ID: "go-bench"}, GoBenchConfig{})
+	if err != nil {
+		t.Fatalf("bench check: %v", err)
+	}
+	if res.Status != "fail" || !strings.Contains(res.Next, "-bench . -benchmem -count 5 ./...") {
+		t.Fatalf("expected failure with rerun command, got %s %q", res.Status, res.Next)
+	}
+}
+
+func TestWriteAndReadBenchBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".dun", "bench", "go-bench.json")
+	want := benchBaseline{Benchmarks: map[string]benchSamples{
+		"example.com/app.BenchmarkParse": {NsPerOp: []float64{1, 2}},
+	}}
+	if err := writeBenchBaseline(path, want); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+	got, err := readBenchBaseline(path)
+	if err != nil {
+		t.Fatalf("read baseline: %v", err)
+	}
+	if got == nil || len(got.Benchmarks["example.com/app.BenchmarkParse"].NsPerOp) != 2 {
+		t.Fatalf("unexpected baseline %+v", got)
+	}
+}
+
+func TestUpdateBenchBaselinesWritesBaseline(t *testing.T) {
+	binDir, _ := stubGoBench(t, benchOutputFixture)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/app\n")
+
+	updates, err := UpdateBenchBaselines(root, DefaultOptions())
+	if err != nil {
+		t.Fatalf("update baselines: %v", err)
+	}
+	if len(updates) != 1 || updates[0].Path != ".dun/bench/go-bench.json" || updates[0].Benchmarks != 2 {
+		t.Fatalf("unexpected updates %+v", updates)
+	}
+	baseline, err := readBenchBaseline(filepath.Join(root, ".dun", "bench", "go-bench.json"))
+	if err != nil || baseline == nil {
+		t.Fatalf("expected baseline written: %v", err)
+	}
+}
+
+func TestUpdateBenchBaselinesCoversSubprojects(t *testing.T) {
+	binDir, _ := stubGoBench(t, benchOutputFixture)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	root := t.TempDir()
+	writeAPIDiffFiles(t, root, map[string]string{
+		"go.mod":              "module example.com/app\n",
+		"services/api/go.mod": "module example.com/api\n",
+	})
+
+	updates, err := UpdateBenchBaselines(root, DefaultOptions())
+	if err != nil {
+		t.Fatalf("update baselines: %v", err)
+	}
+	var got []string
+	for _, update := range updates {
+		got = append(got, update.CheckID+" "+update.Path)
+	}
+	sort.Strings(got)
+	want := []string{
+		"go-bench .dun/bench/go-bench.json",
+		"services/api:go-bench services/api/.dun/bench/go-bench.json",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func stubGoBench(t *testing.T, output string) (string, string) {
+	t.Helper()
+	binDir := t.TempDir()
+	outputPath := filepath.Join(binDir, "bench.txt")
+	argsPath := filepath.Join(binDir, "args")
+	writeFile(t, outputPath, output)
+	script := `#!/bin/sh
+echo "$@" >> "` + argsPath + `"
+cat "` + outputPath + `"
+exit ${DUN_BENCH_EXIT:-0}
+`
+	goPath := filepath.Join(binDir, "go")
+	writeFile(t, goPath, script)
+	if err := os.Chmod(goPath, 0755); err != nil {
+		t.Fatalf("chmod go: %v", err)
+	}
+	return binDir, argsPath
+}
@@ -0,0 +1,169 @@
+package dun
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var defaultBuildTargets = []BuildTarget{
+	{GOOS: "linux", GOARCH: "amd64"},
+	{GOOS: "darwin", GOARCH: "arm64"},
+	{GOOS: "windows", GOARCH: "amd64"},
+}
+
+var goCompileErrorLine = regexp.MustCompile(`^(?:vet: )?(?:\./)?(\S+\.go):(\d+)(?::\d+)?: (.+)$`)
+
+type buildTargetResult struct {
+	Target BuildTarget
+	Output []byte
+	Err    error
+}
+
+func runGoBuildMatrixCheck(root string, def CheckDefinition, config GoBuildMatrixConfig) (CheckResult, error) {
+	targets := config.Targets
+	if len(targets) == 0 {
+		targets = defaultBuildTargets
+	}
+	command := config.GoCommand
+	if command == "" {
+		command = "build"
+	}
+
+	results := runBuildTargets(root, command, targets, config)
+
+	var issues []Issue
+	var failed []string
+	for _, res := range results {
+		if res.Err == nil {
+			continue
+		}
+		name := buildTargetName(res.Target)
+		failed = append(failed, name)
+		issues = append(issues, buildTargetIssues(name, command, res)...)
+	}
+
+	if len(failed) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: fmt.Sprintf("go %s passed for %d targets", command, len(targets)),
+		}, nil
+	}
+	first := results[0].Target
+	for _, res := range results {
+		if res.Err != nil {
+			first = res.Target
+			break
+		}
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("go %s failed for %s", command, strings.Join(failed, ", ")),
+		Detail: issues[0].Summary,
+		Next:   buildTargetCommandLine(command, first),
+		Issues: issues,
+	}, nil
+}
+
+// runBuildTargets builds every target concurrently, bounded by the CPU count,
+// and returns the results in target order.
+func runBuildTargets(root string, command string, targets []BuildTarget, config GoBuildMatrixConfig) []buildTargetResult {
+	results := make([]buildTargetResult, len(targets))
+	limit := runtime.NumCPU()
+	if limit > len(targets) {
+		limit = len(targets)
+	}
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target BuildTarget) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			output, err := runBuildTarget(root, command, target, config)
+			results[i] = buildTargetResult{Target: target, Output: output, Err: err}
+		}(i, target)
+	}
+	wg.Wait()
+	return results
+}
+
+func runBuildTarget(root string, command string, target BuildTarget, config GoBuildMatrixConfig) ([]byte, error) {
+	timeout := commandTimeout(CommandConfig{Timeout: config.Timeout})
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", buildTargetArgs(command, target)...)
+	cmd.Dir = root
+	cmd.Env = append(buildCommandEnv(CommandConfig{Env: config.Env}),
+		"CGO_ENABLED=0",
+		"GOOS="+target.GOOS,
+		"GOARCH="+target.GOARCH,
+	)
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return output, fmt.Errorf("timed out after %s", timeout)
+	}
+	return output, err
+}
+
+func buildTargetArgs(command string, target BuildTarget) []string {
+	args := []string{command}
+	if len(target.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(target.Tags, ","))
+	}
+	return append(args, "./...")
+}
+
+func buildTargetName(target BuildTarget) string {
+	name := target.GOOS + "/" + target.GOARCH
+	if len(target.Tags) > 0 {
+		name += "+" + strings.Join(target.Tags, ",")
+	}
+	return name
+}
+
+func buildTargetCommandLine(command string, target BuildTarget) string {
+	return fmt.Sprintf("CGO_ENABLED=0 GOOS=%s GOARCH=%s go %s", target.GOOS, target.GOARCH, strings.Join(buildTargetArgs(command, target), " "))
+}
+
+// buildTargetIssues turns compiler diagnostics into one issue per location;
+// a failure without diagnostics becomes a single issue for the target.
+func buildTargetIssues(name string, command string, res buildTargetResult) []Issue {
+	var issues []Issue
+	for _, line := range strings.Split(string(res.Output), "\n") {
+		match := goCompileErrorLine.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		lineNo, _ := strconv.Atoi(match[2])
+		issues = append(issues, Issue{
+			ID:       fmt.Sprintf("build:%s:%s:%d", name, match[1], lineNo),
+			Summary:  fmt.Sprintf("%s: %s", name, match[3]),
+			Path:     match[1],
+			Line:     lineNo,
+			Severity: "fail",
+		})
+	}
+	if len(issues) > 0 {
+		return issues
+	}
+	detail := trimOutput(res.Output)
+	if detail == "" {
+		detail = res.Err.Error()
+	}
+	return []Issue{{
+		ID:       "build:" + name,
+		Summary:  fmt.Sprintf("%s: go %s failed", name, command),
+		Severity: "fail",
+		Detail:   detail,
+	}}
+}
@@ -0,0 +1,106 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func matrixModule(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/app\n\ngo 1.21\n")
+	writeFile(t, filepath.Join(root, "app.go"), "package app\n\nfunc Run() { platformRun() }\n")
+	writeFile(t, filepath.Join(root, "app_unix.go"), "//go:build !windows\n\npackage app\n\nimport \"syscall\"\n\nfunc platformRun() { _ = syscall.Getpgrp() }\n")
+	writeFile(t, filepath.Join(root, "app_windows.go"), "package app\n\nfunc platformRun() { undefinedHelper() }\n")
+	return root
+}
+
+func TestGoBuildMatrixReportsPerTargetFailures(t *testing.T) {
+	root := matrixModule(t)
+	config := GoBuildMatrixConfig{Targets: []BuildTarget{
+		{GOOS: "linux", GOARCH: "amd64"},
+		{GOOS: "windows", GOARCH: "amd64"},
+		{GOOS: "darwin", GOARCH: "arm64"},
+	}}
+	res, err := runGoBuildMatrixCheck(root, CheckDefinition{ID: "go-build-matrix"}, config)
+	if err != nil {
+		t.Fatalf("build matrix: %v", err)
+	}
+	if res.Status != "fail" || res.Signal != "go build failed for windows/amd64" {
+		t.Fatalf("expected windows failure, got %s %q", res.Status, res.Signal)
+	}
+	if len(res.Issues) != 1 {
+		t.Fatalf("expected one issue, got %+v", res.Issues)
+	}
+	issue := res.Issues[0]
+	if issue.Path != "app_windows.go" || issue.Line != 3 || !strings.HasPrefix(issue.Summary, "windows/amd64: undefined: undefinedHelper") {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+	if res.Next != "CGO_ENABLED=0 GOOS=windows GOARCH=amd64 go build ./..." {
+		t.Fatalf("unexpected next %q", res.Next)
+	}
+}
+
+func TestGoBuildMatrixPassesWithTags(t *testing.T) {
+	root := matrixModule(t)
+	writeFile(t, filepath.Join(root, "app_windows.go"), "//go:build !nowindows\n\npackage app\n\nfunc platformRun() {}\n")
+	config := GoBuildMatrixConfig{GoCommand: "vet", Targets: []BuildTarget{
+		{GOOS: "windows", GOARCH: "amd64", Tags: []string{"nowindows", "extra"}},
+	}}
+	// With the tag set the Windows file is excluded and platformRun is undefined.
+	res, err := runGoBuildMatrixCheck(root, CheckDefinition{ID: "go-build-matrix"}, config)
+	if err != nil {
+		t.Fatalf("build matrix: %v", err)
+	}
+	if res.Status != "fail" || res.Issues[0].ID != "build:windows/amd64+nowindows,extra:app.go:3" {
+		t.Fatalf("expected tagged failure, got %s %+v", res.Status, res.Issues)
+	}
+
+	config.Targets[0].Tags = nil
+	res, err = runGoBuildMatrixCheck(root, CheckDefinition{ID: "go-build-matrix"}, config)
+	if err != nil {
+		t.Fatalf("build matrix: %v", err)
+	}
+	if res.Status != "pass" || res.Signal != "go vet passed for 1 targets" {
+		t.Fatalf("expected pass, got %s %q %+v", res.Status, res.Signal, res.Issues)
+	}
+}
+
+func TestGoBuildMatrixDisablesCgo(t *testing.T) {
+	binDir := t.TempDir()
+	envFile := filepath.Join(binDir, "env.txt")
+	script := "#!/bin/sh\necho \"$CGO_ENABLED $GOOS $GOARCH $*\" >> " + envFile + "\necho 'something broke' >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(binDir, "go"), []byte(script), 0755); err != nil {
+		t.Fatalf("write stub: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	res, err := runGoBuildMatrixCheck(t.TempDir(), CheckDefinition{ID: "go-build-matrix"}, GoBuildMatrixConfig{
+		Targets: []BuildTarget{{GOOS: "freebsd", GOARCH: "arm64"}},
+	})
+	if err != nil {
+		t.Fatalf("build matrix: %v", err)
+	}
+	env, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatalf("read env: %v", err)
+	}
+	if string(env) != "0 freebsd arm64 build ./...\n" {
+		t.Fatalf("unexpected env %q", string(env))
+	}
+	if len(res.Issues) != 1 || res.Issues[0].ID != "build:freebsd/arm64" || res.Issues[0].Detail != "something broke" {
+		t.Fatalf("unexpected issues %+v", res.Issues)
+	}
+}
+
+func TestGoBuildMatrixRejectsUnknownCommand(t *testing.T) {
+	handler, ok := LookupCheckType("go-build-matrix")
+	if !ok {
+		t.Fatalf("go-build-matrix not registered")
+	}
+	if _, err := handler.Decode(Check{GoCommand: "test"}); err == nil {
+		t.Fatalf("expected error for go_command test")
+	}
+}
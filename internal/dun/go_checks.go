@@ -15,22 +15,34 @@ var closeCoverageFile = func(f *os.File) error {
 	return f.Close()
 }
 
-func runGoTestCheck(root string, def CheckDefinition) (CheckResult, error) {
-	output, err := runGoCommand(root, "test", "./...")
-	if err != nil {
+func runGoTestCheck(root string, def CheckDefinition, config GoTestConfig) (CheckResult, error) {
+	args := append([]string{"test", "-json"}, goTestFlags(config)...)
+	args = append(args, goPackageArgs(config.Packages)...)
+	output, err := runGoCommand(root, args...)
+	if err == nil {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: "go test passed",
+		}, nil
+	}
+
+	run := parseGoTestEvents(output)
+	if len(run.Failed) == 0 {
 		return CheckResult{
 			ID:     def.ID,
 			Status: "fail",
 			Signal: "go test failed",
-			Detail: trimOutput(output),
-			Next:   "go test ./...",
+			Detail: trimOutput([]byte(run.Text())),
+			Next:   "go test " + strings.Join(goPackageArgs(config.Packages), " "),
 		}, nil
 	}
-	return CheckResult{
-		ID:     def.ID,
-		Status: "pass",
-		Signal: "go test passed",
-	}, nil
+
+	failures := run.Failed
+	if config.FlakeRetries > 0 {
+		failures = classifyFlakyTests(root, config, failures)
+	}
+	return goTestFailureResult(def, failures), nil
 }
 
 func runGoCoverageCheck(root string, def CheckDefinition, config GoCoverageConfig, opts Options) (CheckResult, error) {
@@ -40,7 +52,8 @@ func runGoCoverageCheck(root string, def CheckDefinition, config GoCoverageConfi
 	}
 	defer os.Remove(coveragePath)
 
-	output, err := runGoCommand(root, "test", "./...", "-coverprofile", coveragePath)
+	args := append([]string{"test"}, goPackageArgs(config.Packages)...)
+	output, err := runGoCommand(root, append(args, "-coverprofile", coveragePath)...)
 	if err != nil {
 		return CheckResult{
 			ID:     def.ID,
@@ -91,15 +104,16 @@ func runGoCoverageCheck(root string, def CheckDefinition, config GoCoverageConfi
 	}, nil
 }
 
-func runGoVetCheck(root string, def CheckDefinition) (CheckResult, error) {
-	output, err := runGoCommand(root, "vet", "./...")
+func runGoVetCheck(root string, def CheckDefinition, config GoVetConfig) (CheckResult, error) {
+	packages := goPackageArgs(config.Packages)
+	output, err := runGoCommand(root, append([]string{"vet"}, packages...)...)
 	if err != nil {
 		return CheckResult{
 			ID:     def.ID,
 			Status: "fail",
 			Signal: "go vet failed",
 			Detail: trimOutput(output),
-			Next:   "go vet ./...",
+			Next:   "go vet " + strings.Join(packages, " "),
 		}, nil
 	}
 	return CheckResult{
@@ -12,7 +12,7 @@ func TestGoTestCheckPasses(t *testing.T) {
 	t.Setenv("PATH", binDir)
 
 	root := t.TempDir()
-	res, err := runGoTestCheck(root, CheckDefinition{ID: "go-test"})
+	res, err := runGoTestCheck(root, CheckDefinition{ID: "go-test"}, GoTestConfig{})
 	if err != nil {
 		t.Fatalf("go test check: %v", err)
 	}
@@ -27,7 +27,7 @@ func TestGoTestCheckFails(t *testing.T) {
 	t.Setenv("DUN_GO_TEST_EXIT", "1")
 
 	root := t.TempDir()
-	res, err := runGoTestCheck(root, CheckDefinition{ID: "go-test"})
+	res, err := runGoTestCheck(root, CheckDefinition{ID: "go-test"}, GoTestConfig{})
 	if err != nil {
 		t.Fatalf("go test check: %v", err)
 	}
@@ -88,7 +88,7 @@ func TestGoVetCheckFails(t *testing.T) {
 	t.Setenv("DUN_GO_VET_EXIT", "1")
 
 	root := t.TempDir()
-	res, err := runGoVetCheck(root, CheckDefinition{ID: "go-vet"})
+	res, err := runGoVetCheck(root, CheckDefinition{ID: "go-vet"}, GoVetConfig{})
 	if err != nil {
 		t.Fatalf("go vet check: %v", err)
 	}
@@ -102,7 +102,7 @@ func TestGoVetCheckPasses(t *testing.T) {
 	t.Setenv("PATH", binDir)
 
 	root := t.TempDir()
-	res, err := runGoVetCheck(root, CheckDefinition{ID: "go-vet"})
+	res, err := runGoVetCheck(root, CheckDefinition{ID: "go-vet"}, GoVetConfig{})
 	if err != nil {
 		t.Fatalf("go vet check: %v", err)
 	}
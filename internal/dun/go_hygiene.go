@@ -0,0 +1,390 @@
+package dun
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const generatedMarker = "// Code generated "
+
+func runGoFmtCheck(root string, def CheckDefinition, config GoHygieneConfig) (CheckResult, error) {
+	tool := "gofmt"
+	if _, err := exec.LookPath("goimports"); err == nil {
+		tool = "goimports"
+	}
+
+	cmd := exec.Command(tool, "-l", ".")
+	cmd.Dir = root
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "fail",
+			Signal: tool + " failed",
+			Detail: trimOutput(output),
+			Next:   tool + " -l .",
+		}, nil
+	}
+
+	var files []string
+	for _, file := range filterGoSourcePaths(strings.Split(strings.TrimSpace(string(output)), "\n")) {
+		if !inSubproject(root, file) {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: "go files formatted",
+		}, nil
+	}
+
+	var issues []Issue
+	for _, file := range files {
+		issues = append(issues, Issue{
+			ID:      "gofmt:" + file,
+			Summary: "Not formatted with " + tool,
+			Path:    file,
+		})
+	}
+	next := config.Fix
+	if next == "" {
+		next = tool + " -w " + strings.Join(files, " ")
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("%d files need formatting", len(files)),
+		Next:   next,
+		Issues: issues,
+	}, nil
+}
+
+// filterGoSourcePaths drops empty entries and paths the go tool ignores
+// (vendor, testdata, and directories starting with "." or "_").
+func filterGoSourcePaths(paths []string) []string {
+	var out []string
+	for _, path := range paths {
+		path = filepath.ToSlash(strings.TrimSpace(path))
+		if path == "" || isIgnoredGoPath(path) {
+			continue
+		}
+		out = append(out, path)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func isIgnoredGoPath(path string) bool {
+	parts := strings.Split(path, "/")
+	for _, part := range parts[:len(parts)-1] {
+		if isIgnoredGoDir(part) {
+			return true
+		}
+	}
+	return false
+}
+
+func isIgnoredGoDir(name string) bool {
+	if name == "vendor" || name == "testdata" {
+		return true
+	}
+	if name == "." || name == ".." {
+		return false
+	}
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+func runGoModTidyCheck(root string, def CheckDefinition, config GoHygieneConfig) (CheckResult, error) {
+	next := config.Fix
+	if next == "" {
+		next = "go mod tidy"
+	}
+
+	tmpDir, err := os.MkdirTemp("", "dun-tidy-*")
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	modPath := filepath.Join(tmpDir, "go.mod")
+	sumPath := filepath.Join(tmpDir, "go.sum")
+	if err := copyFileIfExists(filepath.Join(root, "go.mod"), modPath); err != nil {
+		return CheckResult{}, err
+	}
+	if err := copyFileIfExists(filepath.Join(root, "go.sum"), sumPath); err != nil {
+		return CheckResult{}, err
+	}
+
+	// -modfile makes go read and write the temp copy (and its sibling go.sum)
+	// so the working tree is never modified.
+	output, err := runGoCommand(root, "mod", "tidy", "-modfile="+modPath)
+	if err != nil {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "fail",
+			Signal: "go mod tidy failed",
+			Detail: trimOutput(output),
+			Next:   next,
+		}, nil
+	}
+
+	var issues []Issue
+	var details []string
+	for _, pair := range [][2]string{{"go.mod", modPath}, {"go.sum", sumPath}} {
+		before, _ := os.ReadFile(filepath.Join(root, pair[0]))
+		after, _ := os.ReadFile(pair[1])
+		diff := lineDiff(string(before), string(after))
+		if len(diff) == 0 {
+			continue
+		}
+		detail := strings.Join(diff, "\n")
+		issues = append(issues, Issue{
+			ID:      "tidy:" + pair[0],
+			Summary: fmt.Sprintf("%s is not tidy (%d lines differ)", pair[0], len(diff)),
+			Path:    pair[0],
+			Detail:  detail,
+		})
+		details = append(details, detail)
+	}
+
+	if len(issues) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: "go.mod and go.sum are tidy",
+		}, nil
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: "go mod tidy drift",
+		Detail: trimOutput([]byte(strings.Join(details, "\n"))),
+		Next:   next,
+		Issues: issues,
+	}, nil
+}
+
+// lineDiff reports removed (-) and added (+) lines between two files. go.mod
+// and go.sum are kept in canonical order, so a set difference is enough.
+func lineDiff(before string, after string) []string {
+	beforeLines := strings.Split(strings.TrimSpace(before), "\n")
+	afterLines := strings.Split(strings.TrimSpace(after), "\n")
+	inBefore := make(map[string]bool, len(beforeLines))
+	for _, line := range beforeLines {
+		inBefore[line] = true
+	}
+	inAfter := make(map[string]bool, len(afterLines))
+	for _, line := range afterLines {
+		inAfter[line] = true
+	}
+
+	var diff []string
+	for _, line := range beforeLines {
+		if line != "" && !inAfter[line] {
+			diff = append(diff, "- "+line)
+		}
+	}
+	for _, line := range afterLines {
+		if line != "" && !inBefore[line] {
+			diff = append(diff, "+ "+line)
+		}
+	}
+	return diff
+}
+
+func runGoGenerateDriftCheck(root string, def CheckDefinition, config GoHygieneConfig) (CheckResult, error) {
+	next := config.Fix
+	if next == "" {
+		next = "go generate ./..."
+	}
+
+	tmpDir, err := os.MkdirTemp("", "dun-generate-*")
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := copyTree(root, tmpDir); err != nil {
+		return CheckResult{}, err
+	}
+	before, err := generatedFileContents(tmpDir)
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	output, err := runGoCommand(tmpDir, "generate", "./...")
+	if err != nil {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "fail",
+			Signal: "go generate failed",
+			Detail: trimOutput(output),
+			Next:   next,
+		}, nil
+	}
+
+	after, err := generatedFileContents(tmpDir)
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	var issues []Issue
+	for _, path := range sortedMapKeys(after) {
+		prev, ok := before[path]
+		switch {
+		case !ok:
+			issues = append(issues, Issue{
+				ID:      "generate:" + path,
+				Summary: "Generated file missing from tree",
+				Path:    path,
+			})
+		case prev != after[path]:
+			issues = append(issues, Issue{
+				ID:      "generate:" + path,
+				Summary: "Generated file differs from generator output",
+				Path:    path,
+			})
+		}
+	}
+	for _, path := range sortedMapKeys(before) {
+		if _, ok := after[path]; !ok {
+			issues = append(issues, Issue{
+				ID:      "generate:" + path,
+				Summary: "Generated file no longer produced by generator",
+				Path:    path,
+			})
+		}
+	}
+
+	if len(issues) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: "generated files up to date",
+		}, nil
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("%d generated files drifted", len(issues)),
+		Next:   next,
+		Issues: issues,
+	}, nil
+}
+
+// generatedFileContents returns the contents of every Go file carrying the
+// standard "Code generated ... DO NOT EDIT." header, keyed by relative path.
+func generatedFileContents(root string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && isIgnoredGoDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !isGeneratedGo(content) {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	return files, err
+}
+
+func isGeneratedGo(content []byte) bool {
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, []byte("package ")) {
+			return false
+		}
+		if bytes.HasPrefix(line, []byte(generatedMarker)) && bytes.HasSuffix(line, []byte(" DO NOT EDIT.")) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedMapKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// copyTree copies the repo at src into dst, skipping the .git directory.
+func copyTree(src string, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return copyFile(path, target)
+	})
+}
+
+func copyFileIfExists(src string, dst string) error {
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+	return copyFile(src, dst)
+}
+
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
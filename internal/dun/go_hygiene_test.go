@@ -0,0 +1,221 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoFmtCheckPassesWhenClean(t *testing.T) {
+	t.Setenv("PATH", stubHygieneBinaries(t))
+
+	res, err := runGoFmtCheck(t.TempDir(), CheckDefinition{ID: "go-fmt"}, GoHygieneConfig{})
+	if err != nil {
+		t.Fatalf("go fmt check: %v", err)
+	}
+	if res.Status != "pass" {
+		t.Fatalf("expected pass, got %s", res.Status)
+	}
+}
+
+func TestGoFmtCheckListsUnformattedFiles(t *testing.T) {
+	t.Setenv("PATH", stubHygieneBinaries(t))
+	t.Setenv("DUN_GOFMT_FILES", "b.go vendor/x/y.go a.go internal/testdata/z.go")
+
+	res, err := runGoFmtCheck(t.TempDir(), CheckDefinition{ID: "go-fmt"}, GoHygieneConfig{})
+	if err != nil {
+		t.Fatalf("go fmt check: %v", err)
+	}
+	if res.Status != "fail" {
+		t.Fatalf("expected fail, got %s", res.Status)
+	}
+	if len(res.Issues) != 2 || res.Issues[0].Path != "a.go" || res.Issues[1].Path != "b.go" {
+		t.Fatalf("expected a.go and b.go issues, got %+v", res.Issues)
+	}
+	if res.Next != "gofmt -w a.go b.go" {
+		t.Fatalf("unexpected fix command: %q", res.Next)
+	}
+}
+
+func TestGoFmtCheckUsesConfiguredFix(t *testing.T) {
+	t.Setenv("PATH", stubHygieneBinaries(t))
+	t.Setenv("DUN_GOFMT_FILES", "a.go")
+
+	res, err := runGoFmtCheck(t.TempDir(), CheckDefinition{ID: "go-fmt"}, GoHygieneConfig{Fix: "make fmt"})
+	if err != nil {
+		t.Fatalf("go fmt check: %v", err)
+	}
+	if res.Next != "make fmt" {
+		t.Fatalf("expected configured fix, got %q", res.Next)
+	}
+}
+
+func TestGoModTidyCheckPassesWhenTidy(t *testing.T) {
+	t.Setenv("PATH", stubHygieneBinaries(t))
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/app\n\ngo 1.22\n")
+
+	res, err := runGoModTidyCheck(root, CheckDefinition{ID: "go-mod-tidy"}, GoHygieneConfig{})
+	if err != nil {
+		t.Fatalf("tidy check: %v", err)
+	}
+	if res.Status != "pass" {
+		t.Fatalf("expected pass, got %s (%s)", res.Status, res.Detail)
+	}
+}
+
+func TestGoModTidyCheckReportsDriftWithoutTouchingTree(t *testing.T) {
+	t.Setenv("PATH", stubHygieneBinaries(t))
+	t.Setenv("DUN_TIDY_ADD", "require example.com/dep v1.0.0")
+	root := t.TempDir()
+	original := "module example.com/app\n\ngo 1.22\n"
+	writeFile(t, filepath.Join(root, "go.mod"), original)
+
+	res, err := runGoModTidyCheck(root, CheckDefinition{ID: "go-mod-tidy"}, GoHygieneConfig{})
+	if err != nil {
+		t.Fatalf("tidy check: %v", err)
+	}
+	if res.Status != "fail" || res.Next != "go mod tidy" {
+		t.Fatalf("expected fail with fix, got %s %q", res.Status, res.Next)
+	}
+	if len(res.Issues) != 1 || res.Issues[0].Path != "go.mod" {
+		t.Fatalf("expected go.mod issue, got %+v", res.Issues)
+	}
+	if !strings.Contains(res.Issues[0].Detail, "+ require example.com/dep v1.0.0") {
+		t.Fatalf("expected diff detail, got %q", res.Issues[0].Detail)
+	}
+	content, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		t.Fatalf("read go.mod: %v", err)
+	}
+	if string(content) != original {
+		t.Fatalf("expected go.mod untouched, got %q", string(content))
+	}
+}
+
+func TestGoModTidyCheckFailsWhenTidyErrors(t *testing.T) {
+	t.Setenv("PATH", stubHygieneBinaries(t))
+	t.Setenv("DUN_TIDY_EXIT", "1")
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/app\n")
+
+	res, err := runGoModTidyCheck(root, CheckDefinition{ID: "go-mod-tidy"}, GoHygieneConfig{})
+	if err != nil {
+		t.Fatalf("tidy check: %v", err)
+	}
+	if res.Status != "fail" || res.Signal != "go mod tidy failed" {
+		t.Fatalf("expected tidy failure, got %s %q", res.Status, res.Signal)
+	}
+}
+
+func TestGoGenerateDriftCheckIsOptIn(t *testing.T) {
+	spec := Check{ID: "go-generate-drift", Type: "go-generate-drift"}
+	if got := expandCheck(t.TempDir(), spec, Options{}); len(got) != 0 {
+		t.Fatalf("expected no check by default, got %+v", got)
+	}
+	if got := expandCheck(t.TempDir(), spec, Options{GoGenerateDrift: true}); len(got) != 1 {
+		t.Fatalf("expected check when enabled, got %+v", got)
+	}
+}
+
+func TestGoGenerateDriftCheckPassesWhenUpToDate(t *testing.T) {
+	t.Setenv("PATH", stubHygieneBinaries(t))
+	t.Setenv("DUN_GEN_CONTENT", "var x = 1")
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "gen.go"), "// Code generated by stub. DO NOT EDIT.\npackage app\nvar x = 1\n")
+
+	res, err := runGoGenerateDriftCheck(root, CheckDefinition{ID: "go-generate-drift"}, GoHygieneConfig{})
+	if err != nil {
+		t.Fatalf("generate check: %v", err)
+	}
+	if res.Status != "pass" {
+		t.Fatalf("expected pass, got %s", res.Status)
+	}
+}
+
+func TestGoGenerateDriftCheckReportsStaleFile(t *testing.T) {
+	t.Setenv("PATH", stubHygieneBinaries(t))
+	t.Setenv("DUN_GEN_CONTENT", "var x = 2")
+	root := t.TempDir()
+	stale := "// Code generated by stub. DO NOT EDIT.\npackage app\nvar x = 1\n"
+	writeFile(t, filepath.Join(root, "gen.go"), stale)
+
+	res, err := runGoGenerateDriftCheck(root, CheckDefinition{ID: "go-generate-drift"}, GoHygieneConfig{})
+	if err != nil {
+		t.Fatalf("generate check: %v", err)
+	}
+	if res.Status != "fail" || res.Next != "go generate ./..." {
+		t.Fatalf("expected fail with fix, got %s %q", res.Status, res.Next)
+	}
+	if len(res.Issues) != 1 || res.Issues[0].Path != "gen.go" {
+		t.Fatalf("expected gen.go issue, got %+v", res.Issues)
+	}
+	content, err := os.ReadFile(filepath.Join(root, "gen.go"))
+	if err != nil {
+		t.Fatalf("read gen.go: %v", err)
+	}
+	if string(content) != stale {
+		t.Fatalf("expected tree untouched")
+	}
+}
+
+func TestGoGenerateDriftCheckReportsMissingFile(t *testing.T) {
+	t.Setenv("PATH", stubHygieneBinaries(t))
+	t.Setenv("DUN_GEN_CONTENT", "var x = 1")
+
+	res, err := runGoGenerateDriftCheck(t.TempDir(), CheckDefinition{ID: "go-generate-drift"}, GoHygieneConfig{})
+	if err != nil {
+		t.Fatalf("generate check: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 1 || res.Issues[0].Summary != "Generated file missing from tree" {
+		t.Fatalf("expected missing generated file, got %s %+v", res.Status, res.Issues)
+	}
+}
+
+func TestIsGeneratedGo(t *testing.T) {
+	if !isGeneratedGo([]byte("// Code generated by x. DO NOT EDIT.\n\npackage a\n")) {
+		t.Fatalf("expected generated header to match")
+	}
+	if isGeneratedGo([]byte("package a\n// Code generated by x. DO NOT EDIT.\n")) {
+		t.Fatalf("expected header after package clause to be ignored")
+	}
+}
+
+func stubHygieneBinaries(t *testing.T) string {
+	t.Helper()
+	binDir := t.TempDir()
+	gofmt := `#!/bin/sh
+for f in ${DUN_GOFMT_FILES:-}; do
+  echo "$f"
+done
+exit 0
+`
+	goScript := `#!/bin/sh
+if [ "$1" = "mod" ] && [ "$2" = "tidy" ]; then
+  for arg in "$@"; do
+    case "$arg" in
+      -modfile=*) modfile="${arg#-modfile=}" ;;
+    esac
+  done
+  if [ -n "${DUN_TIDY_ADD:-}" ]; then
+    echo "$DUN_TIDY_ADD" >> "$modfile"
+  fi
+  exit ${DUN_TIDY_EXIT:-0}
+fi
+if [ "$1" = "generate" ]; then
+  printf '// Code generated by stub. DO NOT EDIT.\npackage app\n%s\n' "$DUN_GEN_CONTENT" > gen.go
+  exit 0
+fi
+echo "unknown go args: $@" >&2
+exit 1
+`
+	for name, script := range map[string]string{"gofmt": gofmt, "go": goScript} {
+		path := filepath.Join(binDir, name)
+		writeFile(t, path, script)
+		if err := os.Chmod(path, 0755); err != nil {
+			t.Fatalf("chmod %s: %v", name, err)
+		}
+	}
+	return binDir
+}
@@ -0,0 +1,241 @@
+package dun
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Issue types of go-test check results.
+const (
+	GoTestIssueFailing = "failing" // Failed with no passing retry
+	GoTestIssueFlaky   = "flaky"   // Failed, then passed on a retry
+)
+
+// goTestEvent is one line of `go test -json` (test2json) output.
+type goTestEvent struct {
+	Action  string `json:"Action"`
+	Package string `json:"Package"`
+	Test    string `json:"Test"`
+	Output  string `json:"Output"`
+}
+
+type goTestFailure struct {
+	Package  string
+	Test     string
+	Output   string
+	Flaky    bool
+	Attempts int
+	Failures int // runs that failed; more than one with -count
+	Runs     int // runs started, 0 for package failures
+}
+
+type goTestRun struct {
+	Failed []goTestFailure
+	lines  []string
+}
+
+// Text returns the human-readable output reconstructed from the event stream.
+func (r goTestRun) Text() string {
+	return strings.Join(r.lines, "")
+}
+
+func goTestFlags(config GoTestConfig) []string {
+	var flags []string
+	if config.Race {
+		flags = append(flags, "-race")
+	}
+	if config.Shuffle != "" {
+		flags = append(flags, "-shuffle="+config.Shuffle)
+	}
+	if config.Count > 0 {
+		flags = append(flags, "-count="+strconv.Itoa(config.Count))
+	}
+	if config.Failfast {
+		flags = append(flags, "-failfast")
+	}
+	return flags
+}
+
+// parseGoTestEvents collects failed tests from `go test -json` output. Lines
+// that are not JSON (build errors on stderr) are kept in the text output.
+// Parent tests are dropped when one of their subtests failed, so each failure
+// points at the most specific test. With -count, a test is reported once with
+// the output of its first failing run.
+func parseGoTestEvents(output []byte) goTestRun {
+	var run goTestRun
+	outputs := map[string]*strings.Builder{}
+	runs := map[string]int{}
+	var failed []goTestFailure
+	failedIndex := map[string]int{}
+	failedTests := map[string]bool{}
+
+	for _, line := range strings.SplitAfter(string(output), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var event goTestEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil || event.Action == "" {
+			run.lines = append(run.lines, line)
+			continue
+		}
+		key := event.Package + "\x00" + event.Test
+		switch event.Action {
+		case "output":
+			run.lines = append(run.lines, event.Output)
+			buf, ok := outputs[key]
+			if !ok {
+				buf = &strings.Builder{}
+				outputs[key] = buf
+			}
+			buf.WriteString(event.Output)
+		case "run":
+			runs[key]++
+			delete(outputs, key)
+		case "fail":
+			if i, ok := failedIndex[key]; ok {
+				failed[i].Failures++
+				continue
+			}
+			failure := goTestFailure{Package: event.Package, Test: event.Test, Failures: 1}
+			if buf, ok := outputs[key]; ok {
+				failure.Output = buf.String()
+			}
+			failedIndex[key] = len(failed)
+			failed = append(failed, failure)
+			if event.Test != "" {
+				failedTests[event.Package] = true
+			}
+		}
+	}
+
+	for _, failure := range failed {
+		if failure.Test == "" && failedTests[failure.Package] {
+			continue
+		}
+		if hasFailedSubtest(failed, failure) {
+			continue
+		}
+		failure.Runs = runs[failure.Package+"\x00"+failure.Test]
+		run.Failed = append(run.Failed, failure)
+	}
+	return run
+}
+
+func hasFailedSubtest(failed []goTestFailure, parent goTestFailure) bool {
+	if parent.Test == "" {
+		return false
+	}
+	for _, other := range failed {
+		if other.Package == parent.Package && strings.HasPrefix(other.Test, parent.Test+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyFlakyTests re-runs each failed test up to FlakeRetries times. A test
+// that passes on any retry is flaky; one that keeps failing is a real failure.
+func classifyFlakyTests(root string, config GoTestConfig, failures []goTestFailure) []goTestFailure {
+	out := make([]goTestFailure, len(failures))
+	for i, failure := range failures {
+		out[i] = failure
+		if failure.Test == "" {
+			continue
+		}
+		args := []string{"test", "-count=1", "-run", goTestRunPattern(failure.Test)}
+		if config.Race {
+			args = append(args, "-race")
+		}
+		args = append(args, failure.Package)
+		for attempt := 1; attempt <= config.FlakeRetries; attempt++ {
+			out[i].Attempts = attempt
+			if _, err := runGoCommand(root, args...); err == nil {
+				out[i].Flaky = true
+				break
+			}
+		}
+	}
+	return out
+}
+
+// goTestRunPattern anchors each element of a (sub)test name for -run.
+func goTestRunPattern(test string) string {
+	parts := strings.Split(test, "/")
+	for i, part := range parts {
+		parts[i] = "^" + regexp.QuoteMeta(part) + "$"
+	}
+	return strings.Join(parts, "/")
+}
+
+func goTestFailureResult(def CheckDefinition, failures []goTestFailure) CheckResult {
+	var failing, flaky []goTestFailure
+	for _, failure := range failures {
+		if failure.Flaky {
+			flaky = append(flaky, failure)
+		} else {
+			failing = append(failing, failure)
+		}
+	}
+	// Real regressions come first so they are picked before flaky tests.
+	var issues []Issue
+	for _, failure := range append(append([]goTestFailure{}, failing...), flaky...) {
+		issues = append(issues, goTestIssue(failure))
+	}
+
+	if len(failing) == 0 {
+		first := flaky[0]
+		return CheckResult{
+			ID:     def.ID,
+			Status: "warn",
+			Signal: fmt.Sprintf("%d flaky tests", len(flaky)),
+			Detail: "Tests failed once and passed on retry; fix the nondeterminism rather than the assertion.",
+			Next:   fmt.Sprintf("go test -count=20 -run '%s' %s", goTestRunPattern(first.Test), first.Package),
+			Issues: issues,
+		}
+	}
+
+	signal := fmt.Sprintf("%d failing tests", len(failing))
+	if len(flaky) > 0 {
+		signal = fmt.Sprintf("%s, %d flaky", signal, len(flaky))
+	}
+	first := failing[0]
+	next := "go test " + first.Package
+	if first.Test != "" {
+		next = fmt.Sprintf("go test -run '%s' %s", goTestRunPattern(first.Test), first.Package)
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: signal,
+		Detail: trimOutput([]byte(first.Output)),
+		Next:   next,
+		Issues: issues,
+	}
+}
+
+func goTestIssue(failure goTestFailure) Issue {
+	issue := Issue{
+		ID:       failure.Package,
+		Summary:  "package failed",
+		Path:     failure.Package,
+		Severity: "fail",
+		Type:     GoTestIssueFailing,
+		Detail:   strings.TrimSpace(failure.Output),
+	}
+	if failure.Test != "" {
+		issue.ID = failure.Package + "." + failure.Test
+		issue.Summary = failure.Test + " failed"
+		if failure.Runs > 1 {
+			issue.Summary = fmt.Sprintf("%s failed in %d of %d runs", failure.Test, failure.Failures, failure.Runs)
+		}
+	}
+	if failure.Flaky {
+		issue.Type = GoTestIssueFlaky
+		issue.Severity = "warn"
+		issue.Summary = fmt.Sprintf("%s is flaky (passed on retry %d)", failure.Test, failure.Attempts)
+	}
+	return issue
+}
@@ -0,0 +1,205 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const goTestEventsFixture = `{"Action":"run","Package":"example.com/app","Test":"TestA"}
+{"Action":"output","Package":"example.com/app","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Action":"output","Package":"example.com/app","Test":"TestA/sub","Output":"    a_test.go:12: boom\n"}
+{"Action":"fail","Package":"example.com/app","Test":"TestA/sub"}
+{"Action":"fail","Package":"example.com/app","Test":"TestA"}
+{"Action":"pass","Package":"example.com/app","Test":"TestB"}
+{"Action":"fail","Package":"example.com/app"}
+# example.com/broken
+broken.go:3:1: syntax error
+{"Action":"fail","Package":"example.com/broken"}
+`
+
+func TestParseGoTestEventsKeepsLeafFailures(t *testing.T) {
+	run := parseGoTestEvents([]byte(goTestEventsFixture))
+	if len(run.Failed) != 2 {
+		t.Fatalf("expected 2 failures, got %+v", run.Failed)
+	}
+	if run.Failed[0].Test != "TestA/sub" || !strings.Contains(run.Failed[0].Output, "boom") {
+		t.Fatalf("expected TestA/sub with output, got %+v", run.Failed[0])
+	}
+	if run.Failed[1].Package != "example.com/broken" || run.Failed[1].Test != "" {
+		t.Fatalf("expected package failure, got %+v", run.Failed[1])
+	}
+	if !strings.Contains(run.Text(), "syntax error") {
+		t.Fatalf("expected non-JSON lines in text, got %q", run.Text())
+	}
+}
+
+func TestParseGoTestEventsDedupesRepeatedRuns(t *testing.T) {
+	events := `{"Action":"run","Package":"example.com/app","Test":"TestA"}
+{"Action":"output","Package":"example.com/app","Test":"TestA","Output":"    a_test.go:5: first\n"}
+{"Action":"fail","Package":"example.com/app","Test":"TestA"}
+{"Action":"run","Package":"example.com/app","Test":"TestA"}
+{"Action":"pass","Package":"example.com/app","Test":"TestA"}
+{"Action":"run","Package":"example.com/app","Test":"TestA"}
+{"Action":"output","Package":"example.com/app","Test":"TestA","Output":"    a_test.go:5: third\n"}
+{"Action":"fail","Package":"example.com/app","Test":"TestA"}
+{"Action":"fail","Package":"example.com/app"}
+`
+	run := parseGoTestEvents([]byte(events))
+	if len(run.Failed) != 1 {
+		t.Fatalf("expected 1 failure, got %+v", run.Failed)
+	}
+	failure := run.Failed[0]
+	if failure.Failures != 2 || failure.Runs != 3 {
+		t.Fatalf("expected 2 of 3 runs failed, got %+v", failure)
+	}
+	if !strings.Contains(failure.Output, "first") || strings.Contains(failure.Output, "third") {
+		t.Fatalf("expected output of the first failing run, got %q", failure.Output)
+	}
+	if got := goTestIssue(failure).Summary; got != "TestA failed in 2 of 3 runs" {
+		t.Fatalf("unexpected summary %q", got)
+	}
+}
+
+func TestGoTestFlags(t *testing.T) {
+	flags := goTestFlags(GoTestConfig{Race: true, Shuffle: "on", Count: 3, Failfast: true})
+	want := "-race -shuffle=on -count=3 -failfast"
+	if got := strings.Join(flags, " "); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if len(goTestFlags(GoTestConfig{})) != 0 {
+		t.Fatalf("expected no flags by default")
+	}
+}
+
+func TestGoTestRunPattern(t *testing.T) {
+	if got := goTestRunPattern("TestA/case_1.x"); got != `^TestA$/^case_1\.x$` {
+		t.Fatalf("unexpected pattern %q", got)
+	}
+}
+
+func TestGoTestCheckPassesFlags(t *testing.T) {
+	binDir, argsFile := stubGoTestEvents(t, "", 0)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	config := GoTestConfig{Race: true, Shuffle: "on", Count: 2, Failfast: true}
+	res, err := runGoTestCheck(t.TempDir(), CheckDefinition{ID: "go-test"}, config)
+	if err != nil {
+		t.Fatalf("go test check: %v", err)
+	}
+	if res.Status != "pass" {
+		t.Fatalf("expected pass, got %s", res.Status)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("read args: %v", err)
+	}
+	if !strings.Contains(string(args), "test -json -race -shuffle=on -count=2 -failfast ./...") {
+		t.Fatalf("unexpected args %q", string(args))
+	}
+}
+
+func TestGoTestCheckReportsFailingTests(t *testing.T) {
+	binDir, _ := stubGoTestEvents(t, goTestEventsFixture, 1)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	res, err := runGoTestCheck(t.TempDir(), CheckDefinition{ID: "go-test"}, GoTestConfig{})
+	if err != nil {
+		t.Fatalf("go test check: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 2 {
+		t.Fatalf("expected fail with 2 issues, got %s %+v", res.Status, res.Issues)
+	}
+	if res.Issues[0].Type != GoTestIssueFailing || res.Issues[0].ID != "example.com/app.TestA/sub" {
+		t.Fatalf("unexpected issue %+v", res.Issues[0])
+	}
+	if res.Next != "go test -run '^TestA$/^sub$' example.com/app" {
+		t.Fatalf("unexpected next %q", res.Next)
+	}
+}
+
+func TestGoTestCheckClassifiesFlakyTests(t *testing.T) {
+	binDir, _ := stubGoTestEvents(t, goTestEventsFixture, 1)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("DUN_RETRY_PASS_ON", "2")
+
+	res, err := runGoTestCheck(t.TempDir(), CheckDefinition{ID: "go-test"}, GoTestConfig{FlakeRetries: 3})
+	if err != nil {
+		t.Fatalf("go test check: %v", err)
+	}
+	// The package failure is never retried, so the check still fails.
+	if res.Status != "fail" {
+		t.Fatalf("expected fail, got %s", res.Status)
+	}
+	flaky := res.Issues[1]
+	if flaky.Type != GoTestIssueFlaky || flaky.Severity != "warn" {
+		t.Fatalf("expected flaky issue, got %+v", flaky)
+	}
+	if !strings.Contains(flaky.Summary, "passed on retry 2") {
+		t.Fatalf("expected retry count in summary, got %q", flaky.Summary)
+	}
+	if res.Issues[0].Type != GoTestIssueFailing {
+		t.Fatalf("expected package failure to stay failing and come first, got %+v", res.Issues[0])
+	}
+	if !strings.Contains(res.Signal, "1 failing tests, 1 flaky") {
+		t.Fatalf("unexpected signal %q", res.Signal)
+	}
+}
+
+func TestGoTestFailureResultFlakyOnlyWarns(t *testing.T) {
+	res := goTestFailureResult(CheckDefinition{ID: "go-test"}, []goTestFailure{
+		{Package: "example.com/app", Test: "TestA", Flaky: true, Attempts: 1},
+	})
+	if res.Status != "warn" {
+		t.Fatalf("expected warn, got %s", res.Status)
+	}
+	if !strings.Contains(res.Next, "-count=20") {
+		t.Fatalf("expected reproduction hint, got %q", res.Next)
+	}
+}
+
+func TestClassifyFlakyTestsKeepsConsistentFailures(t *testing.T) {
+	binDir, _ := stubGoTestEvents(t, "", 0)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	failures := classifyFlakyTests(t.TempDir(), GoTestConfig{FlakeRetries: 2}, []goTestFailure{
+		{Package: "example.com/app", Test: "TestA"},
+	})
+	if failures[0].Flaky || failures[0].Attempts != 2 {
+		t.Fatalf("expected consistent failure after 2 attempts, got %+v", failures[0])
+	}
+}
+
+func stubGoTestEvents(t *testing.T, events string, exitCode int) (string, string) {
+	t.Helper()
+	binDir := t.TempDir()
+	eventsPath := filepath.Join(binDir, "events.json")
+	argsPath := filepath.Join(binDir, "args")
+	statePath := filepath.Join(binDir, "retries")
+	writeFile(t, eventsPath, events)
+	writeFile(t, statePath, "0")
+	script := `#!/bin/sh
+echo "$@" >> "` + argsPath + `"
+if [ "$1" = "test" ] && [ "$2" = "-json" ]; then
+  cat "` + eventsPath + `"
+  exit ` + strconv.Itoa(exitCode) + `
+fi
+if [ "$1" = "test" ] && [ "$2" = "-count=1" ]; then
+  n=$(( $(cat "` + statePath + `") + 1 ))
+  echo "$n" > "` + statePath + `"
+  if [ -n "${DUN_RETRY_PASS_ON:-}" ] && [ "$n" -ge "$DUN_RETRY_PASS_ON" ]; then
+    exit 0
+  fi
+  exit 1
+fi
+exit 1
+`
+	goPath := filepath.Join(binDir, "go")
+	writeFile(t, goPath, script)
+	if err := os.Chmod(goPath, 0755); err != nil {
+		t.Fatalf("chmod go: %v", err)
+	}
+	return binDir, argsPath
+}
@@ -0,0 +1,348 @@
+package dun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const govulncheckInstallHint = "go install golang.org/x/vuln/cmd/govulncheck@latest"
+
+// vulnMessage is one entry of the govulncheck -json stream.
+type vulnMessage struct {
+	OSV     *vulnOSV     `json:"osv,omitempty"`
+	Finding *vulnFinding `json:"finding,omitempty"`
+}
+
+type vulnOSV struct {
+	ID      string   `json:"id"`
+	Summary string   `json:"summary"`
+	Details string   `json:"details"`
+	Aliases []string `json:"aliases"`
+}
+
+type vulnFinding struct {
+	OSV          string      `json:"osv"`
+	FixedVersion string      `json:"fixed_version"`
+	Trace        []vulnFrame `json:"trace"`
+}
+
+type vulnFrame struct {
+	Module   string        `json:"module"`
+	Version  string        `json:"version"`
+	Package  string        `json:"package"`
+	Function string        `json:"function"`
+	Receiver string        `json:"receiver"`
+	Position *vulnPosition `json:"position,omitempty"`
+}
+
+type vulnPosition struct {
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+}
+
+// vulnReport groups the findings for one OSV entry.
+type vulnReport struct {
+	ID           string
+	Summary      string
+	Aliases      []string
+	Module       string
+	FoundVersion string
+	FixedVersion string
+	Reachable    bool
+	Trace        []vulnFrame
+}
+
+func runGoVulncheck(root string, def CheckDefinition, config GoVulncheckConfig) (CheckResult, error) {
+	if _, err := exec.LookPath("govulncheck"); err != nil {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "warn",
+			Signal: "govulncheck missing",
+			Detail: "govulncheck not found on PATH",
+			Next:   govulncheckInstallHint,
+		}, nil
+	}
+
+	timeout := commandTimeout(CommandConfig{Timeout: config.Timeout})
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	env := buildCommandEnv(CommandConfig{Env: config.Env})
+	args := []string{"-json"}
+	if db := vulnDBFromEnv(env); db != "" {
+		args = append(args, "-db", db)
+	}
+	args = append(args, "./...")
+
+	cmd := exec.CommandContext(ctx, "govulncheck", args...)
+	cmd.Dir = root
+	cmd.Env = env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "fail",
+			Signal: "govulncheck timed out",
+			Detail: "govulncheck exceeded timeout of " + timeout.String(),
+			Next:   "govulncheck ./...",
+		}, nil
+	}
+
+	reports, parseErr := parseGovulncheckJSON(output)
+	if err != nil && len(reports) == 0 {
+		detail := stderr.Bytes()
+		if len(detail) == 0 {
+			detail = output
+		}
+		return CheckResult{
+			ID:     def.ID,
+			Status: "fail",
+			Signal: "govulncheck failed",
+			Detail: trimOutput(detail),
+			Next:   "govulncheck ./...",
+		}, nil
+	}
+	if parseErr != nil {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "fail",
+			Signal: "govulncheck output parsing failed",
+			Detail: parseErr.Error(),
+			Next:   "govulncheck -json ./...",
+		}, nil
+	}
+
+	return vulncheckResult(def, reports), nil
+}
+
+// vulnDBFromEnv returns the -db argument for GOVULNDB. Local directories are
+// converted to file:// URLs so an offline database can be used directly.
+func vulnDBFromEnv(env []string) string {
+	var db string
+	for _, entry := range env {
+		if value, ok := strings.CutPrefix(entry, "GOVULNDB="); ok {
+			db = value
+		}
+	}
+	db = strings.TrimSpace(db)
+	if db == "" || strings.Contains(db, "://") {
+		return db
+	}
+	abs, err := filepath.Abs(db)
+	if err != nil {
+		return db
+	}
+	return "file://" + filepath.ToSlash(abs)
+}
+
+// parseGovulncheckJSON decodes the govulncheck -json message stream and
+// groups findings by OSV ID. Reachability is the most precise level seen for
+// an entry: a finding with a function in its first frame is reachable, any
+// other finding means the vulnerable code is only imported or required.
+func parseGovulncheckJSON(output []byte) ([]vulnReport, error) {
+	osvs := map[string]vulnOSV{}
+	byID := map[string]*vulnReport{}
+
+	dec := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var msg vulnMessage
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("decode govulncheck output: %w", err)
+		}
+		if msg.OSV != nil {
+			osvs[msg.OSV.ID] = *msg.OSV
+		}
+		if msg.Finding == nil || len(msg.Finding.Trace) == 0 {
+			continue
+		}
+		finding := msg.Finding
+		reachable := finding.Trace[0].Function != ""
+		report, ok := byID[finding.OSV]
+		if !ok {
+			report = &vulnReport{ID: finding.OSV}
+			byID[finding.OSV] = report
+		}
+		// Keep the first trace at the most precise level as the example stack.
+		if !ok || (reachable && !report.Reachable) {
+			report.Module = finding.Trace[0].Module
+			report.FoundVersion = finding.Trace[0].Version
+			report.FixedVersion = finding.FixedVersion
+			report.Trace = finding.Trace
+			report.Reachable = reachable
+		}
+	}
+
+	reports := make([]vulnReport, 0, len(byID))
+	for id, report := range byID {
+		if entry, ok := osvs[id]; ok {
+			report.Summary = entry.Summary
+			if report.Summary == "" {
+				report.Summary = firstLine(entry.Details)
+			}
+			report.Aliases = entry.Aliases
+		}
+		reports = append(reports, *report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Reachable != reports[j].Reachable {
+			return reports[i].Reachable
+		}
+		return reports[i].ID < reports[j].ID
+	})
+	return reports, nil
+}
+
+func vulncheckResult(def CheckDefinition, reports []vulnReport) CheckResult {
+	if len(reports) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: "no known vulnerabilities",
+		}
+	}
+
+	var issues []Issue
+	reachable := 0
+	for _, report := range reports {
+		if report.Reachable {
+			reachable++
+		}
+		issues = append(issues, vulnIssue(report))
+	}
+	imported := len(reports) - reachable
+
+	if reachable == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "warn",
+			Signal: fmt.Sprintf("%d vulnerabilities in imported code (not reachable)", imported),
+			Detail: "Vulnerable packages are imported or required but no vulnerable symbols are called.",
+			Next:   "govulncheck -show verbose ./...",
+			Issues: issues,
+		}
+	}
+
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("%d reachable vulnerabilities", reachable),
+		Detail: fmt.Sprintf("%d reachable, %d imported only", reachable, imported),
+		Next:   vulnFixNext(reports),
+		Issues: issues,
+	}
+}
+
+func vulnIssue(report vulnReport) Issue {
+	module := report.Module
+	if report.FoundVersion != "" {
+		module += "@" + report.FoundVersion
+	}
+	fixed := report.FixedVersion
+	if fixed == "" {
+		fixed = "none"
+	}
+	level := "imported"
+	severity := "warn"
+	if report.Reachable {
+		level = "reachable"
+		severity = "fail"
+	}
+
+	name := report.ID
+	if len(report.Aliases) > 0 {
+		name = fmt.Sprintf("%s (%s)", name, strings.Join(report.Aliases, ", "))
+	}
+	summary := fmt.Sprintf("%s in %s (fixed: %s, %s)", name, module, fixed, level)
+	if report.Summary != "" {
+		summary = fmt.Sprintf("%s: %s", summary, report.Summary)
+	}
+
+	issue := Issue{
+		ID:       report.ID,
+		Summary:  summary,
+		Severity: severity,
+		Detail:   formatVulnTrace(report.Trace),
+	}
+	if frame, ok := vulnEntryFrame(report.Trace); ok {
+		issue.Path = frame.Position.Filename
+		issue.Line = frame.Position.Line
+	}
+	return issue
+}
+
+// vulnEntryFrame returns the outermost frame with a source position, which is
+// the call site in the scanned module.
+func vulnEntryFrame(trace []vulnFrame) (vulnFrame, bool) {
+	for i := len(trace) - 1; i >= 0; i-- {
+		if trace[i].Position != nil && trace[i].Position.Filename != "" {
+			return trace[i], true
+		}
+	}
+	return vulnFrame{}, false
+}
+
+// formatVulnTrace renders a call stack from the entry point down to the
+// vulnerable symbol.
+func formatVulnTrace(trace []vulnFrame) string {
+	var lines []string
+	for i := len(trace) - 1; i >= 0; i-- {
+		frame := trace[i]
+		name := frame.Package
+		if name == "" {
+			name = frame.Module
+		}
+		if frame.Function != "" {
+			fn := frame.Function
+			if frame.Receiver != "" {
+				fn = strings.TrimPrefix(frame.Receiver, "*") + "." + fn
+			}
+			name = name + "." + fn
+		}
+		if frame.Position != nil && frame.Position.Filename != "" {
+			name = fmt.Sprintf("%s (%s:%d)", name, frame.Position.Filename, frame.Position.Line)
+		}
+		lines = append(lines, name)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func vulnFixNext(reports []vulnReport) string {
+	var upgrades []string
+	seen := map[string]bool{}
+	for _, report := range reports {
+		if !report.Reachable || report.FixedVersion == "" || report.Module == "" || report.Module == "stdlib" {
+			continue
+		}
+		target := report.Module + "@" + report.FixedVersion
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+		upgrades = append(upgrades, target)
+	}
+	if len(upgrades) == 0 {
+		return "govulncheck -show traces ./..."
+	}
+	return "go get " + strings.Join(upgrades, " ") + " && go mod tidy"
+}
+
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if idx := strings.IndexByte(text, '\n'); idx >= 0 {
+		return strings.TrimSpace(text[:idx])
+	}
+	return text
+}
@@ -0,0 +1,204 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const vulncheckFixture = `{"config":{"protocol_version":"v1.0.0","scanner_name":"govulncheck"}}
+{"osv":{"id":"GO-2023-0001","summary":"Panic in parser","aliases":["CVE-2023-0001"]}}
+{"osv":{"id":"GO-2023-0002","details":"Memory exhaustion\nin decoder"}}
+{
+  "finding": {
+    "osv": "GO-2023-0001",
+    "fixed_version": "v1.2.3",
+    "trace": [
+      {"module": "example.com/dep", "version": "v1.2.0", "package": "example.com/dep/parse"}
+    ]
+  }
+}
+{
+  "finding": {
+    "osv": "GO-2023-0001",
+    "fixed_version": "v1.2.3",
+    "trace": [
+      {"module": "example.com/dep", "version": "v1.2.0", "package": "example.com/dep/parse", "function": "Parse", "position": {"filename": "parse.go", "line": 10}},
+      {"module": "example.com/app", "package": "example.com/app", "function": "main", "position": {"filename": "main.go", "line": 7}}
+    ]
+  }
+}
+{"finding":{"osv":"GO-2023-0002","trace":[{"module":"example.com/other","version":"v0.1.0","package":"example.com/other/dec"}]}}
+`
+
+func TestParseGovulncheckJSONGroupsFindings(t *testing.T) {
+	reports, err := parseGovulncheckJSON([]byte(vulncheckFixture))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(reports))
+	}
+	reachable := reports[0]
+	if reachable.ID != "GO-2023-0001" || !reachable.Reachable {
+		t.Fatalf("expected reachable GO-2023-0001 first, got %+v", reachable)
+	}
+	if reachable.Module != "example.com/dep" || reachable.FoundVersion != "v1.2.0" || reachable.FixedVersion != "v1.2.3" {
+		t.Fatalf("unexpected module details: %+v", reachable)
+	}
+	imported := reports[1]
+	if imported.Reachable {
+		t.Fatalf("expected GO-2023-0002 to be imported only")
+	}
+	if imported.Summary != "Memory exhaustion" {
+		t.Fatalf("expected summary from details, got %q", imported.Summary)
+	}
+}
+
+func TestParseGovulncheckJSONInvalid(t *testing.T) {
+	if _, err := parseGovulncheckJSON([]byte("{not json")); err == nil {
+		t.Fatalf("expected decode error")
+	}
+}
+
+func TestVulncheckResultReachableFails(t *testing.T) {
+	reports, err := parseGovulncheckJSON([]byte(vulncheckFixture))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	res := vulncheckResult(CheckDefinition{ID: "govulncheck"}, reports)
+	if res.Status != "fail" {
+		t.Fatalf("expected fail, got %s", res.Status)
+	}
+	if len(res.Issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d", len(res.Issues))
+	}
+	issue := res.Issues[0]
+	if issue.ID != "GO-2023-0001" || issue.Severity != "fail" {
+		t.Fatalf("unexpected issue: %+v", issue)
+	}
+	if issue.Path != "main.go" || issue.Line != 7 {
+		t.Fatalf("expected entry frame main.go:7, got %s:%d", issue.Path, issue.Line)
+	}
+	for _, want := range []string{"CVE-2023-0001", "example.com/dep@v1.2.0", "fixed: v1.2.3", "reachable"} {
+		if !strings.Contains(issue.Summary, want) {
+			t.Fatalf("expected %q in summary %q", want, issue.Summary)
+		}
+	}
+	if !strings.HasPrefix(issue.Detail, "example.com/app.main (main.go:7)") {
+		t.Fatalf("expected call stack from entry point, got %q", issue.Detail)
+	}
+	if res.Issues[1].Severity != "warn" {
+		t.Fatalf("expected imported issue to warn, got %s", res.Issues[1].Severity)
+	}
+	if res.Next != "go get example.com/dep@v1.2.3 && go mod tidy" {
+		t.Fatalf("unexpected next: %q", res.Next)
+	}
+}
+
+func TestVulncheckResultImportedOnlyWarns(t *testing.T) {
+	res := vulncheckResult(CheckDefinition{ID: "govulncheck"}, []vulnReport{{ID: "GO-1", Module: "m"}})
+	if res.Status != "warn" {
+		t.Fatalf("expected warn, got %s", res.Status)
+	}
+}
+
+func TestVulncheckResultPassesWhenEmpty(t *testing.T) {
+	res := vulncheckResult(CheckDefinition{ID: "govulncheck"}, nil)
+	if res.Status != "pass" {
+		t.Fatalf("expected pass, got %s", res.Status)
+	}
+}
+
+func TestVulnDBFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	if got := vulnDBFromEnv([]string{"GOVULNDB=" + dir}); got != "file://"+filepath.ToSlash(dir) {
+		t.Fatalf("expected file URL, got %q", got)
+	}
+	if got := vulnDBFromEnv([]string{"GOVULNDB=https://vuln.example.com"}); got != "https://vuln.example.com" {
+		t.Fatalf("expected URL passthrough, got %q", got)
+	}
+	if got := vulnDBFromEnv([]string{"HOME=/tmp"}); got != "" {
+		t.Fatalf("expected empty db, got %q", got)
+	}
+}
+
+func TestGoVulncheckWarnsWhenMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	res, err := runGoVulncheck(t.TempDir(), CheckDefinition{ID: "govulncheck"}, GoVulncheckConfig{})
+	if err != nil {
+		t.Fatalf("vulncheck: %v", err)
+	}
+	if res.Status != "warn" || !strings.Contains(res.Next, "govulncheck") {
+		t.Fatalf("expected warn with install hint, got %s %q", res.Status, res.Next)
+	}
+}
+
+func TestGoVulncheckPassesLocalDB(t *testing.T) {
+	binDir := stubGovulncheck(t, `{"config":{}}`, 0)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	dbDir := t.TempDir()
+	argsFile := filepath.Join(t.TempDir(), "args")
+
+	config := GoVulncheckConfig{Env: map[string]string{"GOVULNDB": dbDir, "DUN_VULN_ARGS": argsFile}}
+	res, err := runGoVulncheck(t.TempDir(), CheckDefinition{ID: "govulncheck"}, config)
+	if err != nil {
+		t.Fatalf("vulncheck: %v", err)
+	}
+	if res.Status != "pass" {
+		t.Fatalf("expected pass, got %s (%s)", res.Status, res.Detail)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("read args: %v", err)
+	}
+	if !strings.Contains(string(args), "-db file://"+filepath.ToSlash(dbDir)) {
+		t.Fatalf("expected local db arg, got %q", string(args))
+	}
+}
+
+func TestGoVulncheckReportsFindings(t *testing.T) {
+	binDir := stubGovulncheck(t, vulncheckFixture, 0)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	res, err := runGoVulncheck(t.TempDir(), CheckDefinition{ID: "govulncheck"}, GoVulncheckConfig{})
+	if err != nil {
+		t.Fatalf("vulncheck: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 2 {
+		t.Fatalf("expected fail with 2 issues, got %s with %d", res.Status, len(res.Issues))
+	}
+}
+
+func TestGoVulncheckFailsOnToolError(t *testing.T) {
+	binDir := stubGovulncheck(t, "", 1)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	res, err := runGoVulncheck(t.TempDir(), CheckDefinition{ID: "govulncheck"}, GoVulncheckConfig{})
+	if err != nil {
+		t.Fatalf("vulncheck: %v", err)
+	}
+	if res.Status != "fail" || res.Signal != "govulncheck failed" {
+		t.Fatalf("expected tool failure, got %s %q", res.Status, res.Signal)
+	}
+}
+
+func stubGovulncheck(t *testing.T, output string, exitCode int) string {
+	t.Helper()
+	binDir := t.TempDir()
+	outputPath := filepath.Join(binDir, "output.json")
+	writeFile(t, outputPath, output)
+	script := "#!/bin/sh\n" +
+		"if [ -n \"$DUN_VULN_ARGS\" ]; then echo \"$@\" > \"$DUN_VULN_ARGS\"; fi\n" +
+		"cat " + outputPath + "\n" +
+		"echo 'scan failed' >&2\n" +
+		"exit " + strconv.Itoa(exitCode) + "\n"
+	path := filepath.Join(binDir, "govulncheck")
+	writeFile(t, path, script)
+	if err := os.Chmod(path, 0755); err != nil {
+		t.Fatalf("chmod govulncheck: %v", err)
+	}
+	return binDir
+}
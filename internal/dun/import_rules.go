@@ -0,0 +1,286 @@
+package dun
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// importRulesListFunc allows mocking in tests.
+var importRulesListFunc = func(root string) ([]goListPackage, error) {
+	return runGoList(root, "-deps", "-json", "./...")
+}
+
+type importViolation struct {
+	Package string
+	Import  string
+	Via     string
+	File    string
+	Line    int
+	Rule    string
+}
+
+func runImportRulesCheck(root string, def CheckDefinition, config ImportRulesConfig) (CheckResult, error) {
+	if len(config.Rules) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "skip",
+			Signal: "no import rules configured",
+		}, nil
+	}
+	rules, err := compileImportRules(config.Rules)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	pkgs, err := importRulesListFunc(root)
+	if err != nil {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "fail",
+			Signal: "go list failed",
+			Detail: err.Error(),
+			Next:   "go list -deps -json ./...",
+		}, nil
+	}
+
+	violations := evaluateImportRules(root, rules, pkgs)
+	if len(violations) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: fmt.Sprintf("%d import rules satisfied", len(rules)),
+		}, nil
+	}
+
+	var issues []Issue
+	for _, v := range violations {
+		summary := fmt.Sprintf("%s imports %s", v.Package, v.Import)
+		if v.Via != "" {
+			summary = fmt.Sprintf("%s imports %s via %s", v.Package, v.Import, v.Via)
+		}
+		issues = append(issues, Issue{
+			ID:       "import:" + v.Package + "->" + v.Import,
+			Summary:  summary,
+			Path:     v.File,
+			Line:     v.Line,
+			Severity: "fail",
+			Detail:   v.Rule,
+		})
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("%d import rule violations", len(violations)),
+		Detail: issues[0].Summary + " (" + violations[0].Rule + ")",
+		Next:   "Remove the import or move the code to a package allowed to depend on it.",
+		Issues: issues,
+	}, nil
+}
+
+// compiledImportRule is an ImportRule with its patterns turned into matchers.
+type compiledImportRule struct {
+	rule     ImportRule
+	packages []importPattern
+	deny     []importPattern
+	allow    []importPattern
+	only     []importPattern
+	imports  []importPattern
+}
+
+// importPattern matches import paths. "..." matches any string and "*" one
+// path element; "x/..." also matches "x" itself, as in go tool patterns.
+type importPattern struct {
+	raw string
+	re  *regexp.Regexp
+}
+
+func compileImportRules(rules []ImportRule) ([]compiledImportRule, error) {
+	var out []compiledImportRule
+	for i, rule := range rules {
+		c := compiledImportRule{rule: rule}
+		var err error
+		if c.packages, err = compileImportPatterns(rule.Package); err != nil {
+			return nil, err
+		}
+		if c.deny, err = compileImportPatterns(rule.Deny...); err != nil {
+			return nil, err
+		}
+		if c.allow, err = compileImportPatterns(rule.Allow...); err != nil {
+			return nil, err
+		}
+		if c.only, err = compileImportPatterns(rule.Only...); err != nil {
+			return nil, err
+		}
+		if c.imports, err = compileImportPatterns(rule.Import); err != nil {
+			return nil, err
+		}
+		if len(c.deny) == 0 && len(c.imports) == 0 {
+			return nil, fmt.Errorf("import rule %d: set deny or import", i+1)
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func compileImportPatterns(patterns ...string) ([]importPattern, error) {
+	var out []importPattern
+	for _, raw := range patterns {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		expr := regexp.QuoteMeta(raw)
+		if strings.HasSuffix(expr, `/\.\.\.`) {
+			expr = strings.TrimSuffix(expr, `/\.\.\.`) + `(/.*)?`
+		}
+		expr = strings.ReplaceAll(expr, `\.\.\.`, `.*`)
+		expr = strings.ReplaceAll(expr, `\*`, `[^/]*`)
+		re, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			return nil, fmt.Errorf("import pattern %q: %w", raw, err)
+		}
+		out = append(out, importPattern{raw: raw, re: re})
+	}
+	return out, nil
+}
+
+// matchImportPatterns matches a full import path, or its path relative to the
+// main module so rules can say "cmd/..." instead of "example.com/app/cmd/...".
+func matchImportPatterns(patterns []importPattern, path string, modulePath string) bool {
+	rel := ""
+	if modulePath != "" && strings.HasPrefix(path, modulePath+"/") {
+		rel = strings.TrimPrefix(path, modulePath+"/")
+	} else if modulePath != "" && path == modulePath {
+		rel = "."
+	}
+	for _, p := range patterns {
+		if p.re.MatchString(path) || (rel != "" && p.re.MatchString(rel)) {
+			return true
+		}
+	}
+	return false
+}
+
+// forbids reports whether the rule forbids pkg from depending on dep.
+func (c compiledImportRule) forbids(pkg string, dep string, modulePath string) bool {
+	if len(c.packages) > 0 && !matchImportPatterns(c.packages, pkg, modulePath) {
+		return false
+	}
+	if matchImportPatterns(c.allow, pkg, modulePath) {
+		return false
+	}
+	if len(c.deny) > 0 && matchImportPatterns(c.deny, dep, modulePath) {
+		return true
+	}
+	if len(c.imports) > 0 && matchImportPatterns(c.imports, dep, modulePath) {
+		return !matchImportPatterns(c.only, pkg, modulePath)
+	}
+	return false
+}
+
+func (c compiledImportRule) describe() string {
+	if c.rule.Reason != "" {
+		return c.rule.Reason
+	}
+	scope := "all packages"
+	if c.rule.Package != "" {
+		scope = c.rule.Package
+	}
+	if len(c.deny) > 0 {
+		return fmt.Sprintf("%s must not import %s", scope, strings.Join(c.rule.Deny, ", "))
+	}
+	if len(c.rule.Only) == 0 {
+		return fmt.Sprintf("no package may import %s", c.rule.Import)
+	}
+	return fmt.Sprintf("only %s may import %s", strings.Join(c.rule.Only, ", "), c.rule.Import)
+}
+
+// evaluateImportRules checks every main-module package's direct imports, and
+// with transitive rules the dependencies reached through them.
+func evaluateImportRules(root string, rules []compiledImportRule, pkgs []goListPackage) []importViolation {
+	deps := map[string][]string{}
+	for _, pkg := range pkgs {
+		deps[pkg.ImportPath] = pkg.Deps
+	}
+
+	var violations []importViolation
+	for _, pkg := range pkgs {
+		if pkg.Module == nil || !pkg.Module.Main {
+			continue
+		}
+		modulePath := pkg.Module.Path
+		var positions map[string]token.Position
+		for _, imp := range pkg.Imports {
+			for _, rule := range rules {
+				target, via := "", ""
+				if rule.forbids(pkg.ImportPath, imp, modulePath) {
+					target = imp
+				} else if rule.rule.Transitive {
+					for _, dep := range deps[imp] {
+						if rule.forbids(pkg.ImportPath, dep, modulePath) {
+							target, via = dep, imp
+							break
+						}
+					}
+				}
+				if target == "" {
+					continue
+				}
+				if positions == nil {
+					positions = importPositions(root, pkg)
+				}
+				pos := positions[imp]
+				violations = append(violations, importViolation{
+					Package: pkg.ImportPath,
+					Import:  target,
+					Via:     via,
+					File:    pos.Filename,
+					Line:    pos.Line,
+					Rule:    rule.describe(),
+				})
+			}
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].File != violations[j].File {
+			return violations[i].File < violations[j].File
+		}
+		return violations[i].Line < violations[j].Line
+	})
+	return violations
+}
+
+// importPositions maps each import path of pkg to its first import spec,
+// with the filename relative to root.
+func importPositions(root string, pkg goListPackage) map[string]token.Position {
+	positions := map[string]token.Position{}
+	fset := token.NewFileSet()
+	files := append(append([]string{}, pkg.GoFiles...), pkg.CgoFiles...)
+	for _, name := range files {
+		path := filepath.Join(pkg.Dir, name)
+		file, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, spec := range file.Imports {
+			imp, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			if _, ok := positions[imp]; ok {
+				continue
+			}
+			pos := fset.Position(spec.Pos())
+			if rel, err := filepath.Rel(root, pos.Filename); err == nil {
+				pos.Filename = filepath.ToSlash(rel)
+			}
+			positions[imp] = pos
+		}
+	}
+	return positions
+}
@@ -0,0 +1,137 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func importRulesFixture(t *testing.T) (string, []goListPackage) {
+	t.Helper()
+	root := t.TempDir()
+	for dir, content := range map[string]string{
+		"internal/core/core.go":     "package core\n\nimport (\n\t\"fmt\"\n\t\"net/http\"\n\n\t\"example.com/app/cmd/app\"\n)\n",
+		"internal/update/update.go": "package update\n\nimport \"net/http\"\n",
+		"internal/util/util.go":     "package util\n\nimport \"example.com/app/internal/update\"\n",
+		"cmd/app/main.go":           "package main\n\nimport \"example.com/app/internal/core\"\n",
+	} {
+		path := filepath.Join(root, dir)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		writeFile(t, path, content)
+	}
+	main := &goListModule{Path: "example.com/app", Main: true}
+	pkgs := []goListPackage{
+		{ImportPath: "fmt", Standard: true},
+		{ImportPath: "net/http", Standard: true, Deps: []string{"fmt"}},
+		{ImportPath: "example.com/app/internal/update", Dir: filepath.Join(root, "internal/update"), GoFiles: []string{"update.go"}, Module: main, Imports: []string{"net/http"}, Deps: []string{"fmt", "net/http"}},
+		{ImportPath: "example.com/app/internal/util", Dir: filepath.Join(root, "internal/util"), GoFiles: []string{"util.go"}, Module: main, Imports: []string{"example.com/app/internal/update"}, Deps: []string{"example.com/app/internal/update", "fmt", "net/http"}},
+		{ImportPath: "example.com/app/cmd/app", Dir: filepath.Join(root, "cmd/app"), GoFiles: []string{"main.go"}, Module: main, Imports: []string{"example.com/app/internal/core"}},
+		{ImportPath: "example.com/app/internal/core", Dir: filepath.Join(root, "internal/core"), GoFiles: []string{"core.go"}, Module: main, Imports: []string{"example.com/app/cmd/app", "fmt", "net/http"}},
+	}
+	return root, pkgs
+}
+
+func stubImportRulesList(t *testing.T, pkgs []goListPackage) {
+	t.Helper()
+	orig := importRulesListFunc
+	importRulesListFunc = func(string) ([]goListPackage, error) { return pkgs, nil }
+	t.Cleanup(func() { importRulesListFunc = orig })
+}
+
+func TestImportRulesReportsViolationsWithPositions(t *testing.T) {
+	root, pkgs := importRulesFixture(t)
+	stubImportRulesList(t, pkgs)
+
+	config := ImportRulesConfig{Rules: []ImportRule{
+		{Package: "internal/...", Deny: []string{"cmd/..."}},
+		{Import: "net/http", Only: []string{"internal/update"}},
+	}}
+	res, err := runImportRulesCheck(root, CheckDefinition{ID: "import-rules"}, config)
+	if err != nil {
+		t.Fatalf("import rules: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 2 {
+		t.Fatalf("expected 2 violations, got %s %+v", res.Status, res.Issues)
+	}
+	first := res.Issues[0]
+	if first.Path != "internal/core/core.go" || first.Line != 5 || first.Summary != "example.com/app/internal/core imports net/http" {
+		t.Fatalf("unexpected first issue %+v", first)
+	}
+	if first.Detail != "only internal/update may import net/http" {
+		t.Fatalf("unexpected rule description %q", first.Detail)
+	}
+	second := res.Issues[1]
+	if second.Line != 7 || !strings.Contains(second.Summary, "imports example.com/app/cmd/app") {
+		t.Fatalf("unexpected second issue %+v", second)
+	}
+}
+
+func TestImportRulesAllowListAndTransitive(t *testing.T) {
+	root, pkgs := importRulesFixture(t)
+	stubImportRulesList(t, pkgs)
+
+	config := ImportRulesConfig{Rules: []ImportRule{
+		{Deny: []string{"net/http"}, Allow: []string{"internal/update", "internal/core"}, Transitive: true, Reason: "keep networking in internal/update"},
+	}}
+	res, err := runImportRulesCheck(root, CheckDefinition{ID: "import-rules"}, config)
+	if err != nil {
+		t.Fatalf("import rules: %v", err)
+	}
+	if len(res.Issues) != 1 {
+		t.Fatalf("expected transitive violation only, got %+v", res.Issues)
+	}
+	issue := res.Issues[0]
+	if issue.Summary != "example.com/app/internal/util imports net/http via example.com/app/internal/update" {
+		t.Fatalf("unexpected summary %q", issue.Summary)
+	}
+	if issue.Path != "internal/util/util.go" || issue.Line != 3 || issue.Detail != "keep networking in internal/update" {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+}
+
+func TestImportRulesPass(t *testing.T) {
+	root, pkgs := importRulesFixture(t)
+	stubImportRulesList(t, pkgs)
+
+	config := ImportRulesConfig{Rules: []ImportRule{{Package: "cmd/*", Deny: []string{"internal/update"}}}}
+	res, err := runImportRulesCheck(root, CheckDefinition{ID: "import-rules"}, config)
+	if err != nil {
+		t.Fatalf("import rules: %v", err)
+	}
+	if res.Status != "pass" {
+		t.Fatalf("expected pass, got %s %+v", res.Status, res.Issues)
+	}
+}
+
+func TestImportRulesRejectsEmptyRule(t *testing.T) {
+	if _, err := compileImportRules([]ImportRule{{Package: "internal/..."}}); err == nil {
+		t.Fatalf("expected error for rule without deny or import")
+	}
+	res, err := runImportRulesCheck(t.TempDir(), CheckDefinition{ID: "import-rules"}, ImportRulesConfig{})
+	if err != nil || res.Status != "skip" {
+		t.Fatalf("expected skip without rules, got %s %v", res.Status, err)
+	}
+}
+
+func TestImportPatternMatching(t *testing.T) {
+	patterns, err := compileImportPatterns("cmd/...", "internal/*/api", "golang.org/x/...")
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	cases := map[string]bool{
+		"example.com/app/cmd":                  true,
+		"example.com/app/cmd/dun":              true,
+		"example.com/app/command":              false,
+		"example.com/app/internal/svc/api":     true,
+		"example.com/app/internal/svc/sub/api": false,
+		"golang.org/x/tools/go/packages":       true,
+	}
+	for path, want := range cases {
+		if got := matchImportPatterns(patterns, path, "example.com/app"); got != want {
+			t.Fatalf("match %q: expected %v, got %v", path, want, got)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"gopkg.in/yaml.v3"
 )
@@ -27,8 +28,9 @@ type Provider struct {
 
 // Consumer represents an interface that a component consumes.
 type Consumer struct {
-	Name string `yaml:"name"`
-	From string `yaml:"from"`
+	Name       string   `yaml:"name"`
+	From       string   `yaml:"from"`
+	Operations []string `yaml:"operations"` // operationIds, "GET /path" or RPC names used
 }
 
 // runIntegrationContractCheck verifies components define and satisfy integration interfaces.
@@ -154,6 +156,8 @@ func applyContractRule(root string, rule ContractRule, integrationMap *Integrati
 		return checkConsumersSatisfied(integrationMap, graph)
 	case "no-circular-dependencies":
 		return checkNoCircularDependencies(integrationMap, graph)
+	case "consumer-operations-exist":
+		return checkConsumerOperationsExist(root, integrationMap)
 	default:
 		return nil, "pass"
 	}
@@ -228,6 +232,93 @@ func checkConsumersSatisfied(integrationMap *IntegrationMap, graph *ComponentGra
 	return issues, "pass"
 }
 
+// checkConsumerOperationsExist verifies each operation a consumer lists is
+// defined by the provider's OpenAPI or protobuf definition.
+func checkConsumerOperationsExist(root string, integrationMap *IntegrationMap) ([]Issue, string) {
+	var issues []Issue
+
+	if integrationMap == nil {
+		return issues, "pass"
+	}
+
+	definitions := make(map[string]string)
+	for _, component := range integrationMap.Components {
+		for _, provider := range component.Provides {
+			definitions[provider.Name] = provider.Definition
+		}
+	}
+
+	for _, componentName := range sortedComponentNames(integrationMap) {
+		for _, consumer := range integrationMap.Components[componentName].Consumes {
+			definition := definitions[consumer.Name]
+			if len(consumer.Operations) == 0 || definition == "" {
+				continue
+			}
+			operations, err := definitionOperations(filepath.Join(root, definition))
+			if err != nil {
+				issues = append(issues, Issue{
+					ID:      "unreadable-definition",
+					Path:    definition,
+					Summary: fmt.Sprintf("Component %s consumes %s: %v", componentName, consumer.Name, err),
+				})
+				continue
+			}
+			for _, operation := range consumer.Operations {
+				if !operations[operation] {
+					issues = append(issues, Issue{
+						ID:      "missing-operation",
+						Path:    definition,
+						Summary: fmt.Sprintf("Component %s uses %s.%s but %s does not define it", componentName, consumer.Name, operation, definition),
+					})
+				}
+			}
+		}
+	}
+
+	if len(issues) > 0 {
+		return issues, "fail"
+	}
+	return issues, "pass"
+}
+
+// definitionOperations lists the operations of a provider definition: RPCs as
+// "Method" and "Service.Method" for .proto files, operationIds and
+// "METHOD /path" for OpenAPI documents.
+func definitionOperations(path string) (map[string]bool, error) {
+	operations := make(map[string]bool)
+	if filepath.Ext(path) == ".proto" {
+		parsed, err := parseProtoFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, rpc := range parsed.RPCs {
+			operations[rpc.Name] = true
+			operations[rpc.Service+"."+rpc.Name] = true
+		}
+		return operations, nil
+	}
+	doc, err := loadOpenAPIDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range openAPIOperations(doc) {
+		if op.ID != "" {
+			operations[op.ID] = true
+		}
+		operations[op.Method+" "+op.Path] = true
+	}
+	return operations, nil
+}
+
+func sortedComponentNames(integrationMap *IntegrationMap) []string {
+	names := make([]string, 0, len(integrationMap.Components))
+	for name := range integrationMap.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // checkNoCircularDependencies detects cycles in the component dependency graph.
 func checkNoCircularDependencies(integrationMap *IntegrationMap, graph *ComponentGraph) ([]Issue, string) {
 	var issues []Issue
@@ -1198,3 +1198,63 @@ func TestRunIntegrationContractCheck_MultipleConsumersOfSameInterface(t *testing
 		t.Errorf("expected status 'pass', got %q", result.Status)
 	}
 }
+
+func TestRunIntegrationContractCheck_ConsumerOperationsExist(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "contracts"), 0755); err != nil {
+		t.Fatalf("failed to create contracts dir: %v", err)
+	}
+
+	mapContent := `components:
+  pets:
+    provides:
+      - name: PetsAPI
+        definition: contracts/pets.yaml
+  orders:
+    provides:
+      - name: OrdersRPC
+        definition: contracts/orders.proto
+  web:
+    consumes:
+      - name: PetsAPI
+        from: pets
+        operations: [listPets, "GET /pets/{id}", deletePet]
+      - name: OrdersRPC
+        from: orders
+        operations: [OrderService.Create, Cancel]
+`
+	writeFile(t, filepath.Join(root, "contracts", "integration-map.yaml"), mapContent)
+	writeFile(t, filepath.Join(root, "contracts", "pets.yaml"), `openapi: 3.0.0
+paths:
+  /pets:
+    get:
+      operationId: listPets
+  /pets/{id}:
+    get:
+      operationId: getPet
+`)
+	writeFile(t, filepath.Join(root, "contracts", "orders.proto"), `syntax = "proto3";
+package orders;
+service OrderService {
+  rpc Create(Req) returns (Resp);
+}
+`)
+
+	check := Check{
+		ID:            "test-integration-contract",
+		Contracts:     ContractsConfig{Map: "contracts/integration-map.yaml"},
+		ContractRules: []ContractRule{{Type: "consumer-operations-exist"}},
+	}
+	result, err := runIntegrationContractCheckFromSpec(root, check)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != "fail" || len(result.Issues) != 2 {
+		t.Fatalf("expected two missing operations, got %+v", result)
+	}
+	for _, issue := range result.Issues {
+		if issue.ID != "missing-operation" {
+			t.Errorf("unexpected issue %+v", issue)
+		}
+	}
+}
@@ -0,0 +1,488 @@
+package dun
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// nodeProject is the part of package.json the node checks rely on.
+type nodeProject struct {
+	Root           string
+	PackageManager string
+	Scripts        map[string]string
+	Deps           map[string]bool
+}
+
+type nodePackageJSON struct {
+	PackageManager  string            `json:"packageManager"`
+	Scripts         map[string]string `json:"scripts"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+// nodeTool is how a check will invoke a tool: directly (with a reporter the
+// check can parse) or through the project's script.
+type nodeTool struct {
+	Path   string
+	Args   []string
+	Script string
+}
+
+var tscDiagnosticLine = regexp.MustCompile(`^(.+?)\((\d+),(\d+)\): (error|warning) (TS\d+): (.*)$`)
+
+func loadNodeProject(root string) (nodeProject, error) {
+	data, err := os.ReadFile(filepath.Join(root, "package.json"))
+	if err != nil {
+		return nodeProject{}, err
+	}
+	var pkg nodePackageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nodeProject{}, fmt.Errorf("parse package.json: %w", err)
+	}
+	project := nodeProject{
+		Root:           root,
+		PackageManager: detectNodePackageManager(root, pkg.PackageManager),
+		Scripts:        pkg.Scripts,
+		Deps:           map[string]bool{},
+	}
+	for name := range pkg.Dependencies {
+		project.Deps[name] = true
+	}
+	for name := range pkg.DevDependencies {
+		project.Deps[name] = true
+	}
+	// npm init writes a placeholder test script that always fails.
+	if strings.Contains(project.Scripts["test"], "no test specified") {
+		delete(project.Scripts, "test")
+	}
+	return project, nil
+}
+
+// detectNodePackageManager prefers the packageManager field, then lockfiles,
+// then npm.
+func detectNodePackageManager(root string, field string) string {
+	if field != "" {
+		name := strings.SplitN(field, "@", 2)[0]
+		switch name {
+		case "npm", "pnpm", "yarn", "bun":
+			return name
+		}
+	}
+	lockfiles := []struct {
+		file    string
+		manager string
+	}{
+		{"pnpm-lock.yaml", "pnpm"},
+		{"yarn.lock", "yarn"},
+		{"bun.lockb", "bun"},
+		{"bun.lock", "bun"},
+		{"package-lock.json", "npm"},
+	}
+	for _, lock := range lockfiles {
+		if _, err := os.Stat(filepath.Join(root, lock.file)); err == nil {
+			return lock.manager
+		}
+	}
+	return "npm"
+}
+
+func nodeInstallHint(manager string, tool string) string {
+	switch manager {
+	case "pnpm":
+		return "pnpm add -D " + tool
+	case "yarn":
+		return "yarn add --dev " + tool
+	case "bun":
+		return "bun add -d " + tool
+	default:
+		return "npm install --save-dev " + tool
+	}
+}
+
+// nodeToolPath finds a tool in node_modules/.bin, then on PATH.
+func nodeToolPath(root string, tool string) string {
+	local := filepath.Join(root, "node_modules", ".bin", tool)
+	if info, err := os.Stat(local); err == nil && !info.IsDir() {
+		return local
+	}
+	if path, err := exec.LookPath(tool); err == nil {
+		return path
+	}
+	return ""
+}
+
+// scriptToolArgs returns the arguments a script passes to tool, or false when
+// the script does not invoke tool. Arguments stop at the first shell operator.
+func scriptToolArgs(script string, tool string) ([]string, bool) {
+	fields := strings.Fields(script)
+	for i, field := range fields {
+		if field != tool {
+			continue
+		}
+		var args []string
+		for _, arg := range fields[i+1:] {
+			if arg == "&&" || arg == "||" || arg == "|" || arg == ";" {
+				break
+			}
+			args = append(args, arg)
+		}
+		return args, true
+	}
+	return nil, false
+}
+
+// resolveNodeTool decides how to run a check: the first tool the script uses
+// (or, without a script, the first tool the project depends on) runs directly;
+// other scripts run through the package manager. ok is false with a skip
+// result when nothing can run.
+func resolveNodeTool(project nodeProject, def CheckDefinition, script string, tools []string, defaultArgs map[string][]string) (nodeTool, string, CheckResult, bool) {
+	command, hasScript := project.Scripts[script]
+	for _, tool := range tools {
+		args, used := scriptToolArgs(command, tool)
+		if !hasScript {
+			used = project.Deps[tool] || (tool == "tsc" && fileExists(filepath.Join(project.Root, "tsconfig.json")))
+			args = defaultArgs[tool]
+		}
+		if !used {
+			continue
+		}
+		path := nodeToolPath(project.Root, tool)
+		if path == "" {
+			next := nodeInstallHint(project.PackageManager, nodePackageName(tool))
+			if project.Deps[nodePackageName(tool)] {
+				next = project.PackageManager + " install"
+			}
+			return nodeTool{}, tool, CheckResult{
+				ID:     def.ID,
+				Status: "skip",
+				Signal: tool + " missing",
+				Detail: tool + " not found in node_modules/.bin or on PATH",
+				Next:   next,
+			}, false
+		}
+		return nodeTool{Path: path, Args: args}, tool, CheckResult{}, true
+	}
+	if hasScript {
+		if _, err := exec.LookPath(project.PackageManager); err != nil {
+			return nodeTool{}, "", CheckResult{
+				ID:     def.ID,
+				Status: "skip",
+				Signal: project.PackageManager + " missing",
+				Detail: project.PackageManager + " not found on PATH",
+				Next:   "Install " + project.PackageManager,
+			}, false
+		}
+		return nodeTool{Script: script}, "", CheckResult{}, true
+	}
+	return nodeTool{}, "", CheckResult{
+		ID:     def.ID,
+		Status: "skip",
+		Signal: fmt.Sprintf("no %s script", script),
+		Detail: fmt.Sprintf("package.json has no %q script and no %s dependency", script, strings.Join(tools, "/")),
+		Next:   nodeInstallHint(project.PackageManager, nodePackageName(tools[0])),
+	}, false
+}
+
+func nodePackageName(tool string) string {
+	if tool == "tsc" {
+		return "typescript"
+	}
+	return tool
+}
+
+func runNodeScript(project nodeProject, config NodeCheckConfig, script string) (toolRun, string, error) {
+	args := []string{"run", script}
+	run, err := runToolCommand(project.Root, config.Timeout, config.Env, project.PackageManager, args...)
+	return run, toolCommandLine(project.PackageManager, args...), err
+}
+
+func runNodeTypecheck(root string, def CheckDefinition, config NodeCheckConfig) (CheckResult, error) {
+	project, err := loadNodeProject(root)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	tool, _, skip, ok := resolveNodeTool(project, def, "typecheck", []string{"tsc"}, map[string][]string{"tsc": {"--noEmit"}})
+	if !ok {
+		return skip, nil
+	}
+
+	var run toolRun
+	var command string
+	if tool.Script != "" {
+		run, command, err = runNodeScript(project, config, tool.Script)
+	} else {
+		args := append(append([]string{}, tool.Args...), "--pretty", "false")
+		command = toolCommandLine("tsc", args...)
+		run, err = runToolCommand(root, config.Timeout, config.Env, tool.Path, args...)
+	}
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	issues := parseTscOutput(root, run.Combined())
+	if len(issues) == 0 {
+		return toolExitResult(def, run, command, "typecheck"), nil
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("%d TypeScript errors", len(issues)),
+		Detail: issues[0].Path + ": " + issues[0].Summary,
+		Next:   command,
+		Issues: issues,
+	}, nil
+}
+
+func parseTscOutput(root string, output []byte) []Issue {
+	var issues []Issue
+	for _, line := range strings.Split(string(output), "\n") {
+		match := tscDiagnosticLine.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil || match[4] != "error" {
+			continue
+		}
+		lineNo, _ := strconv.Atoi(match[2])
+		path := toolRelPath(root, match[1])
+		issues = append(issues, Issue{
+			ID:       fmt.Sprintf("tsc:%s:%d:%s", path, lineNo, match[5]),
+			Summary:  match[5] + ": " + match[6],
+			Path:     path,
+			Line:     lineNo,
+			Severity: "fail",
+		})
+	}
+	return issues
+}
+
+type eslintFileResult struct {
+	FilePath string          `json:"filePath"`
+	Messages []eslintMessage `json:"messages"`
+}
+
+type eslintMessage struct {
+	RuleID   string `json:"ruleId"`
+	Severity int    `json:"severity"`
+	Message  string `json:"message"`
+	Line     int    `json:"line"`
+}
+
+func runNodeLint(root string, def CheckDefinition, config NodeCheckConfig) (CheckResult, error) {
+	project, err := loadNodeProject(root)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	tool, _, skip, ok := resolveNodeTool(project, def, "lint", []string{"eslint"}, map[string][]string{"eslint": {"."}})
+	if !ok {
+		return skip, nil
+	}
+	if tool.Script != "" {
+		run, command, err := runNodeScript(project, config, tool.Script)
+		if err != nil {
+			return CheckResult{}, err
+		}
+		return toolExitResult(def, run, command, "lint"), nil
+	}
+
+	args := append(append([]string{}, tool.Args...), "--format", "json")
+	command := toolCommandLine("eslint", tool.Args...)
+	run, err := runToolCommand(root, config.Timeout, config.Env, tool.Path, args...)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if run.TimedOut {
+		return CheckResult{ID: def.ID, Status: "fail", Signal: "eslint timed out", Next: command}, nil
+	}
+	var files []eslintFileResult
+	if err := json.Unmarshal(run.Stdout, &files); err != nil {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "fail",
+			Signal: "eslint failed",
+			Detail: trimOutput(run.Combined()),
+			Next:   command,
+		}, nil
+	}
+
+	var issues []Issue
+	failures := 0
+	for _, file := range files {
+		path := toolRelPath(root, file.FilePath)
+		for _, msg := range file.Messages {
+			severity := "warn"
+			if msg.Severity >= 2 {
+				severity = "fail"
+				failures++
+			}
+			rule := msg.RuleID
+			if rule == "" {
+				rule = "parse"
+			}
+			issues = append(issues, Issue{
+				ID:       fmt.Sprintf("eslint:%s:%d:%s", path, msg.Line, rule),
+				Summary:  rule + ": " + msg.Message,
+				Path:     path,
+				Line:     msg.Line,
+				Severity: severity,
+			})
+		}
+	}
+	if len(issues) == 0 {
+		return CheckResult{ID: def.ID, Status: "pass", Signal: "eslint passed"}, nil
+	}
+	status := "warn"
+	if failures > 0 {
+		status = "fail"
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: status,
+		Signal: fmt.Sprintf("eslint reported %d errors, %d warnings", failures, len(issues)-failures),
+		Detail: issues[0].Path + ": " + issues[0].Summary,
+		Next:   command,
+		Issues: issues,
+	}, nil
+}
+
+// jestReport is the --json output of jest, also written by vitest's json
+// reporter.
+type jestReport struct {
+	NumTotalTests  int              `json:"numTotalTests"`
+	NumFailedTests int              `json:"numFailedTests"`
+	TestResults    []jestTestResult `json:"testResults"`
+}
+
+type jestTestResult struct {
+	Name             string                `json:"name"`
+	Status           string                `json:"status"`
+	Message          string                `json:"message"`
+	AssertionResults []jestAssertionResult `json:"assertionResults"`
+}
+
+type jestAssertionResult struct {
+	FullName        string   `json:"fullName"`
+	Status          string   `json:"status"`
+	FailureMessages []string `json:"failureMessages"`
+	Location        *struct {
+		Line int `json:"line"`
+	} `json:"location"`
+}
+
+func runNodeTest(root string, def CheckDefinition, config NodeCheckConfig) (CheckResult, error) {
+	project, err := loadNodeProject(root)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	tool, name, skip, ok := resolveNodeTool(project, def, "test", []string{"vitest", "jest"}, nil)
+	if !ok {
+		return skip, nil
+	}
+	if tool.Script != "" {
+		run, command, err := runNodeScript(project, config, tool.Script)
+		if err != nil {
+			return CheckResult{}, err
+		}
+		return toolExitResult(def, run, command, "tests"), nil
+	}
+
+	reportFile, err := os.CreateTemp("", "dun-node-test-*.json")
+	if err != nil {
+		return CheckResult{}, err
+	}
+	reportPath := reportFile.Name()
+	reportFile.Close()
+	defer os.Remove(reportPath)
+
+	args := nodeTestArgs(name, tool.Args, reportPath)
+	command := toolCommandLine(name, tool.Args...)
+	run, err := runToolCommand(root, config.Timeout, config.Env, tool.Path, args...)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if run.TimedOut {
+		return CheckResult{ID: def.ID, Status: "fail", Signal: name + " timed out", Next: command}, nil
+	}
+
+	var report jestReport
+	data, _ := os.ReadFile(reportPath)
+	if err := json.Unmarshal(data, &report); err != nil {
+		return toolExitResult(def, run, command, name), nil
+	}
+	issues := jestIssues(root, report)
+	if len(issues) == 0 && run.ExitCode == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: fmt.Sprintf("%d tests passed", report.NumTotalTests),
+		}, nil
+	}
+	if len(issues) == 0 {
+		return toolExitResult(def, run, command, name), nil
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("%d of %d tests failed", len(issues), report.NumTotalTests),
+		Detail: issues[0].Summary,
+		Next:   command,
+		Issues: issues,
+	}, nil
+}
+
+func nodeTestArgs(tool string, scriptArgs []string, reportPath string) []string {
+	var args []string
+	for _, arg := range scriptArgs {
+		if arg != "--watch" && arg != "watch" && arg != "--watchAll" {
+			args = append(args, arg)
+		}
+	}
+	if tool == "vitest" {
+		if len(args) == 0 || args[0] != "run" {
+			args = append([]string{"run"}, args...)
+		}
+		return append(args, "--reporter=json", "--outputFile="+reportPath)
+	}
+	return append(args, "--json", "--outputFile="+reportPath)
+}
+
+// jestIssues reports each failed test, and suites that failed to run.
+func jestIssues(root string, report jestReport) []Issue {
+	var issues []Issue
+	for _, suite := range report.TestResults {
+		path := toolRelPath(root, suite.Name)
+		failed := 0
+		for _, test := range suite.AssertionResults {
+			if test.Status != "failed" {
+				continue
+			}
+			failed++
+			issue := Issue{
+				ID:       "test:" + path + ":" + test.FullName,
+				Summary:  test.FullName,
+				Path:     path,
+				Severity: "fail",
+			}
+			if test.Location != nil {
+				issue.Line = test.Location.Line
+			}
+			if len(test.FailureMessages) > 0 {
+				issue.Detail = trimOutput([]byte(test.FailureMessages[0]))
+			}
+			issues = append(issues, issue)
+		}
+		if failed == 0 && suite.Status == "failed" {
+			issues = append(issues, Issue{
+				ID:       "test:" + path,
+				Summary:  "test suite failed to run",
+				Path:     path,
+				Severity: "fail",
+				Detail:   trimOutput([]byte(suite.Message)),
+			})
+		}
+	}
+	return issues
+}
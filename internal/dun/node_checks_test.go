@@ -0,0 +1,182 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func nodeRepo(t *testing.T, packageJSON string) string {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "package.json"), packageJSON)
+	return root
+}
+
+// writeNodeBin installs a stub tool in node_modules/.bin.
+func writeNodeBin(t *testing.T, root string, name string, script string) {
+	t.Helper()
+	dir := filepath.Join(root, "node_modules", ".bin")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatalf("write stub: %v", err)
+	}
+}
+
+func TestDetectNodePackageManager(t *testing.T) {
+	root := t.TempDir()
+	if got := detectNodePackageManager(root, ""); got != "npm" {
+		t.Fatalf("expected npm default, got %s", got)
+	}
+	writeFile(t, filepath.Join(root, "yarn.lock"), "")
+	if got := detectNodePackageManager(root, ""); got != "yarn" {
+		t.Fatalf("expected yarn from lockfile, got %s", got)
+	}
+	writeFile(t, filepath.Join(root, "pnpm-lock.yaml"), "")
+	if got := detectNodePackageManager(root, ""); got != "pnpm" {
+		t.Fatalf("expected pnpm from lockfile, got %s", got)
+	}
+	if got := detectNodePackageManager(root, "bun@1.1.0"); got != "bun" {
+		t.Fatalf("expected packageManager field to win, got %s", got)
+	}
+}
+
+func TestNodeTypecheckParsesTsc(t *testing.T) {
+	root := nodeRepo(t, `{"scripts": {"typecheck": "tsc --noEmit -p tsconfig.build.json"}}`)
+	argsFile := filepath.Join(root, "args.txt")
+	writeNodeBin(t, root, "tsc", "echo \"$*\" > "+argsFile+"\n"+
+		"echo 'src/app.ts(12,5): error TS2322: Type string is not assignable to type number.'\n"+
+		"echo 'src/util.ts(3,1): error TS2304: Cannot find name foo.'\nexit 2\n")
+
+	res, err := runNodeTypecheck(root, CheckDefinition{ID: "node-typecheck"}, NodeCheckConfig{})
+	if err != nil {
+		t.Fatalf("typecheck: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 2 {
+		t.Fatalf("expected 2 issues, got %s %+v", res.Status, res.Issues)
+	}
+	issue := res.Issues[0]
+	if issue.Path != "src/app.ts" || issue.Line != 12 || issue.ID != "tsc:src/app.ts:12:TS2322" || !strings.HasPrefix(issue.Summary, "TS2322: ") {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if string(args) != "--noEmit -p tsconfig.build.json --pretty false\n" {
+		t.Fatalf("unexpected args %q", string(args))
+	}
+}
+
+func TestNodeTypecheckSkipsWithInstallHint(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	root := nodeRepo(t, `{"devDependencies": {"eslint": "^9"}}`)
+	writeFile(t, filepath.Join(root, "pnpm-lock.yaml"), "")
+	writeFile(t, filepath.Join(root, "tsconfig.json"), "{}")
+
+	res, err := runNodeTypecheck(root, CheckDefinition{ID: "node-typecheck"}, NodeCheckConfig{})
+	if err != nil {
+		t.Fatalf("typecheck: %v", err)
+	}
+	if res.Status != "skip" || res.Next != "pnpm add -D typescript" {
+		t.Fatalf("expected skip with install hint, got %+v", res)
+	}
+
+	res, err = runNodeLint(root, CheckDefinition{ID: "node-lint"}, NodeCheckConfig{})
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	if res.Status != "skip" || res.Next != "pnpm install" {
+		t.Fatalf("expected skip asking to install dependencies, got %+v", res)
+	}
+}
+
+func TestNodeLintParsesEslintJSON(t *testing.T) {
+	root := nodeRepo(t, `{"scripts": {"lint": "eslint src"}}`)
+	output := `[{"filePath":"` + filepath.Join(root, "src", "app.ts") + `","messages":[` +
+		`{"ruleId":"no-unused-vars","severity":2,"message":"'x' is defined but never used.","line":4},` +
+		`{"ruleId":"eqeqeq","severity":1,"message":"Expected '==='.","line":9}]},` +
+		`{"filePath":"` + filepath.Join(root, "src", "ok.ts") + `","messages":[]}]`
+	writeNodeBin(t, root, "eslint", "echo '"+output+"'\nexit 1\n")
+
+	res, err := runNodeLint(root, CheckDefinition{ID: "node-lint"}, NodeCheckConfig{})
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	if res.Status != "fail" || res.Signal != "eslint reported 1 errors, 1 warnings" {
+		t.Fatalf("unexpected result %s %q", res.Status, res.Signal)
+	}
+	first := res.Issues[0]
+	if first.ID != "eslint:src/app.ts:4:no-unused-vars" || first.Line != 4 || first.Severity != "fail" {
+		t.Fatalf("unexpected issue %+v", first)
+	}
+	if res.Issues[1].Severity != "warn" || res.Next != "eslint src" {
+		t.Fatalf("unexpected warning issue %+v next %q", res.Issues[1], res.Next)
+	}
+}
+
+func TestNodeTestParsesJestReport(t *testing.T) {
+	root := nodeRepo(t, `{"scripts": {"test": "jest --watch"}, "devDependencies": {"jest": "^29"}}`)
+	report := `{"numTotalTests":3,"numFailedTests":1,"testResults":[` +
+		`{"name":"` + filepath.Join(root, "src", "sum.test.ts") + `","status":"failed","assertionResults":[` +
+		`{"fullName":"sum adds numbers","status":"failed","failureMessages":["expected 3, got 4"],"location":{"line":7,"column":3}},` +
+		`{"fullName":"sum handles zero","status":"passed"}]},` +
+		`{"name":"` + filepath.Join(root, "src", "broken.test.ts") + `","status":"failed","message":"SyntaxError: Unexpected token","assertionResults":[]}]}`
+	argsFile := filepath.Join(root, "args.txt")
+	script := "echo \"$*\" > " + argsFile + "\n" +
+		"for arg in \"$@\"; do case $arg in --outputFile=*) out=${arg#--outputFile=};; esac; done\n" +
+		"echo '" + report + "' > \"$out\"\nexit 1\n"
+	writeNodeBin(t, root, "jest", script)
+
+	res, err := runNodeTest(root, CheckDefinition{ID: "node-test"}, NodeCheckConfig{})
+	if err != nil {
+		t.Fatalf("test: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 2 {
+		t.Fatalf("expected 2 issues, got %s %+v", res.Status, res.Issues)
+	}
+	failed := res.Issues[0]
+	if failed.Path != "src/sum.test.ts" || failed.Line != 7 || failed.Summary != "sum adds numbers" || failed.Detail != "expected 3, got 4" {
+		t.Fatalf("unexpected issue %+v", failed)
+	}
+	if res.Issues[1].Summary != "test suite failed to run" {
+		t.Fatalf("unexpected suite issue %+v", res.Issues[1])
+	}
+	args, _ := os.ReadFile(argsFile)
+	if !strings.HasPrefix(string(args), "--json --outputFile=") {
+		t.Fatalf("expected --watch dropped and json reporter, got %q", string(args))
+	}
+}
+
+func TestNodeTestVitestArgs(t *testing.T) {
+	args := nodeTestArgs("vitest", []string{"--coverage"}, "/tmp/out.json")
+	if strings.Join(args, " ") != "run --coverage --reporter=json --outputFile=/tmp/out.json" {
+		t.Fatalf("unexpected vitest args %v", args)
+	}
+}
+
+func TestNodeTestRunsOtherScriptsThroughPackageManager(t *testing.T) {
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "npm"), []byte("#!/bin/sh\necho \"npm $*\"\nexit 1\n"), 0755); err != nil {
+		t.Fatalf("write stub: %v", err)
+	}
+	t.Setenv("PATH", binDir)
+	root := nodeRepo(t, `{"scripts": {"test": "mocha"}}`)
+
+	res, err := runNodeTest(root, CheckDefinition{ID: "node-test"}, NodeCheckConfig{})
+	if err != nil {
+		t.Fatalf("test: %v", err)
+	}
+	if res.Status != "fail" || res.Detail != "npm run test" || res.Next != "npm run test" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	root = nodeRepo(t, `{"scripts": {"test": "echo \"Error: no test specified\" && exit 1"}}`)
+	res, err = runNodeTest(root, CheckDefinition{ID: "node-test"}, NodeCheckConfig{})
+	if err != nil {
+		t.Fatalf("test: %v", err)
+	}
+	if res.Status != "skip" || res.Next != "npm install --save-dev vitest" {
+		t.Fatalf("expected skip for placeholder script, got %+v", res)
+	}
+}
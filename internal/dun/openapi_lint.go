@@ -0,0 +1,316 @@
+package dun
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// openAPIOperation is one path item operation of an OpenAPI document.
+type openAPIOperation struct {
+	ID     string
+	Method string
+	Path   string
+	Node   *yaml.Node
+}
+
+func runOpenAPILint(root string, def CheckDefinition, config OpenAPILintConfig) (CheckResult, error) {
+	files, err := contractFiles(root, config.Files, isOpenAPIFile)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if len(files) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "skip",
+			Signal: "no OpenAPI documents",
+		}, nil
+	}
+
+	var issues []Issue
+	for _, file := range files {
+		doc, err := loadOpenAPIDocument(filepath.Join(root, file))
+		if err != nil {
+			issues = append(issues, Issue{
+				ID:       "openapi:" + file + ":0:parse",
+				Summary:  err.Error(),
+				Path:     file,
+				Severity: "fail",
+			})
+			continue
+		}
+		issues = append(issues, lintOpenAPIDocument(root, file, doc)...)
+	}
+	return contractLintResult(def, "OpenAPI", len(files), issues), nil
+}
+
+// contractFiles resolves globs relative to root. Without globs it walks the
+// tree and keeps files accepted by detect.
+func contractFiles(root string, globs []string, detect func(path string) bool) ([]string, error) {
+	seen := map[string]bool{}
+	if len(globs) > 0 {
+		for _, glob := range globs {
+			matches, err := filepath.Glob(filepath.Join(root, glob))
+			if err != nil {
+				return nil, err
+			}
+			for _, match := range matches {
+				seen[toolRelPath(root, match)] = true
+			}
+		}
+	} else {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if skipTreeDir(root, path) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if detect(path) {
+				seen[toolRelPath(root, path)] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	files := make([]string, 0, len(seen))
+	for file := range seen {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// isOpenAPIFile reports whether path is a YAML or JSON file declaring
+// "openapi: 3.x" at the top level.
+func isOpenAPIFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var head struct {
+		OpenAPI string `yaml:"openapi"`
+	}
+	if yaml.Unmarshal(data, &head) != nil {
+		return false
+	}
+	return strings.HasPrefix(head.OpenAPI, "3")
+}
+
+// loadOpenAPIDocument parses a YAML or JSON OpenAPI 3 document and returns
+// its top-level mapping node.
+func loadOpenAPIDocument(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: not an OpenAPI document", filepath.Base(path))
+	}
+	top := doc.Content[0]
+	version := mappingValue(top, "openapi")
+	if version == nil || !strings.HasPrefix(version.Value, "3") {
+		return nil, fmt.Errorf("%s: not an OpenAPI 3 document", filepath.Base(path))
+	}
+	return top, nil
+}
+
+// openAPIOperations lists the operations under paths, in document order.
+func openAPIOperations(doc *yaml.Node) []openAPIOperation {
+	var ops []openAPIOperation
+	paths := mappingValue(doc, "paths")
+	if paths == nil || paths.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		path := paths.Content[i].Value
+		item := paths.Content[i+1]
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			method := item.Content[j].Value
+			if !slices.Contains(openAPIMethods, method) {
+				continue
+			}
+			op := openAPIOperation{Method: strings.ToUpper(method), Path: path, Node: item.Content[j+1]}
+			if id := mappingValue(op.Node, "operationId"); id != nil {
+				op.ID = id.Value
+			}
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// lintOpenAPIDocument reports unresolved $refs, duplicate operationIds and
+// responses without schemas.
+func lintOpenAPIDocument(root string, file string, doc *yaml.Node) []Issue {
+	var issues []Issue
+	add := func(node *yaml.Node, rule string, severity string, summary string) {
+		issues = append(issues, Issue{
+			ID:       fmt.Sprintf("openapi:%s:%d:%s", file, node.Line, rule),
+			Summary:  summary,
+			Path:     file,
+			Line:     node.Line,
+			Severity: severity,
+		})
+	}
+
+	walkYAMLRefs(doc, func(ref *yaml.Node) {
+		if !resolveOpenAPIRef(root, file, doc, ref.Value) {
+			add(ref, "unresolved-ref", "fail", fmt.Sprintf("unresolved $ref %q", ref.Value))
+		}
+	})
+
+	seen := map[string]bool{}
+	for _, op := range openAPIOperations(doc) {
+		name := op.Method + " " + op.Path
+		if op.ID != "" {
+			if seen[op.ID] {
+				add(mappingValue(op.Node, "operationId"), "duplicate-operation-id", "fail", fmt.Sprintf("duplicate operationId %q (%s)", op.ID, name))
+			}
+			seen[op.ID] = true
+		}
+		responses := mappingValue(op.Node, "responses")
+		if responses == nil || responses.Kind != yaml.MappingNode || len(responses.Content) == 0 {
+			add(op.Node, "missing-responses", "fail", name+": no responses")
+			continue
+		}
+		for i := 0; i+1 < len(responses.Content); i += 2 {
+			code := responses.Content[i].Value
+			response := responses.Content[i+1]
+			if mappingValue(response, "$ref") != nil {
+				continue
+			}
+			content := mappingValue(response, "content")
+			if content == nil || content.Kind != yaml.MappingNode || len(content.Content) == 0 {
+				if strings.HasPrefix(code, "2") && code != "204" {
+					add(responses.Content[i], "missing-response-schema", "warn", fmt.Sprintf("%s: %s response has no content schema", name, code))
+				}
+				continue
+			}
+			for j := 0; j+1 < len(content.Content); j += 2 {
+				if mappingValue(content.Content[j+1], "schema") == nil {
+					add(content.Content[j], "missing-response-schema", "warn", fmt.Sprintf("%s: %s %s response has no schema", name, code, content.Content[j].Value))
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// walkYAMLRefs calls fn with the value node of every "$ref" key.
+func walkYAMLRefs(node *yaml.Node, fn func(ref *yaml.Node)) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "$ref" && node.Content[i+1].Kind == yaml.ScalarNode {
+				fn(node.Content[i+1])
+				continue
+			}
+			walkYAMLRefs(node.Content[i+1], fn)
+		}
+		return
+	}
+	for _, child := range node.Content {
+		walkYAMLRefs(child, fn)
+	}
+}
+
+// resolveOpenAPIRef resolves local (#/a/b) and relative file (other.yaml#/a)
+// references. Remote URLs are not fetched and count as resolved.
+func resolveOpenAPIRef(root string, file string, doc *yaml.Node, ref string) bool {
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		return true
+	}
+	target, pointer, _ := strings.Cut(ref, "#")
+	if target != "" {
+		path := filepath.Join(root, filepath.Dir(file), target)
+		if pointer == "" {
+			return fileExists(path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return false
+		}
+		var external yaml.Node
+		if yaml.Unmarshal(data, &external) != nil || len(external.Content) == 0 {
+			return false
+		}
+		doc = external.Content[0]
+	}
+	return yamlPointer(doc, pointer) != nil
+}
+
+// yamlPointer follows a JSON pointer such as "/components/schemas/Pet".
+func yamlPointer(node *yaml.Node, pointer string) *yaml.Node {
+	if pointer == "" || pointer == "/" {
+		return node
+	}
+	for _, part := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		switch node.Kind {
+		case yaml.MappingNode:
+			node = mappingValue(node, part)
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[index]
+		default:
+			return nil
+		}
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// contractLintResult summarizes lint issues: any fail issue fails the check,
+// warnings alone warn.
+func contractLintResult(def CheckDefinition, kind string, files int, issues []Issue) CheckResult {
+	if len(issues) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: fmt.Sprintf("%d %s files passed", files, kind),
+		}
+	}
+	status := "warn"
+	for _, issue := range issues {
+		if issue.Severity == "fail" {
+			status = "fail"
+			break
+		}
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: status,
+		Signal: fmt.Sprintf("%d %s problems", len(issues), kind),
+		Detail: fmt.Sprintf("%s:%d: %s", issues[0].Path, issues[0].Line, issues[0].Summary),
+		Next:   "Fix the reported " + kind + " definitions.",
+		Issues: issues,
+	}
+}
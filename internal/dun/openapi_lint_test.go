@@ -0,0 +1,118 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testOpenAPISpec = `openapi: 3.0.3
+info:
+  title: Pets
+  version: "1"
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pets"
+    post:
+      operationId: listPets
+      responses:
+        "201":
+          description: created
+  /pets/{id}:
+    get:
+      operationId: getPet
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json: {}
+        "404":
+          $ref: "#/components/responses/NotFound"
+components:
+  schemas:
+    Pet:
+      type: object
+  responses:
+    NotFound:
+      description: missing
+`
+
+func TestLintOpenAPIDocument(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "openapi.yaml"), testOpenAPISpec)
+	doc, err := loadOpenAPIDocument(filepath.Join(root, "openapi.yaml"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	issues := lintOpenAPIDocument(root, "openapi.yaml", doc)
+	want := []string{
+		"openapi:openapi.yaml:15:unresolved-ref",
+		"openapi:openapi.yaml:17:duplicate-operation-id",
+		"openapi:openapi.yaml:19:missing-response-schema",
+		"openapi:openapi.yaml:28:missing-response-schema",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %+v", len(want), issues)
+	}
+	for i, id := range want {
+		if issues[i].ID != id {
+			t.Fatalf("issue %d: expected %s, got %s", i, id, issues[i].ID)
+		}
+	}
+}
+
+func TestResolveOpenAPIRefExternalFile(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "api"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, filepath.Join(root, "api", "schemas.yaml"), "Pet:\n  type: object\n")
+	writeFile(t, filepath.Join(root, "api", "openapi.yaml"), "openapi: 3.1.0\n")
+	doc, err := loadOpenAPIDocument(filepath.Join(root, "api", "openapi.yaml"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !resolveOpenAPIRef(root, "api/openapi.yaml", doc, "schemas.yaml#/Pet") {
+		t.Fatalf("expected external ref to resolve")
+	}
+	if resolveOpenAPIRef(root, "api/openapi.yaml", doc, "schemas.yaml#/Dog") {
+		t.Fatalf("expected missing pointer to fail")
+	}
+	if resolveOpenAPIRef(root, "api/openapi.yaml", doc, "missing.yaml") {
+		t.Fatalf("expected missing file to fail")
+	}
+}
+
+func TestOpenAPILintCheckDiscoversDocuments(t *testing.T) {
+	root := t.TempDir()
+	res, err := runOpenAPILint(root, CheckDefinition{ID: "openapi-lint"}, OpenAPILintConfig{})
+	if err != nil || res.Status != "skip" {
+		t.Fatalf("expected skip, got %+v %v", res, err)
+	}
+
+	writeFile(t, filepath.Join(root, "config.yaml"), "name: not-openapi\n")
+	writeFile(t, filepath.Join(root, "api.json"), `{"openapi":"3.0.0","paths":{"/x":{"get":{"operationId":"x","responses":{"204":{"description":"none"}}}}}}`)
+	res, err = runOpenAPILint(root, CheckDefinition{ID: "openapi-lint"}, OpenAPILintConfig{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Status != "pass" || res.Signal != "1 OpenAPI files passed" {
+		t.Fatalf("expected pass for one document, got %+v", res)
+	}
+
+	res, err = runOpenAPILint(root, CheckDefinition{ID: "openapi-lint"}, OpenAPILintConfig{Files: []string{"*.yaml"}})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Status != "fail" || res.Issues[0].ID != "openapi:config.yaml:0:parse" {
+		t.Fatalf("expected explicit non-OpenAPI file to fail, got %+v", res)
+	}
+}
@@ -6,7 +6,7 @@ import (
 )
 
 func TestPlanRepoIncludesHelixChecks(t *testing.T) {
-	plan, err := PlanRepo(fixturePath(t, "../testdata/repos/helix-missing-architecture"))
+	plan, err := PlanRepo(fixturePath(t, "../testdata/repos/helix-missing-architecture"), DefaultOptions())
 	if err != nil {
 		t.Fatalf("plan repo: %v", err)
 	}
@@ -36,7 +36,7 @@ func planHas(plan Plan, id string) bool {
 func TestHelixPluginInactiveWithoutDocsHelix(t *testing.T) {
 	root := tempGitRepo(t)
 	// No docs/helix/ directory - Helix plugin should not activate
-	plan, err := PlanRepo(root)
+	plan, err := PlanRepo(root, DefaultOptions())
 	if err != nil {
 		t.Fatalf("plan repo: %v", err)
 	}
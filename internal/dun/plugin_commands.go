@@ -0,0 +1,167 @@
+package dun
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"text/template"
+)
+
+// PluginCLICommand is a command contributed by a loaded plugin.
+type PluginCLICommand struct {
+	PluginID string
+	Command  PluginCommand
+	Plugin   Plugin
+}
+
+// PluginCommandIO connects a plugin command to the terminal.
+type PluginCommandIO struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// pluginCommandData is what a run template sees. Arguments are deliberately
+// absent: they reach the script as "$@", so the shell never parses them.
+type pluginCommandData struct {
+	Root      string
+	PluginDir string
+}
+
+// LoadActivePlugins loads the plugins for root as a check run does: it
+// enforces plugins.lock according to opts.PluginIntegrity and keeps only the
+// plugins whose triggers match root.
+func LoadActivePlugins(root string, opts Options) ([]Plugin, error) {
+	plugins, err := loadPlugins(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := enforcePluginLock(root, plugins, opts.PluginIntegrity); err != nil {
+		return nil, err
+	}
+	return filterActivePlugins(root, plugins), nil
+}
+
+// CollectPluginCommands lists the commands of plugins, ordered by plugin ID
+// and then as declared.
+func CollectPluginCommands(plugins []Plugin) []PluginCLICommand {
+	var commands []PluginCLICommand
+	for _, p := range plugins {
+		for _, c := range p.Manifest.Commands {
+			commands = append(commands, PluginCLICommand{PluginID: p.Manifest.ID, Command: c, Plugin: p})
+		}
+	}
+	sort.SliceStable(commands, func(i, j int) bool { return commands[i].PluginID < commands[j].PluginID })
+	return commands
+}
+
+// CollectPromptPartials lists the qualified names ("<plugin-id>/<name>") of
+// the prompt partials plugins provide, sorted.
+func CollectPromptPartials(plugins []Plugin) []string {
+	var names []string
+	for _, p := range plugins {
+		for name := range p.Manifest.Partials {
+			names = append(names, p.Manifest.ID+"/"+name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// RunPluginCommand runs a plugin command in root and returns its exit code.
+func RunPluginCommand(root string, c PluginCLICommand, args []string, stdio PluginCommandIO) (int, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return -1, err
+	}
+	var cmd *exec.Cmd
+	switch {
+	case c.Command.Exec != "":
+		if c.Plugin.Dir == "" {
+			return -1, fmt.Errorf("command %s %s: exec needs a plugin directory", c.PluginID, c.Command.Name)
+		}
+		bin, err := pluginFilePath(c.Plugin.Dir, c.Command.Exec)
+		if err != nil {
+			return -1, fmt.Errorf("command %s %s: %w", c.PluginID, c.Command.Name, err)
+		}
+		if bin, err = filepath.Abs(bin); err != nil {
+			return -1, err
+		}
+		cmd = exec.Command(bin, args...)
+	case c.Command.Run != "":
+		script, err := renderPluginCommand(c, pluginCommandData{Root: absRoot, PluginDir: c.Plugin.Dir})
+		if err != nil {
+			return -1, err
+		}
+		cmd = exec.Command("sh", append([]string{"-c", script, c.PluginID + "-" + c.Command.Name}, args...)...)
+	default:
+		return -1, fmt.Errorf("command %s %s: needs run or exec", c.PluginID, c.Command.Name)
+	}
+	cmd.Dir = absRoot
+	cmd.Env = append(os.Environ(), "DUN_ROOT="+absRoot)
+	if c.Plugin.Dir != "" {
+		cmd.Env = append(cmd.Env, "DUN_PLUGIN_DIR="+c.Plugin.Dir)
+	}
+	cmd.Stdin = stdio.Stdin
+	cmd.Stdout = stdio.Stdout
+	cmd.Stderr = stdio.Stderr
+	err = cmd.Run()
+	if code := exitCodeFromError(err); code != -1 {
+		return code, nil
+	}
+	return -1, err
+}
+
+func renderPluginCommand(c PluginCLICommand, data pluginCommandData) (string, error) {
+	tmpl, err := template.New(c.Command.Name).Option("missingkey=error").Parse(c.Command.Run)
+	if err != nil {
+		return "", fmt.Errorf("command %s %s: %w", c.PluginID, c.Command.Name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("command %s %s: %w", c.PluginID, c.Command.Name, err)
+	}
+	return buf.String(), nil
+}
+
+// promptPartial is a partial template file inside a plugin.
+type promptPartial struct {
+	FS   fs.FS
+	Path string
+}
+
+// withPromptPartials gives every plugin of a loaded set the partials of the
+// whole set, so their prompts can use each other's partials.
+func withPromptPartials(plugins []Plugin) []Plugin {
+	partials := map[string]promptPartial{}
+	for _, p := range plugins {
+		for name, file := range p.Manifest.Partials {
+			partials[p.Manifest.ID+"/"+name] = promptPartial{FS: p.FS, Path: path.Join(p.Base, file)}
+		}
+	}
+	for i := range plugins {
+		plugins[i].partials = partials
+	}
+	return plugins
+}
+
+// addPromptPartials parses partials into tmpl as named templates.
+func addPromptPartials(tmpl *template.Template, partials map[string]promptPartial) error {
+	for name, partial := range partials {
+		raw, err := fs.ReadFile(partial.FS, partial.Path)
+		if err != nil {
+			return fmt.Errorf("prompt partial %s: %w", name, err)
+		}
+		if _, err := tmpl.New(name).Parse(string(raw)); err != nil {
+			return fmt.Errorf("prompt partial %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package dun
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunPluginCommandRendersRun(t *testing.T) {
+	root := t.TempDir()
+	c := PluginCLICommand{
+		PluginID: "helix",
+		Command:  PluginCommand{Name: "phase", Run: `echo "root={{.Root}} n=$#" "$@"; exit 3`},
+	}
+	var stdout bytes.Buffer
+	code, err := RunPluginCommand(root, c, []string{"a b", "c"}, PluginCommandIO{Stdout: &stdout, Stderr: &stdout})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 3 {
+		t.Fatalf("expected exit code 3, got %d", code)
+	}
+	if got := strings.TrimSpace(stdout.String()); got != "root="+root+" n=2 a b c" {
+		t.Fatalf("unexpected output %q", got)
+	}
+
+	c.Command.Run = "{{.Missing}}"
+	if _, err := RunPluginCommand(root, c, nil, PluginCommandIO{}); err == nil {
+		t.Fatalf("expected template error")
+	}
+	// Arguments are never spliced into the script text.
+	c.Command.Run = "echo {{index .Args 0}}"
+	if _, err := RunPluginCommand(root, c, []string{"$(touch pwned)"}, PluginCommandIO{}); err == nil {
+		t.Fatalf("expected .Args to be unavailable")
+	}
+}
+
+func TestRunPluginCommandExec(t *testing.T) {
+	root := t.TempDir()
+	pluginDir := t.TempDir()
+	writeFile(t, filepath.Join(pluginDir, "story.sh"), "#!/bin/sh\necho \"$DUN_ROOT $PWD $1\"\n")
+	if err := os.Chmod(filepath.Join(pluginDir, "story.sh"), 0755); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	c := PluginCLICommand{
+		PluginID: "helix",
+		Command:  PluginCommand{Name: "new-story", Exec: "story.sh"},
+		Plugin:   Plugin{Dir: pluginDir},
+	}
+	var stdout bytes.Buffer
+	code, err := RunPluginCommand(root, c, []string{"login"}, PluginCommandIO{Stdout: &stdout})
+	if err != nil || code != 0 {
+		t.Fatalf("run: %d %v", code, err)
+	}
+	if got := strings.TrimSpace(stdout.String()); got != root+" "+root+" login" {
+		t.Fatalf("unexpected output %q", got)
+	}
+
+	c.Plugin.Dir = ""
+	if _, err := RunPluginCommand(root, c, nil, PluginCommandIO{}); err == nil {
+		t.Fatalf("expected exec without plugin directory to fail")
+	}
+}
+
+func TestPromptPartialsAreSharedAcrossPlugins(t *testing.T) {
+	styleDir := t.TempDir()
+	writeFile(t, filepath.Join(styleDir, "tone.md"), "Be terse about {{.CheckID}}.")
+	style := Plugin{Manifest: Manifest{ID: "style", Partials: map[string]string{"tone": "tone.md"}}, FS: os.DirFS(styleDir), Base: "."}
+
+	reviewDir := t.TempDir()
+	writeFile(t, filepath.Join(reviewDir, "review.md"), `Review. {{ template "style/tone" . }}`)
+	review := Plugin{Manifest: Manifest{ID: "review"}, FS: os.DirFS(reviewDir), Base: "."}
+	if _, _, err := renderPromptText(reviewDir, review, AgentCheckConfig{Prompt: "review.md"}, "review-docs", nil, "auto"); err == nil {
+		t.Fatalf("expected a plugin outside the set not to see the partial")
+	}
+
+	review = withPromptPartials([]Plugin{style, review})[1]
+	text, _, err := renderPromptText(reviewDir, review, AgentCheckConfig{Prompt: "review.md"}, "review-docs", nil, "auto")
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if text != "Review. Be terse about review-docs." {
+		t.Fatalf("unexpected prompt %q", text)
+	}
+
+	if got := CollectPromptPartials([]Plugin{style}); len(got) != 1 || got[0] != "style/tone" {
+		t.Fatalf("unexpected partials %v", got)
+	}
+}
+
+func TestLoadPluginWithOnlyCommands(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), `id: tools
+version: "1"
+commands:
+  - name: hello
+    description: Say hello
+    run: echo hello
+`)
+	p, err := loadPluginFromPath(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	commands := CollectPluginCommands([]Plugin{p})
+	if len(commands) != 1 || commands[0].PluginID != "tools" || commands[0].Command.Description != "Say hello" {
+		t.Fatalf("unexpected commands %+v", commands)
+	}
+}
+
+func TestLintPluginCommandsAndPartials(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), `id: tools
+version: "1"
+commands:
+  - name: both
+    run: echo
+    exec: bin/tool
+  - name: gone
+    exec: bin/missing
+  - description: no name
+    run: echo
+partials:
+  tone: partials/tone.md
+`)
+	issues, err := LintPlugin(dir)
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	want := []string{
+		"plugin:plugin.yaml:4:invalid-command",
+		"plugin:plugin.yaml:7:missing-file",
+		"plugin:plugin.yaml:9:invalid-command",
+		"plugin:plugin.yaml:12:missing-file",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %+v", len(want), issues)
+	}
+	for i, id := range want {
+		if issues[i].ID != id {
+			t.Fatalf("issue %d: expected %s, got %s", i, id, issues[i].ID)
+		}
+	}
+}
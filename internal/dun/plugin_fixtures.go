@@ -0,0 +1,175 @@
+package dun
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Plugin fixture outcomes.
+const (
+	PluginTestPass    = "pass"
+	PluginTestFail    = "fail"
+	PluginTestUpdated = "updated"
+)
+
+// PluginTestCase is the outcome of running a plugin against one fixture repo
+// under <plugin>/testdata/<case>/repo.
+type PluginTestCase struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Diff   string `json:"diff,omitempty"`
+}
+
+// PluginTestOptions controls RunPluginTests.
+type PluginTestOptions struct {
+	Update bool   // Rewrite expected.json instead of comparing
+	Case   string // Run only this case
+}
+
+var volatileDuration = regexp.MustCompile(`\b\d+(\.\d+)?(ns|µs|us|ms)\b|\b\d+\.\d+s\b`)
+
+// RunPluginTests runs the checks of the plugin in dir against each fixture
+// repo under dir/testdata/<case>/repo and compares the normalized JSON result
+// with dir/testdata/<case>/expected.json. Fixtures are copied to a temporary
+// directory first so checks cannot modify them.
+func RunPluginTests(dir string, opts PluginTestOptions) ([]PluginTestCase, error) {
+	plugin, err := loadPluginFromPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := registerPluginCheckTypes([]Plugin{plugin}); err != nil {
+		return nil, err
+	}
+	plugin = withPromptPartials([]Plugin{plugin})[0]
+	entries, err := os.ReadDir(filepath.Join(dir, "testdata"))
+	if err != nil {
+		return nil, fmt.Errorf("no fixtures: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && fileExists(filepath.Join(dir, "testdata", entry.Name(), "repo")) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	if opts.Case != "" {
+		if !slices.Contains(names, opts.Case) {
+			return nil, fmt.Errorf("fixture %s not found", opts.Case)
+		}
+		names = []string{opts.Case}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no fixtures under %s", filepath.Join(dir, "testdata"))
+	}
+
+	var cases []PluginTestCase
+	for _, name := range names {
+		caseDir := filepath.Join(dir, "testdata", name)
+		got, err := runPluginFixture(plugin, filepath.Join(caseDir, "repo"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		expectedPath := filepath.Join(caseDir, "expected.json")
+		if opts.Update {
+			if err := os.WriteFile(expectedPath, got, 0644); err != nil {
+				return nil, err
+			}
+			cases = append(cases, PluginTestCase{Name: name, Status: PluginTestUpdated})
+			continue
+		}
+		want, err := os.ReadFile(expectedPath)
+		if err != nil {
+			cases = append(cases, PluginTestCase{Name: name, Status: PluginTestFail, Diff: "missing expected.json (run with --update)"})
+			continue
+		}
+		if bytes.Equal(want, got) {
+			cases = append(cases, PluginTestCase{Name: name, Status: PluginTestPass})
+			continue
+		}
+		cases = append(cases, PluginTestCase{Name: name, Status: PluginTestFail, Diff: firstLineDiff(string(want), string(got))})
+	}
+	return cases, nil
+}
+
+// runPluginFixture runs plugin against a copy of repo and returns the
+// normalized, indented JSON result.
+func runPluginFixture(plugin Plugin, repo string) ([]byte, error) {
+	root, err := os.MkdirTemp("", "dun-fixture-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(root)
+	if err := copyTree(repo, root); err != nil {
+		return nil, err
+	}
+
+	defer closeWasmModules()
+	plan, err := buildPlan(root, filterActivePlugins(root, []Plugin{plugin}), DefaultOptions(), true)
+	if err != nil {
+		return nil, err
+	}
+	sortPlan(plan)
+	result := Result{Checks: []CheckResult{}}
+	for _, pc := range plan {
+		res, err := runCheck(root, pc, DefaultOptions())
+		if err != nil {
+			return nil, err
+		}
+		result.Checks = append(result.Checks, res)
+	}
+
+	raw, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(normalizeFixtureOutput(raw, root), '\n'), nil
+}
+
+// normalizeFixtureOutput replaces values that change between runs: the
+// temporary repo path, the home directory and durations.
+func normalizeFixtureOutput(raw []byte, root string) []byte {
+	out := string(raw)
+	roots := []string{root}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil && resolved != root {
+		roots = append(roots, resolved)
+	}
+	for _, r := range roots {
+		out = strings.ReplaceAll(out, jsonEscape(r), "$ROOT")
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" && home != "/" {
+		out = strings.ReplaceAll(out, jsonEscape(home), "$HOME")
+	}
+	out = volatileDuration.ReplaceAllString(out, "<duration>")
+	return []byte(out)
+}
+
+func jsonEscape(s string) string {
+	raw, _ := json.Marshal(s)
+	return strings.Trim(string(raw), `"`)
+}
+
+// firstLineDiff describes the first line that differs between want and got.
+func firstLineDiff(want string, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("expected.json line %d:\n- %s\n+ %s", i+1, w, g)
+		}
+	}
+	return ""
+}
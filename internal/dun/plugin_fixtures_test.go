@@ -0,0 +1,94 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunPluginTestsUpdateThenCompare(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "fixture-plugin")
+	for _, sub := range []string{"testdata/has-readme/repo", "testdata/empty/repo"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), `id: fixture-plugin
+version: "1"
+checks:
+  - id: readme
+    type: rule-set
+    rules:
+      - type: path-exists
+        path: README.md
+  - id: where
+    type: command
+    command: pwd
+`)
+	writeFile(t, filepath.Join(dir, "testdata", "has-readme", "repo", "README.md"), "# hi\n")
+
+	cases, err := RunPluginTests(dir, PluginTestOptions{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(cases) != 2 || cases[0].Name != "empty" || cases[0].Status != PluginTestFail || !strings.Contains(cases[0].Diff, "--update") {
+		t.Fatalf("expected missing golden files to fail, got %+v", cases)
+	}
+
+	cases, err = RunPluginTests(dir, PluginTestOptions{Update: true})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	for _, c := range cases {
+		if c.Status != PluginTestUpdated {
+			t.Fatalf("expected updated, got %+v", c)
+		}
+	}
+	golden, err := os.ReadFile(filepath.Join(dir, "testdata", "has-readme", "expected.json"))
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	if !strings.Contains(string(golden), `"detail": "$ROOT"`) {
+		t.Fatalf("expected temp root to be normalized, got %s", golden)
+	}
+
+	cases, err = RunPluginTests(dir, PluginTestOptions{})
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	for _, c := range cases {
+		if c.Status != PluginTestPass {
+			t.Fatalf("expected pass on rerun, got %+v", c)
+		}
+	}
+
+	os.Remove(filepath.Join(dir, "testdata", "has-readme", "repo", "README.md"))
+	cases, err = RunPluginTests(dir, PluginTestOptions{Case: "has-readme"})
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	if len(cases) != 1 || cases[0].Status != PluginTestFail || !strings.Contains(cases[0].Diff, "- ") {
+		t.Fatalf("expected drifted fixture to fail with a diff, got %+v", cases)
+	}
+
+	if _, err := RunPluginTests(dir, PluginTestOptions{Case: "nope"}); err == nil {
+		t.Fatalf("expected error for unknown case")
+	}
+}
+
+func TestRunPluginTestsNoFixtures(t *testing.T) {
+	dir := t.TempDir()
+	writeTestPlugin(t, dir, "bare", "1")
+	if _, err := RunPluginTests(dir, PluginTestOptions{}); err == nil {
+		t.Fatalf("expected error without testdata")
+	}
+}
+
+func TestNormalizeFixtureOutput(t *testing.T) {
+	raw := []byte(`{"detail":"/tmp/x/file.go ok 0.25s took 12ms"}`)
+	got := string(normalizeFixtureOutput(raw, "/tmp/x"))
+	if got != `{"detail":"$ROOT/file.go ok <duration> took <duration>"}` {
+		t.Fatalf("unexpected normalization: %s", got)
+	}
+}
@@ -0,0 +1,345 @@
+package dun
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	pluginTriggerTypes = []string{"path-exists", "glob-exists"}
+	pluginRuleTypes    = []string{"path-exists", "path-missing", "glob-min-count", "glob-max-count", "pattern-count", "unique-ids", "cross-reference"}
+	regexRuleTypes     = []string{"pattern-count", "unique-ids"}
+)
+
+// LintPlugin validates the plugin in dir against the manifest schema: unknown
+// keys, missing required fields, unknown check, trigger and rule types,
+// missing prompt and data files, and invalid templates and regular
+// expressions. Unlike loading, it reports every problem instead of stopping
+// at the first.
+func LintPlugin(dir string) ([]Issue, error) {
+	const file = "plugin.yaml"
+	raw, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	add := func(line int, rule string, severity string, summary string) {
+		issues = append(issues, Issue{
+			ID:       fmt.Sprintf("plugin:%s:%d:%s", file, line, rule),
+			Summary:  summary,
+			Path:     file,
+			Line:     line,
+			Severity: severity,
+		})
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		add(0, "parse", "fail", err.Error())
+		return issues, nil
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		add(0, "parse", "fail", "manifest is not a mapping")
+		return issues, nil
+	}
+	top := doc.Content[0]
+	lintYAMLKeys(top, reflect.TypeOf(Manifest{}), "", func(node *yaml.Node, key string) {
+		add(node.Line, "unknown-key", "fail", fmt.Sprintf("unknown key %q", key))
+	})
+
+	var manifest Manifest
+	if err := top.Decode(&manifest); err != nil {
+		add(top.Line, "decode", "fail", err.Error())
+		return issues, nil
+	}
+
+	line := func(node *yaml.Node) int {
+		if node == nil {
+			return top.Line
+		}
+		return node.Line
+	}
+	if manifest.ID == "" {
+		add(top.Line, "missing-id", "fail", "id is required")
+	} else if !pluginIDPattern.MatchString(manifest.ID) {
+		add(line(mappingValue(top, "id")), "invalid-id", "warn", fmt.Sprintf("id %q should use lowercase letters, digits, - and _", manifest.ID))
+	}
+	if manifest.Version == "" {
+		add(top.Line, "missing-version", "fail", "version is required")
+	}
+	if !hasPluginContent(manifest) {
+		add(top.Line, "no-checks", "fail", "no checks, check_types, commands or partials defined")
+	}
+
+	requires := mappingValue(top, "requires")
+	if manifest.Requires.Dun != "" {
+		if err := validateVersionConstraint(manifest.Requires.Dun); err != nil {
+			add(line(fieldNode(requires, "dun")), "invalid-requirement", "fail", "requires.dun: "+err.Error())
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(manifest.Requires.Plugins)) {
+		if err := validateVersionConstraint(manifest.Requires.Plugins[id]); err != nil {
+			add(line(fieldNode(mappingValue(requires, "plugins"), id)), "invalid-requirement", "fail", fmt.Sprintf("requires.plugins.%s: %v", id, err))
+		}
+		if id == manifest.ID {
+			add(line(fieldNode(mappingValue(requires, "plugins"), id)), "invalid-requirement", "fail", "plugin requires itself")
+		}
+	}
+
+	triggers := mappingValue(top, "triggers")
+	for i, trigger := range manifest.Triggers {
+		if !slices.Contains(pluginTriggerTypes, trigger.Type) {
+			add(line(sequenceItem(triggers, i)), "unknown-trigger-type", "fail",
+				fmt.Sprintf("unknown trigger type %q (want %s)", trigger.Type, strings.Join(pluginTriggerTypes, ", ")))
+		}
+	}
+
+	ownTypes := map[string]bool{}
+	specs := mappingValue(top, "check_types")
+	for i, spec := range manifest.CheckTypes {
+		node := sequenceItem(specs, i)
+		if spec.Name == "" {
+			add(line(node), "invalid-check-type", "fail", "check type needs a name")
+			continue
+		}
+		ownTypes[spec.Name] = true
+		if (spec.Exec == "") == (spec.Wasm == "") {
+			add(line(node), "invalid-check-type", "fail", fmt.Sprintf("check type %s needs exactly one of exec or wasm", spec.Name))
+			continue
+		}
+		if existing, ok := LookupCheckType(spec.Name); ok && !isPluginCheckType(existing) {
+			add(line(node), "invalid-check-type", "fail", fmt.Sprintf("check type %s is builtin", spec.Name))
+		}
+		file := spec.Exec
+		if spec.Wasm != "" {
+			file = spec.Wasm
+		}
+		if path, err := pluginFilePath(dir, file); err != nil {
+			add(line(node), "invalid-check-type", "fail", fmt.Sprintf("check type %s: %v", spec.Name, err))
+		} else if !fileExists(path) {
+			add(line(node), "missing-file", "fail", fmt.Sprintf("check type %s: %s not found", spec.Name, file))
+		}
+	}
+
+	commands := mappingValue(top, "commands")
+	commandNames := map[string]bool{}
+	for i, command := range manifest.Commands {
+		node := sequenceItem(commands, i)
+		switch {
+		case command.Name == "":
+			add(line(node), "invalid-command", "fail", "command needs a name")
+			continue
+		case commandNames[command.Name]:
+			add(line(node), "invalid-command", "fail", fmt.Sprintf("duplicate command %q", command.Name))
+		case (command.Run == "") == (command.Exec == ""):
+			add(line(node), "invalid-command", "fail", fmt.Sprintf("command %s needs exactly one of run or exec", command.Name))
+		case command.Exec != "" && !filepath.IsLocal(filepath.FromSlash(command.Exec)):
+			add(line(node), "invalid-command", "fail", fmt.Sprintf("command %s: %s is outside the plugin directory", command.Name, command.Exec))
+		case command.Exec != "" && !fileExists(filepath.Join(dir, filepath.FromSlash(command.Exec))):
+			add(line(node), "missing-file", "fail", fmt.Sprintf("command %s: %s not found", command.Name, command.Exec))
+		}
+		commandNames[command.Name] = true
+	}
+
+	partials := mappingValue(top, "partials")
+	for _, name := range slices.Sorted(maps.Keys(manifest.Partials)) {
+		if !fileExists(filepath.Join(dir, filepath.FromSlash(manifest.Partials[name]))) {
+			add(line(fieldNode(partials, name)), "missing-file", "fail", fmt.Sprintf("partial %s: %s not found", name, manifest.Partials[name]))
+		}
+	}
+
+	checks := mappingValue(top, "checks")
+	seen := map[string]bool{}
+	for i, check := range manifest.Checks {
+		node := sequenceItem(checks, i)
+		at := func(key string) int {
+			return line(fieldNode(node, key))
+		}
+		name := check.ID
+		if name == "" {
+			name = fmt.Sprintf("checks[%d]", i)
+			add(line(node), "missing-check-id", "fail", name+": id is required")
+		} else if seen[check.ID] {
+			add(at("id"), "duplicate-check-id", "fail", fmt.Sprintf("duplicate check id %q", check.ID))
+		}
+		seen[check.ID] = true
+
+		if check.Type == "" {
+			add(line(node), "missing-check-type", "fail", name+": type is required")
+		} else if _, ok := LookupCheckType(check.Type); !ok && !ownTypes[check.Type] {
+			add(at("type"), "unknown-check-type", "fail", fmt.Sprintf("%s: unknown check type %q", name, check.Type))
+		}
+
+		// Templated paths are only known once rendered for a repo.
+		if isPromptPath(check.Prompt) && !strings.Contains(check.Prompt, "{{") && !fileExists(filepath.Join(dir, filepath.FromSlash(check.Prompt))) {
+			add(at("prompt"), "missing-file", "fail", fmt.Sprintf("%s: prompt %s not found", name, check.Prompt))
+		}
+		for key, path := range map[string]string{"response_schema": check.ResponseSchema, "state_rules": check.StateRules} {
+			if path != "" && !fileExists(filepath.Join(dir, filepath.FromSlash(path))) {
+				add(at(key), "missing-file", "fail", fmt.Sprintf("%s: %s %s not found", name, key, path))
+			}
+		}
+		for _, path := range check.GateFiles {
+			if !strings.Contains(path, "{{") && !fileExists(filepath.Join(dir, filepath.FromSlash(path))) {
+				add(at("gate_files"), "missing-file", "fail", fmt.Sprintf("%s: gate file %s not found", name, path))
+			}
+		}
+
+		templated := map[string][]string{"command": {check.Command}, "inputs": check.Inputs, "gate_files": check.GateFiles}
+		for _, value := range check.Env {
+			templated["env"] = append(templated["env"], value)
+		}
+		for _, rule := range check.Rules {
+			templated["rules"] = append(templated["rules"], rule.Path)
+		}
+		for _, rule := range check.Conditions {
+			templated["conditions"] = append(templated["conditions"], rule.Path)
+		}
+		for _, key := range slices.Sorted(maps.Keys(templated)) {
+			for _, text := range templated[key] {
+				if !strings.Contains(text, "{{") {
+					continue
+				}
+				if _, err := parseCheckTemplate(key, text); err != nil {
+					add(at(key), "invalid-template", "fail", fmt.Sprintf("%s: %v", name, err))
+				}
+			}
+		}
+
+		if check.IssuePattern != "" {
+			if _, err := regexp.Compile(check.IssuePattern); err != nil {
+				add(at("issue_pattern"), "invalid-regex", "fail", fmt.Sprintf("%s: issue_pattern: %v", name, err))
+			}
+		}
+		for _, key := range []string{"rules", "conditions"} {
+			rules := check.Rules
+			if key == "conditions" {
+				rules = check.Conditions
+			}
+			for j, rule := range rules {
+				ruleLine := line(sequenceItem(fieldNode(node, key), j))
+				if !slices.Contains(pluginRuleTypes, rule.Type) {
+					add(ruleLine, "unknown-rule-type", "fail", fmt.Sprintf("%s: unknown rule type %q", name, rule.Type))
+					continue
+				}
+				if slices.Contains(regexRuleTypes, rule.Type) {
+					if _, err := regexp.Compile(rule.Pattern); err != nil {
+						add(ruleLine, "invalid-regex", "fail", fmt.Sprintf("%s: %s pattern: %v", name, rule.Type, err))
+					}
+				}
+			}
+		}
+	}
+	return issues, nil
+}
+
+// lintYAMLKeys reports mapping keys that have no matching yaml tag in t.
+// Free-form maps (such as check config) are not inspected.
+func lintYAMLKeys(node *yaml.Node, t reflect.Type, path string, report func(node *yaml.Node, key string)) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			field, ok := fields[key]
+			if !ok {
+				report(node.Content[i], strings.TrimPrefix(path+"."+key, "."))
+				continue
+			}
+			lintYAMLKeys(node.Content[i+1], field, path+"."+key, report)
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			lintYAMLKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), report)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode || t.Elem().Kind() == reflect.Interface {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			lintYAMLKeys(node.Content[i+1], t.Elem(), path+"."+node.Content[i].Value, report)
+		}
+	}
+}
+
+// yamlFields maps the yaml key of each field in t to its type, flattening
+// inline structs.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") && field.Type.Kind() == reflect.Struct {
+			for key, typ := range yamlFields(field.Type) {
+				fields[key] = typ
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func sequenceItem(node *yaml.Node, i int) *yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode || i >= len(node.Content) {
+		return nil
+	}
+	return node.Content[i]
+}
+
+// fieldNode returns the key node for key in a mapping, so reported lines
+// point at the offending field.
+func fieldNode(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return node
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			if node.Content[i+1].Kind == yaml.SequenceNode {
+				return node.Content[i+1]
+			}
+			return node.Content[i]
+		}
+	}
+	return node
+}
+
+// isPromptPath reports whether an agent prompt names a file. Prompts that
+// are not found are otherwise used as inline text.
+func isPromptPath(prompt string) bool {
+	if prompt == "" || strings.ContainsAny(prompt, "\n ") {
+		return false
+	}
+	switch filepath.Ext(prompt) {
+	case ".md", ".txt", ".tmpl", ".tpl":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintPluginReportsProblems(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "prompts"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "prompts", "ok.md"), "prompt")
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), `id: sample
+version: "1"
+priorty: 10
+triggers:
+  - type: file-exists
+    value: go.mod
+checks:
+  - id: one
+    type: command
+    command: go vet ./...
+    issue_pattern: "([a-z"
+  - id: one
+    type: no-such-type
+  - id: agent
+    type: agent
+    prompt: prompts/missing.md
+    response_schema: responses/missing.json
+  - id: agent-ok
+    type: agent
+    prompt: prompts/ok.md
+    config:
+      anything: goes
+  - id: rules
+    type: rule-set
+    rules:
+      - type: pattern-count
+        path: README.md
+        pattern: "(unclosed"
+      - type: bogus
+        path: x
+        severty: warn
+`)
+
+	issues, err := LintPlugin(dir)
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	want := map[string]int{
+		"plugin:plugin.yaml:3:unknown-key":          0,
+		"plugin:plugin.yaml:5:unknown-trigger-type": 0,
+		"plugin:plugin.yaml:11:invalid-regex":       0,
+		"plugin:plugin.yaml:12:duplicate-check-id":  0,
+		"plugin:plugin.yaml:13:unknown-check-type":  0,
+		"plugin:plugin.yaml:16:missing-file":        0,
+		"plugin:plugin.yaml:17:missing-file":        0,
+		"plugin:plugin.yaml:26:invalid-regex":       0,
+		"plugin:plugin.yaml:29:unknown-rule-type":   0,
+		"plugin:plugin.yaml:31:unknown-key":         0,
+	}
+	for _, issue := range issues {
+		if _, ok := want[issue.ID]; !ok {
+			t.Errorf("unexpected issue %s: %s", issue.ID, issue.Summary)
+			continue
+		}
+		want[issue.ID]++
+	}
+	for id, count := range want {
+		if count != 1 {
+			t.Errorf("expected issue %s once, got %d", id, count)
+		}
+	}
+}
+
+func TestLintPluginMissingFields(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), "description: nothing\n")
+	issues, err := LintPlugin(dir)
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	var rules []string
+	for _, issue := range issues {
+		rules = append(rules, issue.ID[strings.LastIndex(issue.ID, ":")+1:])
+	}
+	if strings.Join(rules, ",") != "missing-id,missing-version,no-checks" {
+		t.Fatalf("unexpected issues: %v", rules)
+	}
+}
+
+func TestLintPluginParseError(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), "id: [\n")
+	issues, err := LintPlugin(dir)
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	if len(issues) != 1 || !strings.HasSuffix(issues[0].ID, ":parse") {
+		t.Fatalf("expected parse issue, got %+v", issues)
+	}
+	if _, err := LintPlugin(t.TempDir()); err == nil {
+		t.Fatalf("expected error without plugin.yaml")
+	}
+}
+
+func TestLintPluginAcceptsOwnCheckTypes(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "run.sh"), "#!/bin/sh\n")
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), `id: own
+version: "1"
+check_types:
+  - name: own-lint-type
+    exec: run.sh
+  - name: own-missing
+    exec: missing.sh
+checks:
+  - id: own
+    type: own-lint-type
+`)
+	issues, err := LintPlugin(dir)
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	if len(issues) != 1 || issues[0].ID != "plugin:plugin.yaml:6:missing-file" {
+		t.Fatalf("expected only the missing exec, got %+v", issues)
+	}
+}
+
+func TestLintBuiltinPlugins(t *testing.T) {
+	for _, entry := range builtinPlugins() {
+		issues, err := LintPlugin(filepath.Join("..", "plugins", "builtin", entry.Base))
+		if err != nil {
+			t.Fatalf("lint %s: %v", entry.ID, err)
+		}
+		for _, issue := range issues {
+			t.Errorf("%s: %s:%d: %s", entry.ID, issue.Path, issue.Line, issue.Summary)
+		}
+	}
+}
+
+func TestLintPluginRejectsPathsOutsidePlugin(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), `id: escape
+version: "1"
+check_types:
+  - name: escape-type
+    exec: ../shared/run.sh
+commands:
+  - name: run
+    exec: /usr/bin/env
+`)
+	issues, err := LintPlugin(dir)
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	var ids []string
+	for _, issue := range issues {
+		ids = append(ids, issue.ID)
+	}
+	if strings.Join(ids, ",") != "plugin:plugin.yaml:4:invalid-check-type,plugin:plugin.yaml:7:invalid-command" {
+		t.Fatalf("unexpected issues %v", ids)
+	}
+}
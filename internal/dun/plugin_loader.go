@@ -14,12 +14,14 @@ import (
 
 var builtinPlugins = builtin.Plugins
 
-// LoadBuiltins loads all builtin plugins, cached plugins, and external plugins.
+// LoadPlugins loads all builtin plugins, cached plugins, and external plugins.
 // Priority (lowest to highest): builtin < cached < user < project.
-// External plugins from the project directory override user plugins with the same ID.
-func LoadBuiltins() ([]Plugin, error) {
-	var plugins []Plugin
-	seen := make(map[string]int) // ID -> index in plugins slice
+// Project plugins are read from root/.dun/plugins and override user plugins
+// with the same ID.
+// A plugin whose requires block is not satisfied is skipped with a warning and
+// the same plugin from the next lower source is used instead.
+func LoadPlugins(root string) ([]Plugin, error) {
+	var candidates []pluginCandidate
 
 	// 1. Load builtin plugins (lowest priority)
 	for _, entry := range builtinPlugins() {
@@ -27,8 +29,7 @@ func LoadBuiltins() ([]Plugin, error) {
 		if err != nil {
 			return nil, err
 		}
-		seen[p.Manifest.ID] = len(plugins)
-		plugins = append(plugins, p)
+		candidates = append(candidates, pluginCandidate{Plugin: p, Source: PluginSourceBuiltin})
 	}
 
 	// 2. Load cached plugins from ~/.cache/ddx/library (overrides builtins)
@@ -37,28 +38,25 @@ func LoadBuiltins() ([]Plugin, error) {
 		return nil, err
 	}
 	for _, p := range cached {
-		if idx, ok := seen[p.Manifest.ID]; ok {
-			plugins[idx] = p
-		} else {
-			seen[p.Manifest.ID] = len(plugins)
-			plugins = append(plugins, p)
-		}
+		candidates = append(candidates, pluginCandidate{Plugin: p, Source: PluginSourceCache})
 	}
 
 	// 3. Load external plugins (user and project - highest priority)
-	external, err := LoadExternalPlugins()
-	if err != nil {
-		return nil, err
-	}
-	for _, p := range external {
-		if idx, ok := seen[p.Manifest.ID]; ok {
-			plugins[idx] = p
-		} else {
-			seen[p.Manifest.ID] = len(plugins)
-			plugins = append(plugins, p)
+	for _, layer := range externalPluginLayers(root) {
+		external, _ := loadPluginsFromDir(layer.Dir)
+		for _, p := range external {
+			candidates = append(candidates, pluginCandidate{Plugin: p, Source: layer.Source})
 		}
 	}
 
+	plugins, rejections := resolvePluginCandidates(candidates)
+	if len(rejections) > 0 {
+		logPluginRejections(rejections)
+	}
+	plugins = withPromptPartials(plugins)
+	for i := range plugins {
+		plugins[i].repoRoot = root
+	}
 	return plugins, nil
 }
 
@@ -100,33 +98,41 @@ func getCacheDir() (string, error) {
 	return os.UserCacheDir()
 }
 
-// LoadExternalPlugins loads plugins from user and project directories.
-// Project plugins override user plugins with the same ID.
-func LoadExternalPlugins() ([]Plugin, error) {
-	var plugins []Plugin
-	seen := make(map[string]int) // ID -> index in plugins slice
+type pluginLayer struct {
+	Source string
+	Dir    string
+}
 
+// externalPluginLayers lists the user and project plugin directories, lowest
+// priority first.
+func externalPluginLayers(root string) []pluginLayer {
+	var layers []pluginLayer
 	// User plugins: ~/.dun/plugins/*/plugin.yaml
-	homeDir, err := os.UserHomeDir()
-	if err == nil {
-		userDir := filepath.Join(homeDir, ".dun", "plugins")
-		userPlugins, _ := loadPluginsFromDir(userDir)
-		for _, p := range userPlugins {
-			seen[p.Manifest.ID] = len(plugins)
-			plugins = append(plugins, p)
-		}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		layers = append(layers, pluginLayer{Source: PluginSourceUser, Dir: filepath.Join(homeDir, ".dun", "plugins")})
 	}
-
 	// Project plugins: .dun/plugins/*/plugin.yaml
-	projectDir := ".dun/plugins"
-	projectPlugins, _ := loadPluginsFromDir(projectDir)
-	for _, p := range projectPlugins {
-		if idx, ok := seen[p.Manifest.ID]; ok {
-			// Project plugin overrides user plugin with same ID
-			plugins[idx] = p
-		} else {
-			seen[p.Manifest.ID] = len(plugins)
-			plugins = append(plugins, p)
+	layers = append(layers, pluginLayer{Source: PluginSourceProject, Dir: filepath.Join(root, ".dun", "plugins")})
+	return layers
+}
+
+// LoadExternalPlugins loads plugins from the user directory and the project
+// directory under root. Project plugins override user plugins with the same
+// ID.
+func LoadExternalPlugins(root string) ([]Plugin, error) {
+	var plugins []Plugin
+	seen := make(map[string]int) // ID -> index in plugins slice
+
+	for _, layer := range externalPluginLayers(root) {
+		layerPlugins, _ := loadPluginsFromDir(layer.Dir)
+		for _, p := range layerPlugins {
+			if idx, ok := seen[p.Manifest.ID]; ok {
+				// Project plugin overrides user plugin with same ID
+				plugins[idx] = p
+			} else {
+				seen[p.Manifest.ID] = len(plugins)
+				plugins = append(plugins, p)
+			}
 		}
 	}
 
@@ -149,7 +155,7 @@ func loadPluginsFromDir(dir string) ([]Plugin, error) {
 		pluginDir := filepath.Join(dir, entry.Name())
 		p, err := loadPluginFromPath(pluginDir)
 		if err != nil {
-			slog.Warn("skipping invalid plugin", "path", pluginDir, "error", err)
+			slog.Warn("skipping invalid plugin; run 'dun plugin lint' for details", "path", pluginDir, "error", err)
 			continue
 		}
 		plugins = append(plugins, p)
@@ -173,17 +179,39 @@ func loadPluginFromPath(pluginPath string) (Plugin, error) {
 	if manifest.ID == "" || manifest.Version == "" {
 		return Plugin{}, fmt.Errorf("invalid plugin manifest: missing id or version")
 	}
-	if len(manifest.Checks) == 0 {
+	if !hasPluginContent(manifest) {
 		return Plugin{}, fmt.Errorf("invalid plugin manifest: no checks defined")
 	}
+	if _, err := pluginCheckTypes(pluginPath, manifest); err != nil {
+		return Plugin{}, fmt.Errorf("invalid plugin manifest: %w", err)
+	}
+	for _, command := range manifest.Commands {
+		if command.Exec == "" {
+			continue
+		}
+		if _, err := pluginFilePath(pluginPath, command.Exec); err != nil {
+			return Plugin{}, fmt.Errorf("invalid plugin manifest: command %s: %w", command.Name, err)
+		}
+	}
 
 	return Plugin{
 		Manifest: manifest,
 		FS:       os.DirFS(pluginPath),
 		Base:     ".",
+		Dir:      pluginPath,
 	}, nil
 }
 
+// pluginFilePath resolves file, a slash separated manifest path, inside
+// pluginDir. Paths leading out of the directory are rejected, since
+// plugins.lock only hashes the plugin directory.
+func pluginFilePath(pluginDir string, file string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(file)) {
+		return "", fmt.Errorf("%s is outside the plugin directory", file)
+	}
+	return filepath.Join(pluginDir, filepath.FromSlash(file)), nil
+}
+
 func loadPluginFS(pluginFS fs.FS, base string) (Plugin, error) {
 	manifestPath := path.Join(base, "plugin.yaml")
 	raw, err := fs.ReadFile(pluginFS, manifestPath)
@@ -199,7 +227,7 @@ func loadPluginFS(pluginFS fs.FS, base string) (Plugin, error) {
 	if manifest.ID == "" || manifest.Version == "" {
 		return Plugin{}, fmt.Errorf("invalid plugin manifest: missing id or version")
 	}
-	if len(manifest.Checks) == 0 {
+	if !hasPluginContent(manifest) {
 		return Plugin{}, fmt.Errorf("invalid plugin manifest: no checks defined")
 	}
 
@@ -209,3 +237,9 @@ func loadPluginFS(pluginFS fs.FS, base string) (Plugin, error) {
 		Base:     base,
 	}, nil
 }
+
+// hasPluginContent reports whether a manifest contributes anything: checks,
+// check types, commands or prompt partials.
+func hasPluginContent(manifest Manifest) bool {
+	return len(manifest.Checks) > 0 || len(manifest.CheckTypes) > 0 || len(manifest.Commands) > 0 || len(manifest.Partials) > 0
+}
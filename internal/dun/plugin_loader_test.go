@@ -60,7 +60,7 @@ func TestLoadPluginFSSuccess(t *testing.T) {
 	}
 }
 
-func TestLoadBuiltinsError(t *testing.T) {
+func TestLoadPluginsBuiltinError(t *testing.T) {
 	orig := builtinPlugins
 	builtinPlugins = func() []builtin.Entry {
 		return []builtin.Entry{
@@ -69,17 +69,17 @@ func TestLoadBuiltinsError(t *testing.T) {
 	}
 	t.Cleanup(func() { builtinPlugins = orig })
 
-	if _, err := LoadBuiltins(); err == nil {
-		t.Fatalf("expected LoadBuiltins error")
+	if _, err := LoadPlugins(t.TempDir()); err == nil {
+		t.Fatalf("expected LoadPlugins error")
 	}
 }
 
-func TestLoadBuiltinsSuccess(t *testing.T) {
+func TestLoadPluginsBuiltinSuccess(t *testing.T) {
 	orig := builtinPlugins
 	builtinPlugins = builtin.Plugins
 	t.Cleanup(func() { builtinPlugins = orig })
 
-	plugins, err := LoadBuiltins()
+	plugins, err := LoadPlugins(t.TempDir())
 	if err != nil {
 		t.Fatalf("load builtins: %v", err)
 	}
@@ -256,19 +256,12 @@ checks:
 		t.Fatalf("write project manifest: %v", err)
 	}
 
-	// Change to project dir and test
-	origDir, _ := os.Getwd()
-	if err := os.Chdir(projectDir); err != nil {
-		t.Fatalf("chdir: %v", err)
-	}
-	t.Cleanup(func() { os.Chdir(origDir) })
-
 	// Override HOME to use our temp user dir
 	origHome := os.Getenv("HOME")
 	os.Setenv("HOME", userHome)
 	t.Cleanup(func() { os.Setenv("HOME", origHome) })
 
-	plugins, err := LoadExternalPlugins()
+	plugins, err := LoadExternalPlugins(projectDir)
 	if err != nil {
 		t.Fatalf("load external plugins: %v", err)
 	}
@@ -415,7 +408,7 @@ checks:
 	builtinPlugins = func() []builtin.Entry { return nil }
 	t.Cleanup(func() { builtinPlugins = origBuiltins })
 
-	plugins, err := LoadBuiltins()
+	plugins, err := LoadPlugins(".")
 	if err != nil {
 		t.Fatalf("load builtins: %v", err)
 	}
@@ -492,7 +485,7 @@ checks:
 	}
 	t.Cleanup(func() { builtinPlugins = origBuiltins })
 
-	plugins, err := LoadBuiltins()
+	plugins, err := LoadPlugins(".")
 	if err != nil {
 		t.Fatalf("load builtins: %v", err)
 	}
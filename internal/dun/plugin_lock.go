@@ -0,0 +1,286 @@
+package dun
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PluginLockPath is the lockfile recording hashes of active plugins, relative
+// to the repo root.
+const PluginLockPath = ".dun/plugins.lock"
+
+// Plugin integrity modes (plugins.integrity in config).
+const (
+	PluginIntegrityOff  = "off"
+	PluginIntegrityWarn = "warn"
+	PluginIntegrityFail = "fail"
+)
+
+// Drift kinds reported by VerifyPlugins.
+const (
+	PluginDriftChanged  = "changed"  // Content differs from the locked hash
+	PluginDriftUnlocked = "unlocked" // Active plugin missing from the lockfile
+	PluginDriftMissing  = "missing"  // Locked plugin no longer active
+)
+
+// Lock IDs of the prompt override directories. They change what agents are
+// asked to do as much as a plugin does, so plugins.lock records them too.
+// Plugin IDs cannot start with "~" or ".".
+const (
+	userPromptsLockID    = "~/.dun/prompts"
+	projectPromptsLockID = PromptOverrideDir
+)
+
+// PluginLock is the content of .dun/plugins.lock. Builtin plugins ship with
+// the binary and are not recorded.
+type PluginLock struct {
+	Version int                     `yaml:"version"`
+	Plugins map[string]LockedPlugin `yaml:"plugins"`
+}
+
+type LockedPlugin struct {
+	Source  string `yaml:"source"`
+	Version string `yaml:"version"`
+	Hash    string `yaml:"hash"`
+}
+
+// PluginDrift is a difference between the active plugins and the lockfile.
+type PluginDrift struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Source string `json:"source,omitempty"`
+	Want   string `json:"want,omitempty"`
+	Got    string `json:"got,omitempty"`
+}
+
+func (d PluginDrift) String() string {
+	subject := "plugin " + d.ID
+	if d.ID == userPromptsLockID || d.ID == projectPromptsLockID {
+		subject = "prompt overrides " + d.ID
+	}
+	switch d.Kind {
+	case PluginDriftChanged:
+		return fmt.Sprintf("%s (%s) changed since it was locked", subject, d.Source)
+	case PluginDriftUnlocked:
+		return fmt.Sprintf("%s (%s) is not in %s", subject, d.Source, PluginLockPath)
+	default:
+		return fmt.Sprintf("locked %s (%s) is no longer active", subject, d.Source)
+	}
+}
+
+// lockedPlugins lists what plugins.lock covers for root: the non-builtin
+// plugins, and each prompt override directory that exists as a plugin rooted
+// at that directory.
+func lockedPlugins(root string, plugins []Plugin) []Plugin {
+	var out []Plugin
+	for _, p := range plugins {
+		if p.Source != PluginSourceBuiltin && p.Dir != "" {
+			out = append(out, p)
+		}
+	}
+	for _, layer := range promptOverrideDirs(root) {
+		if info, err := os.Stat(layer.Dir); err != nil || !info.IsDir() {
+			continue
+		}
+		id := projectPromptsLockID
+		if layer.Source == PluginSourceUser {
+			id = userPromptsLockID
+		}
+		out = append(out, Plugin{Manifest: Manifest{ID: id}, FS: os.DirFS(layer.Dir), Base: ".", Dir: layer.Dir, Source: layer.Source})
+	}
+	return out
+}
+
+// HashPlugin returns a sha256 over the paths and contents of every file in
+// the plugin directory, in sorted order. VCS metadata is skipped. A symlink
+// counts with its target and the target's contents, so changing a file it
+// points to outside the plugin is drift too; symlinked directories are
+// refused.
+func HashPlugin(p Plugin) (string, error) {
+	var files []string
+	links := map[string]bool{}
+	err := fs.WalkDir(p.FS, p.Base, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			links[name] = true
+			files = append(files, name)
+		} else if d.Type().IsRegular() {
+			files = append(files, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, name := range files {
+		rel := strings.TrimPrefix(strings.TrimPrefix(name, p.Base), "/")
+		if p.Base == "." {
+			rel = name
+		}
+		if links[name] {
+			target, err := fs.ReadLink(p.FS, name)
+			if err != nil {
+				return "", err
+			}
+			if info, err := fs.Stat(p.FS, name); err == nil && info.IsDir() {
+				return "", fmt.Errorf("%s is a symlink to a directory", path.Clean(rel))
+			}
+			fmt.Fprintf(h, "%s\x00->%s\x00", path.Clean(rel), target)
+		}
+		data, err := fs.ReadFile(p.FS, name)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", path.Clean(rel), len(data))
+		h.Write(data)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// LockPlugins hashes the active non-builtin plugins and the prompt override
+// directories and writes the lockfile.
+func LockPlugins(root string) (PluginLock, error) {
+	plugins, err := loadPlugins(root)
+	if err != nil {
+		return PluginLock{}, err
+	}
+	lock := PluginLock{Version: 1, Plugins: map[string]LockedPlugin{}}
+	for _, p := range lockedPlugins(root, plugins) {
+		hash, err := HashPlugin(p)
+		if err != nil {
+			return PluginLock{}, fmt.Errorf("hash plugin %s: %w", p.Manifest.ID, err)
+		}
+		lock.Plugins[p.Manifest.ID] = LockedPlugin{Source: p.Source, Version: p.Manifest.Version, Hash: hash}
+	}
+
+	raw, err := yaml.Marshal(lock)
+	if err != nil {
+		return PluginLock{}, err
+	}
+	path := filepath.Join(root, PluginLockPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return PluginLock{}, err
+	}
+	content := "# Generated by 'dun plugin lock'. Do not edit.\n" + string(raw)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return PluginLock{}, err
+	}
+	return lock, nil
+}
+
+// ReadPluginLock reads the lockfile; ok is false when there is none.
+func ReadPluginLock(root string) (PluginLock, bool, error) {
+	raw, err := os.ReadFile(filepath.Join(root, PluginLockPath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return PluginLock{}, false, nil
+	}
+	if err != nil {
+		return PluginLock{}, false, err
+	}
+	var lock PluginLock
+	if err := yaml.Unmarshal(raw, &lock); err != nil {
+		return PluginLock{}, false, fmt.Errorf("parse %s: %w", PluginLockPath, err)
+	}
+	return lock, true, nil
+}
+
+// VerifyPlugins compares the active plugins and prompt overrides with the
+// lockfile. It returns an error when there is no lockfile.
+func VerifyPlugins(root string) ([]PluginDrift, error) {
+	lock, ok, err := ReadPluginLock(root)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s not found (run 'dun plugin lock')", PluginLockPath)
+	}
+	plugins, err := loadPlugins(root)
+	if err != nil {
+		return nil, err
+	}
+	return pluginLockDrift(lock, lockedPlugins(root, plugins))
+}
+
+// pluginLockDrift compares locked, as listed by lockedPlugins, with lock.
+func pluginLockDrift(lock PluginLock, locked []Plugin) ([]PluginDrift, error) {
+	var drift []PluginDrift
+	active := map[string]bool{}
+	for _, p := range locked {
+		id := p.Manifest.ID
+		active[id] = true
+		hash, err := HashPlugin(p)
+		if err != nil {
+			return nil, fmt.Errorf("hash plugin %s: %w", id, err)
+		}
+		entry, ok := lock.Plugins[id]
+		switch {
+		case !ok:
+			drift = append(drift, PluginDrift{ID: id, Kind: PluginDriftUnlocked, Source: p.Source, Got: hash})
+		case entry.Hash != hash || entry.Source != p.Source:
+			drift = append(drift, PluginDrift{ID: id, Kind: PluginDriftChanged, Source: p.Source, Want: entry.Hash, Got: hash})
+		}
+	}
+	for id, locked := range lock.Plugins {
+		if !active[id] {
+			drift = append(drift, PluginDrift{ID: id, Kind: PluginDriftMissing, Source: locked.Source, Want: locked.Hash})
+		}
+	}
+	sort.Slice(drift, func(i, j int) bool { return drift[i].ID < drift[j].ID })
+	return drift, nil
+}
+
+// enforcePluginLock checks loaded plugins against the lockfile, if there is
+// one. Changed or unlocked plugins are logged in warn mode and stop the run
+// in fail mode.
+func enforcePluginLock(root string, plugins []Plugin, mode string) error {
+	if mode == PluginIntegrityOff {
+		return nil
+	}
+	lock, ok, err := ReadPluginLock(root)
+	if err != nil || !ok {
+		return err
+	}
+	drift, err := pluginLockDrift(lock, lockedPlugins(root, plugins))
+	if err != nil {
+		return err
+	}
+	var problems []string
+	for _, d := range drift {
+		if d.Kind == PluginDriftMissing {
+			continue
+		}
+		problems = append(problems, d.String())
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	if mode == PluginIntegrityFail {
+		return fmt.Errorf("plugin integrity check failed: %s (run 'dun plugin verify', then 'dun plugin lock' if the change is trusted)", strings.Join(problems, "; "))
+	}
+	for _, problem := range problems {
+		slog.Warn("plugin integrity", "problem", problem)
+	}
+	return nil
+}
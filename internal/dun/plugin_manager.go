@@ -0,0 +1,460 @@
+package dun
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/easel/dun/internal/update"
+)
+
+// Plugin sources, lowest priority first.
+const (
+	PluginSourceBuiltin = "builtin"
+	PluginSourceCache   = "cache"
+	PluginSourceUser    = "user"
+	PluginSourceProject = "project"
+)
+
+var pluginIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// PluginInfo describes one plugin found in one source.
+type PluginInfo struct {
+	ID          string   `json:"id"`
+	Version     string   `json:"version,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Source      string   `json:"source"`
+	Path        string   `json:"path,omitempty"`
+	Checks      []string `json:"checks,omitempty"`
+	CheckTypes  []string `json:"check_types,omitempty"`
+	Commands    []string `json:"commands,omitempty"`
+	Partials    []string `json:"partials,omitempty"`
+	Requires    []string `json:"requires,omitempty"`  // "dun >=0.4", "helix ^2"
+	Overrides   []string `json:"overrides,omitempty"` // Lower-priority sources this plugin shadows
+	Shadowed    bool     `json:"shadowed,omitempty"`  // A higher-priority source wins
+	Error       string   `json:"error,omitempty"`     // Why the plugin is not loaded
+}
+
+// PluginDirs returns the on-disk plugin directories for root, keyed by source.
+// Directories that cannot be determined are omitted.
+func PluginDirs(root string) map[string]string {
+	dirs := map[string]string{
+		PluginSourceProject: filepath.Join(root, ".dun", "plugins"),
+	}
+	if cacheDir, err := getCacheDir(); err == nil {
+		dirs[PluginSourceCache] = filepath.Join(cacheDir, "ddx", "library", "plugins")
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		dirs[PluginSourceUser] = filepath.Join(homeDir, ".dun", "plugins")
+	}
+	return dirs
+}
+
+// ListPlugins returns every plugin from every source, including invalid ones,
+// ordered by ID and then by source priority. For each ID the highest-priority
+// valid plugin records the sources it overrides; the others are shadowed.
+func ListPlugins(root string) ([]PluginInfo, error) {
+	var infos []PluginInfo
+	var candidates []pluginCandidate
+	for _, entry := range builtinPlugins() {
+		p, err := loadPluginFS(entry.FS, entry.Base)
+		info := PluginInfo{ID: entry.ID, Source: PluginSourceBuiltin}
+		if err != nil {
+			info.Error = err.Error()
+		} else {
+			info = pluginInfo(p, PluginSourceBuiltin, "")
+			candidates = append(candidates, pluginCandidate{Plugin: p, Source: PluginSourceBuiltin})
+		}
+		infos = append(infos, info)
+	}
+	dirs := PluginDirs(root)
+	for _, source := range []string{PluginSourceCache, PluginSourceUser, PluginSourceProject} {
+		dir, ok := dirs[source]
+		if !ok {
+			continue
+		}
+		layerInfos, layerCandidates := scanPluginDir(dir, source)
+		infos = append(infos, layerInfos...)
+		candidates = append(candidates, layerCandidates...)
+	}
+
+	// Plugins refused for unmet requirements are reported like invalid ones.
+	_, rejections := resolvePluginCandidates(candidates)
+	for _, r := range rejections {
+		for i := range infos {
+			if infos[i].ID == r.Candidate.Plugin.Manifest.ID && infos[i].Source == r.Candidate.Source && infos[i].Error == "" {
+				infos[i].Error = "incompatible: " + r.Reason
+			}
+		}
+	}
+
+	rank := map[string]int{PluginSourceBuiltin: 0, PluginSourceCache: 1, PluginSourceUser: 2, PluginSourceProject: 3}
+	sort.SliceStable(infos, func(i, j int) bool {
+		if infos[i].ID != infos[j].ID {
+			return infos[i].ID < infos[j].ID
+		}
+		return rank[infos[i].Source] < rank[infos[j].Source]
+	})
+	for i := 0; i < len(infos); {
+		j := i
+		for j < len(infos) && infos[j].ID == infos[i].ID {
+			j++
+		}
+		winner := -1
+		for k := j - 1; k >= i; k-- {
+			if infos[k].Error == "" {
+				winner = k
+				break
+			}
+		}
+		for k := i; k < j; k++ {
+			if winner < 0 || k == winner || infos[k].Error != "" {
+				continue
+			}
+			infos[k].Shadowed = true
+			infos[winner].Overrides = append(infos[winner].Overrides, infos[k].Source)
+		}
+		i = j
+	}
+	return infos, nil
+}
+
+// FindPlugin returns the active plugin with id and the full list of sources
+// that define it.
+func FindPlugin(root string, id string) (PluginInfo, []PluginInfo, error) {
+	infos, err := ListPlugins(root)
+	if err != nil {
+		return PluginInfo{}, nil, err
+	}
+	var chain []PluginInfo
+	var active *PluginInfo
+	for i := range infos {
+		if infos[i].ID != id {
+			continue
+		}
+		chain = append(chain, infos[i])
+		if infos[i].Error == "" && !infos[i].Shadowed {
+			active = &infos[i]
+		}
+	}
+	if len(chain) == 0 {
+		return PluginInfo{}, nil, fmt.Errorf("plugin %s not found", id)
+	}
+	if active == nil {
+		return chain[len(chain)-1], chain, nil
+	}
+	return *active, chain, nil
+}
+
+// scanPluginDir reads every plugin directory under dir, keeping invalid
+// plugins with their error. Valid plugins are also returned as candidates.
+func scanPluginDir(dir string, source string) ([]PluginInfo, []pluginCandidate) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+	var infos []PluginInfo
+	var candidates []pluginCandidate
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		p, err := loadPluginFromPath(path)
+		if err != nil {
+			infos = append(infos, PluginInfo{ID: entry.Name(), Source: source, Path: path, Error: err.Error()})
+			continue
+		}
+		infos = append(infos, pluginInfo(p, source, path))
+		candidates = append(candidates, pluginCandidate{Plugin: p, Source: source})
+	}
+	return infos, candidates
+}
+
+func pluginInfo(p Plugin, source string, path string) PluginInfo {
+	info := PluginInfo{
+		ID:          p.Manifest.ID,
+		Version:     p.Manifest.Version,
+		Description: p.Manifest.Description,
+		Source:      source,
+		Path:        path,
+	}
+	for _, check := range p.Manifest.Checks {
+		info.Checks = append(info.Checks, check.ID)
+	}
+	for _, spec := range p.Manifest.CheckTypes {
+		info.CheckTypes = append(info.CheckTypes, spec.Name)
+	}
+	for _, command := range p.Manifest.Commands {
+		info.Commands = append(info.Commands, command.Name)
+	}
+	for _, name := range slices.Sorted(maps.Keys(p.Manifest.Partials)) {
+		info.Partials = append(info.Partials, p.Manifest.ID+"/"+name)
+	}
+	if p.Manifest.Requires.Dun != "" {
+		info.Requires = append(info.Requires, "dun "+p.Manifest.Requires.Dun)
+	}
+	for _, id := range slices.Sorted(maps.Keys(p.Manifest.Requires.Plugins)) {
+		info.Requires = append(info.Requires, id+" "+p.Manifest.Requires.Plugins[id])
+	}
+	return info
+}
+
+// ScaffoldPlugin creates a new plugin at dir/<id> with a manifest, an agent
+// prompt and a test fixture.
+func ScaffoldPlugin(dir string, id string) (string, error) {
+	if !pluginIDPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid plugin id %q (use lowercase letters, digits, - and _)", id)
+	}
+	pluginDir := filepath.Join(dir, id)
+	if _, err := os.Stat(pluginDir); err == nil {
+		return "", fmt.Errorf("%s already exists", pluginDir)
+	}
+	files := map[string]string{
+		"plugin.yaml": fmt.Sprintf(`id: %[1]s
+version: "0.1.0"
+description: "TODO: describe what %[1]s checks"
+priority: 50
+triggers:
+  - type: path-exists
+    value: README.md
+checks:
+  - id: %[1]s-readme
+    description: "README has a usage section"
+    type: rule-set
+    phase: build
+    rules:
+      - type: pattern-count
+        path: README.md
+        pattern: "(?m)^## Usage"
+        expected: 1
+        severity: warn
+
+  - id: %[1]s-review
+    description: "Ask an agent to review the README"
+    type: agent
+    phase: build
+    inputs:
+      - README.md
+    prompt: prompts/review.md
+`, id),
+		"prompts/review.md": fmt.Sprintf(`Check-ID: %s-review
+
+Review the README below and point out anything a new contributor would find
+missing or unclear.
+
+Return JSON with:
+- status: pass|warn|fail
+- signal: short summary
+- issues: optional list of issues
+
+{{- range .Inputs }}
+--- {{ .Path }}
+{{ .Content }}
+{{- end }}
+`, id),
+		"testdata/basic/repo/README.md": "# Example\n\n## Usage\n\nRun it.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(pluginDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return "", err
+		}
+	}
+	return pluginDir, nil
+}
+
+// InstallPlugin installs a plugin from a directory, a git URL or a tarball
+// (local path or http(s) URL) into dir/<id>. The plugin must load cleanly.
+func InstallPlugin(src string, dir string, force bool) (PluginInfo, error) {
+	staging, err := os.MkdirTemp("", "dun-plugin-")
+	if err != nil {
+		return PluginInfo{}, err
+	}
+	defer os.RemoveAll(staging)
+
+	pluginPath, err := fetchPlugin(src, staging)
+	if err != nil {
+		return PluginInfo{}, err
+	}
+	p, err := loadPluginFromPath(pluginPath)
+	if err != nil {
+		return PluginInfo{}, fmt.Errorf("%s: %w", src, err)
+	}
+	if !pluginIDPattern.MatchString(p.Manifest.ID) {
+		return PluginInfo{}, fmt.Errorf("invalid plugin id %q", p.Manifest.ID)
+	}
+
+	target := filepath.Join(dir, p.Manifest.ID)
+	if _, err := os.Stat(target); err == nil {
+		if !force {
+			return PluginInfo{}, fmt.Errorf("plugin %s is already installed at %s (use --force to replace)", p.Manifest.ID, target)
+		}
+		if err := os.RemoveAll(target); err != nil {
+			return PluginInfo{}, err
+		}
+	}
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return PluginInfo{}, err
+	}
+	if err := copyTree(pluginPath, target); err != nil {
+		os.RemoveAll(target)
+		return PluginInfo{}, err
+	}
+	installed, err := loadPluginFromPath(target)
+	if err != nil {
+		return PluginInfo{}, err
+	}
+	return pluginInfo(installed, "", target), nil
+}
+
+// fetchPlugin makes src available under staging and returns the directory
+// holding plugin.yaml.
+func fetchPlugin(src string, staging string) (string, error) {
+	switch {
+	case isGitPluginSource(src):
+		url := strings.TrimPrefix(src, "git+")
+		cmd := exec.Command("git", "clone", "--depth", "1", url, filepath.Join(staging, "repo"))
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("git clone %s: %s", url, trimOutput(out))
+		}
+		return findPluginRoot(filepath.Join(staging, "repo"))
+	case isTarballPluginSource(src):
+		reader, err := openPluginTarball(src)
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+		if err := extractTarGz(reader, filepath.Join(staging, "tar")); err != nil {
+			return "", fmt.Errorf("extract %s: %w", src, err)
+		}
+		return findPluginRoot(filepath.Join(staging, "tar"))
+	default:
+		info, err := os.Stat(src)
+		if err != nil {
+			return "", err
+		}
+		if !info.IsDir() {
+			return "", fmt.Errorf("%s is not a plugin directory, git URL or tarball", src)
+		}
+		return src, nil
+	}
+}
+
+func isGitPluginSource(src string) bool {
+	return strings.HasPrefix(src, "git+") || strings.HasPrefix(src, "git@") ||
+		strings.HasSuffix(src, ".git") || strings.HasPrefix(src, "ssh://")
+}
+
+func isTarballPluginSource(src string) bool {
+	return strings.HasSuffix(src, ".tar.gz") || strings.HasSuffix(src, ".tgz")
+}
+
+func openPluginTarball(src string) (io.ReadCloser, error) {
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		return update.Download(src)
+	}
+	return os.Open(src)
+}
+
+// extractTarGz unpacks regular files and directories, refusing paths that
+// escape dst.
+func extractTarGz(r io.Reader, dst string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, filepath.FromSlash(header.Name))
+		if rel, err := filepath.Rel(dst, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("illegal path %q", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// findPluginRoot returns dir when it holds plugin.yaml, or its only
+// subdirectory that does (tarballs usually wrap their content in one).
+func findPluginRoot(dir string) (string, error) {
+	if fileExists(filepath.Join(dir, "plugin.yaml")) {
+		return dir, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var found []string
+	for _, entry := range entries {
+		if entry.IsDir() && fileExists(filepath.Join(dir, entry.Name(), "plugin.yaml")) {
+			found = append(found, filepath.Join(dir, entry.Name()))
+		}
+	}
+	if len(found) != 1 {
+		return "", fmt.Errorf("expected one plugin.yaml, found %d", len(found))
+	}
+	return found[0], nil
+}
+
+// RemovePlugin deletes dir/<id> after confirming it holds that plugin.
+func RemovePlugin(dir string, id string) (string, error) {
+	if !pluginIDPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid plugin id %q", id)
+	}
+	target := filepath.Join(dir, id)
+	p, err := loadPluginFromPath(target)
+	if err != nil {
+		if _, statErr := os.Stat(target); os.IsNotExist(statErr) {
+			return "", fmt.Errorf("plugin %s is not installed in %s", id, dir)
+		}
+		if !fileExists(filepath.Join(target, "plugin.yaml")) {
+			return "", fmt.Errorf("%s is not a plugin directory", target)
+		}
+	} else if p.Manifest.ID != id {
+		return "", fmt.Errorf("%s holds plugin %s, not %s", target, p.Manifest.ID, id)
+	}
+	if err := os.RemoveAll(target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
@@ -0,0 +1,226 @@
+package dun
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestPlugin(t *testing.T, dir string, id string, version string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), "id: "+id+"\nversion: \""+version+"\"\nchecks:\n  - id: "+id+"-check\n    type: command\n    command: echo ok\n")
+}
+
+func isolatePluginSources(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	return home
+}
+
+func TestListPluginsOverrideChain(t *testing.T) {
+	home := isolatePluginSources(t)
+	root := t.TempDir()
+	writeTestPlugin(t, filepath.Join(home, ".dun", "plugins", "go"), "go", "9")
+	writeTestPlugin(t, filepath.Join(root, ".dun", "plugins", "go"), "go", "10")
+	writeTestPlugin(t, filepath.Join(root, ".dun", "plugins", "extra"), "extra", "1")
+	if err := os.MkdirAll(filepath.Join(root, ".dun", "plugins", "broken"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, filepath.Join(root, ".dun", "plugins", "broken", "plugin.yaml"), "id: broken\n")
+
+	infos, err := ListPlugins(root)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var goInfos []PluginInfo
+	var broken, extra *PluginInfo
+	for i, info := range infos {
+		switch info.ID {
+		case "go":
+			goInfos = append(goInfos, info)
+		case "broken":
+			broken = &infos[i]
+		case "extra":
+			extra = &infos[i]
+		}
+	}
+	if len(goInfos) != 3 {
+		t.Fatalf("expected builtin, user and project go plugins, got %+v", goInfos)
+	}
+	if goInfos[0].Source != PluginSourceBuiltin || !goInfos[0].Shadowed {
+		t.Fatalf("expected shadowed builtin first, got %+v", goInfos[0])
+	}
+	project := goInfos[2]
+	if project.Source != PluginSourceProject || project.Version != "10" || project.Shadowed {
+		t.Fatalf("expected active project plugin, got %+v", project)
+	}
+	if strings.Join(project.Overrides, ",") != "builtin,user" {
+		t.Fatalf("unexpected overrides: %v", project.Overrides)
+	}
+	if broken == nil || broken.Error == "" {
+		t.Fatalf("expected invalid plugin to be listed with its error, got %+v", broken)
+	}
+	if extra == nil || extra.Shadowed || len(extra.Overrides) != 0 {
+		t.Fatalf("unexpected extra plugin: %+v", extra)
+	}
+
+	active, chain, err := FindPlugin(root, "go")
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if active.Version != "10" || len(chain) != 3 {
+		t.Fatalf("unexpected find result: %+v %+v", active, chain)
+	}
+	if _, _, err := FindPlugin(root, "missing"); err == nil {
+		t.Fatalf("expected error for unknown plugin")
+	}
+}
+
+func TestScaffoldPluginLintsClean(t *testing.T) {
+	dir := t.TempDir()
+	created, err := ScaffoldPlugin(dir, "demo")
+	if err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	for _, name := range []string{"plugin.yaml", "prompts/review.md", "testdata/basic/repo/README.md"} {
+		if !fileExists(filepath.Join(created, name)) {
+			t.Fatalf("expected %s", name)
+		}
+	}
+	issues, err := LintPlugin(created)
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Fatalf("expected scaffold to lint clean, got %+v", issues)
+	}
+	if _, err := loadPluginFromPath(created); err != nil {
+		t.Fatalf("scaffold does not load: %v", err)
+	}
+	if _, err := ScaffoldPlugin(dir, "demo"); err == nil {
+		t.Fatalf("expected error when plugin exists")
+	}
+	if _, err := ScaffoldPlugin(dir, "Bad Name"); err == nil {
+		t.Fatalf("expected error for invalid id")
+	}
+}
+
+func TestInstallAndRemovePluginFromDirectory(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	writeTestPlugin(t, src, "local", "1")
+	dest := filepath.Join(t.TempDir(), "plugins")
+
+	info, err := InstallPlugin(src, dest, false)
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if info.ID != "local" || info.Path != filepath.Join(dest, "local") {
+		t.Fatalf("unexpected install info: %+v", info)
+	}
+	if _, err := InstallPlugin(src, dest, false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected already-installed error, got %v", err)
+	}
+	writeTestPlugin(t, src, "local", "2")
+	info, err = InstallPlugin(src, dest, true)
+	if err != nil || info.Version != "2" {
+		t.Fatalf("force install: %+v %v", info, err)
+	}
+
+	if _, err := RemovePlugin(dest, "other"); err == nil {
+		t.Fatalf("expected error removing missing plugin")
+	}
+	outside := filepath.Join(filepath.Dir(dest), "outside")
+	writeTestPlugin(t, outside, "outside", "1")
+	if _, err := RemovePlugin(dest, "../outside"); err == nil || !strings.Contains(err.Error(), "invalid plugin id") {
+		t.Fatalf("expected invalid id error, got %v", err)
+	}
+	if !fileExists(outside) {
+		t.Fatalf("remove deleted a directory outside %s", dest)
+	}
+	removed, err := RemovePlugin(dest, "local")
+	if err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if fileExists(removed) {
+		t.Fatalf("expected %s to be removed", removed)
+	}
+}
+
+func TestInstallPluginDoesNotRegisterCheckTypes(t *testing.T) {
+	src := writeExecPlugin(t, "exec-installed", "", "exit 0\n")
+	if _, err := InstallPlugin(src, filepath.Join(t.TempDir(), "plugins"), false); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if _, ok := LookupCheckType("exec-installed"); ok {
+		t.Fatalf("install registered the plugin's check type")
+	}
+}
+
+func TestInstallPluginRejectsInvalid(t *testing.T) {
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "plugin.yaml"), "id: nope\n")
+	if _, err := InstallPlugin(src, t.TempDir(), false); err == nil {
+		t.Fatalf("expected invalid plugin to be rejected")
+	}
+	if _, err := InstallPlugin(filepath.Join(src, "plugin.yaml"), t.TempDir(), false); err == nil {
+		t.Fatalf("expected error for a plain file")
+	}
+}
+
+func TestInstallPluginFromTarball(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "tarred.tar.gz")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	manifest := "id: tarred\nversion: \"1\"\nchecks:\n  - id: t\n    type: command\n    command: echo ok\n"
+	tw.WriteHeader(&tar.Header{Name: "tarred-1.0/", Typeflag: tar.TypeDir, Mode: 0755})
+	tw.WriteHeader(&tar.Header{Name: "tarred-1.0/plugin.yaml", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(manifest))})
+	tw.Write([]byte(manifest))
+	tw.Close()
+	gz.Close()
+	f.Close()
+
+	dest := t.TempDir()
+	info, err := InstallPlugin(archive, dest, false)
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if !fileExists(filepath.Join(dest, "tarred", "plugin.yaml")) || info.ID != "tarred" {
+		t.Fatalf("unexpected install: %+v", info)
+	}
+}
+
+func TestExtractTarGzRejectsEscapingPaths(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "evil.tgz")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "../evil", Typeflag: tar.TypeReg, Mode: 0644, Size: 1})
+	tw.Write([]byte("x"))
+	tw.Close()
+	gz.Close()
+	f.Close()
+
+	r, err := os.Open(archive)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer r.Close()
+	if err := extractTarGz(r, t.TempDir()); err == nil {
+		t.Fatalf("expected escaping path to be rejected")
+	}
+}
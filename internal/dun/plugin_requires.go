@@ -0,0 +1,252 @@
+package dun
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/easel/dun/internal/version"
+)
+
+var constraintOperatorSpace = regexp.MustCompile(`(>=|<=|==|>|<|=|\^|~)\s+`)
+
+// dunVersion reports the running dun version for manifest requirements.
+var dunVersion = func() string { return version.Version }
+
+// pluginCandidate is a loaded plugin together with the source it came from.
+type pluginCandidate struct {
+	Plugin Plugin
+	Source string
+}
+
+// pluginRejection records why a candidate was refused.
+type pluginRejection struct {
+	Candidate pluginCandidate
+	Reason    string
+	Fallback  string // Source used instead; empty when the plugin is dropped
+}
+
+// resolvePluginCandidates picks one plugin per ID from candidates listed in
+// ascending priority. The highest-priority candidate whose requirements hold
+// wins; an incompatible one falls back to the next lower source. Plugin
+// dependencies are checked against the selected versions, so dropping one
+// plugin can make a dependent fall back as well.
+func resolvePluginCandidates(candidates []pluginCandidate) ([]Plugin, []pluginRejection) {
+	var order []string
+	groups := map[string][]pluginCandidate{}
+	for _, c := range candidates {
+		id := c.Plugin.Manifest.ID
+		if _, ok := groups[id]; !ok {
+			order = append(order, id)
+		}
+		groups[id] = append([]pluginCandidate{c}, groups[id]...) // Highest priority first
+	}
+
+	choice := map[string]int{}
+	var rejections []pluginRejection
+	for changed := true; changed; {
+		changed = false
+		selected := map[string]pluginCandidate{}
+		for _, id := range order {
+			if choice[id] < len(groups[id]) {
+				selected[id] = groups[id][choice[id]]
+			}
+		}
+		for _, id := range order {
+			c, ok := selected[id]
+			if !ok {
+				continue
+			}
+			if err := checkPluginRequirements(c.Plugin.Manifest, selected); err != nil {
+				choice[id]++
+				rejection := pluginRejection{Candidate: c, Reason: err.Error()}
+				if choice[id] < len(groups[id]) {
+					rejection.Fallback = groups[id][choice[id]].Source
+				}
+				rejections = append(rejections, rejection)
+				changed = true
+				break
+			}
+		}
+	}
+
+	var plugins []Plugin
+	for _, id := range order {
+		if choice[id] < len(groups[id]) {
+			c := groups[id][choice[id]]
+			c.Plugin.Source = c.Source
+			plugins = append(plugins, c.Plugin)
+		}
+	}
+	return plugins, rejections
+}
+
+// checkPluginRequirements checks a manifest's requires block against the dun
+// version and the selected plugins. Development builds of dun satisfy any dun
+// constraint.
+func checkPluginRequirements(manifest Manifest, selected map[string]pluginCandidate) error {
+	if constraint := manifest.Requires.Dun; constraint != "" {
+		current := dunVersion()
+		if _, err := parseSemver(current); err == nil {
+			ok, err := versionSatisfies(current, constraint)
+			if err != nil {
+				return fmt.Errorf("invalid dun requirement: %w", err)
+			}
+			if !ok {
+				return fmt.Errorf("requires dun %s, running %s", constraint, current)
+			}
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(manifest.Requires.Plugins)) {
+		constraint := manifest.Requires.Plugins[id]
+		dep, ok := selected[id]
+		if !ok {
+			return fmt.Errorf("requires plugin %s %s, which is not available", id, constraint)
+		}
+		ok, err := versionSatisfies(dep.Plugin.Manifest.Version, constraint)
+		if err != nil {
+			return fmt.Errorf("invalid requirement for plugin %s: %w", id, err)
+		}
+		if !ok {
+			return fmt.Errorf("requires plugin %s %s, found %s (%s)", id, constraint, dep.Plugin.Manifest.Version, dep.Source)
+		}
+	}
+	return nil
+}
+
+func logPluginRejections(rejections []pluginRejection) {
+	for _, r := range rejections {
+		fallback := r.Fallback
+		if fallback == "" {
+			fallback = "none"
+		}
+		slog.Warn("skipping incompatible plugin",
+			"id", r.Candidate.Plugin.Manifest.ID,
+			"version", r.Candidate.Plugin.Manifest.Version,
+			"source", r.Candidate.Source,
+			"reason", r.Reason,
+			"fallback", fallback)
+	}
+}
+
+// versionSatisfies reports whether version matches constraint. Constraints are
+// comparisons (>=1.2, <2, =1.4.0), caret (^2: same major) and tilde (~1.4:
+// same minor) ranges, or a bare version. Terms separated by spaces or commas
+// must all hold; "||" separates alternatives. "*" matches anything.
+func versionSatisfies(version string, constraint string) (bool, error) {
+	v, err := parseSemver(version)
+	if err != nil {
+		return false, err
+	}
+	constraint = constraintOperatorSpace.ReplaceAllString(constraint, "$1")
+	for _, alternative := range strings.Split(constraint, "||") {
+		terms := strings.FieldsFunc(alternative, func(r rune) bool { return r == ',' || r == ' ' })
+		ok := true
+		for _, term := range terms {
+			matched, err := semverTermMatches(v, term)
+			if err != nil {
+				return false, err
+			}
+			ok = ok && matched
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// validateVersionConstraint reports a syntax error in constraint.
+func validateVersionConstraint(constraint string) error {
+	_, err := versionSatisfies("0.0.0", constraint)
+	return err
+}
+
+func semverTermMatches(v [3]int, term string) (bool, error) {
+	if term == "*" || term == "x" {
+		return true, nil
+	}
+	op := ""
+	for _, candidate := range []string{">=", "<=", "==", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			break
+		}
+	}
+	raw := strings.TrimSpace(strings.TrimPrefix(term, op))
+	want, err := parseSemver(raw)
+	if err != nil {
+		return false, err
+	}
+	parts := len(strings.Split(strings.TrimPrefix(raw, "v"), "."))
+	cmp := compareSemver(v, want)
+	switch op {
+	case ">=":
+		return cmp >= 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "^":
+		upper := [3]int{want[0] + 1, 0, 0}
+		if want[0] == 0 && parts > 1 {
+			upper = [3]int{0, want[1] + 1, 0}
+		}
+		return cmp >= 0 && compareSemver(v, upper) < 0, nil
+	case "~":
+		upper := [3]int{want[0], want[1] + 1, 0}
+		if parts == 1 {
+			upper = [3]int{want[0] + 1, 0, 0}
+		}
+		return cmp >= 0 && compareSemver(v, upper) < 0, nil
+	default:
+		// A bare or "=" version matches on the components it names, so
+		// "2" matches 2.3.1.
+		for i := 0; i < parts && i < 3; i++ {
+			if v[i] != want[i] {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+}
+
+// parseSemver parses "1", "1.2" or "1.2.3" with an optional "v" prefix;
+// pre-release and build suffixes are ignored.
+func parseSemver(s string) ([3]int, error) {
+	var v [3]int
+	trimmed := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(trimmed, "-+"); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+	parts := strings.Split(trimmed, ".")
+	if trimmed == "" || len(parts) > 3 {
+		return v, fmt.Errorf("invalid version %q", s)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version %q", s)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+func compareSemver(a [3]int, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
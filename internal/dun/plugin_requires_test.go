@@ -0,0 +1,180 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVersionSatisfies(t *testing.T) {
+	cases := []struct {
+		version    string
+		constraint string
+		want       bool
+	}{
+		{"2.0.0", "^2", true},
+		{"2.9.1", "^2", true},
+		{"3.0.0", "^2", false},
+		{"1.0.0", "^2", false},
+		{"0.4.2", "^0.4", true},
+		{"0.5.0", "^0.4", false},
+		{"1.4.9", "~1.4", true},
+		{"1.5.0", "~1.4", false},
+		{"1", ">=1.0", true},
+		{"0.9", ">= 1.0", false},
+		{"1.2.3", ">=1.2, <2", true},
+		{"2.0.0", ">=1.2 <2", false},
+		{"2.0.0", "^1 || ^2", true},
+		{"v1.2.3-rc1", "=1.2.3", true},
+		{"1.2.3", "1.2", true},
+		{"1.3.0", "1.2", false},
+		{"5.0.0", "*", true},
+	}
+	for _, tc := range cases {
+		got, err := versionSatisfies(tc.version, tc.constraint)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tc.version, tc.constraint, err)
+		}
+		if got != tc.want {
+			t.Errorf("versionSatisfies(%q, %q) = %v, want %v", tc.version, tc.constraint, got, tc.want)
+		}
+	}
+	if _, err := versionSatisfies("1.0.0", ">=banana"); err == nil {
+		t.Fatalf("expected invalid constraint error")
+	}
+	if _, err := versionSatisfies("dev", ">=1"); err == nil {
+		t.Fatalf("expected invalid version error")
+	}
+}
+
+func candidate(id string, version string, source string, requires Requirements) pluginCandidate {
+	return pluginCandidate{
+		Plugin: Plugin{Manifest: Manifest{ID: id, Version: version, Requires: requires}},
+		Source: source,
+	}
+}
+
+func TestResolvePluginCandidatesFallsBack(t *testing.T) {
+	orig := dunVersion
+	dunVersion = func() string { return "1.5.0" }
+	t.Cleanup(func() { dunVersion = orig })
+
+	plugins, rejections := resolvePluginCandidates([]pluginCandidate{
+		candidate("helix", "2.0.0", PluginSourceBuiltin, Requirements{}),
+		candidate("other", "1.0.0", PluginSourceBuiltin, Requirements{Plugins: map[string]string{"helix": "^2"}}),
+		candidate("helix", "3.0.0", PluginSourceCache, Requirements{Dun: ">=2.0"}),
+		candidate("extra", "1.0.0", PluginSourceProject, Requirements{Plugins: map[string]string{"missing": "*"}}),
+	})
+
+	versions := map[string]string{}
+	for _, p := range plugins {
+		versions[p.Manifest.ID] = p.Manifest.Version
+	}
+	if len(plugins) != 2 || versions["helix"] != "2.0.0" || versions["other"] != "1.0.0" {
+		t.Fatalf("expected builtin helix fallback and other, got %v", versions)
+	}
+	if plugins[0].Manifest.ID != "helix" || plugins[1].Manifest.ID != "other" {
+		t.Fatalf("expected first-seen order, got %+v", plugins)
+	}
+	if len(rejections) != 2 {
+		t.Fatalf("expected two rejections, got %+v", rejections)
+	}
+	if rejections[0].Candidate.Source != PluginSourceCache || rejections[0].Fallback != PluginSourceBuiltin ||
+		!strings.Contains(rejections[0].Reason, "requires dun >=2.0, running 1.5.0") {
+		t.Fatalf("unexpected helix rejection: %+v", rejections[0])
+	}
+	if rejections[1].Fallback != "" || rejections[1].Reason != "requires plugin missing *, which is not available" {
+		t.Fatalf("unexpected extra rejection: %+v", rejections[1])
+	}
+}
+
+func TestResolvePluginCandidatesCascadesDependencies(t *testing.T) {
+	plugins, rejections := resolvePluginCandidates([]pluginCandidate{
+		candidate("base", "1.0.0", PluginSourceBuiltin, Requirements{}),
+		candidate("addon", "1.0.0", PluginSourceBuiltin, Requirements{}),
+		candidate("addon", "2.0.0", PluginSourceProject, Requirements{Plugins: map[string]string{"base": "^2"}}),
+	})
+	if len(plugins) != 2 || plugins[1].Manifest.Version != "1.0.0" {
+		t.Fatalf("expected addon to fall back to 1.0.0, got %+v", plugins)
+	}
+	if len(rejections) != 1 || !strings.Contains(rejections[0].Reason, "requires plugin base ^2, found 1.0.0 (builtin)") {
+		t.Fatalf("unexpected rejections: %+v", rejections)
+	}
+}
+
+func TestDevBuildSatisfiesDunRequirement(t *testing.T) {
+	orig := dunVersion
+	dunVersion = func() string { return "dev" }
+	t.Cleanup(func() { dunVersion = orig })
+	if err := checkPluginRequirements(Manifest{Requires: Requirements{Dun: ">=99"}}, nil); err != nil {
+		t.Fatalf("expected dev build to satisfy dun requirement, got %v", err)
+	}
+}
+
+func TestLoadPluginsRefusesIncompatibleCachedPlugin(t *testing.T) {
+	home := isolatePluginSources(t)
+	orig := dunVersion
+	dunVersion = func() string { return "0.1.0" }
+	t.Cleanup(func() { dunVersion = orig })
+
+	cached := filepath.Join(home, ".cache", "ddx", "library", "plugins", "helix")
+	if err := os.MkdirAll(cached, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, filepath.Join(cached, "plugin.yaml"), `id: helix
+version: "3.0.0"
+requires:
+  dun: ">=1.0"
+checks:
+  - id: cached-helix
+    type: command
+    command: echo cached
+`)
+
+	plugins, err := LoadPlugins(t.TempDir())
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	for _, p := range plugins {
+		if p.Manifest.ID == "helix" && p.Manifest.Version == "3.0.0" {
+			t.Fatalf("expected incompatible cached helix to be refused")
+		}
+	}
+
+	infos, err := ListPlugins(t.TempDir())
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, info := range infos {
+		if info.ID == "helix" && info.Source == PluginSourceCache && !strings.Contains(info.Error, "incompatible: requires dun >=1.0") {
+			t.Fatalf("expected cached helix to be listed as incompatible, got %+v", info)
+		}
+		if info.ID == "helix" && info.Source == PluginSourceBuiltin && info.Shadowed {
+			t.Fatalf("expected builtin helix to stay active, got %+v", info)
+		}
+	}
+}
+
+func TestLintPluginInvalidRequirements(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), `id: req
+version: "1"
+requires:
+  dun: ">=nope"
+  plugins:
+    helix: "^2"
+    req: "*"
+checks:
+  - id: x
+    type: command
+    command: echo ok
+`)
+	issues, err := LintPlugin(dir)
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	if len(issues) != 2 || issues[0].ID != "plugin:plugin.yaml:4:invalid-requirement" || issues[1].ID != "plugin:plugin.yaml:7:invalid-requirement" {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+}
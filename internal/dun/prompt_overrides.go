@@ -0,0 +1,136 @@
+package dun
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// PromptOverrideDir is where a project overrides plugin prompts, as
+// <dir>/<plugin-id>/<prompt path>, relative to the repo root. The same layout
+// under ~/.dun/prompts applies to every repo.
+const PromptOverrideDir = ".dun/prompts"
+
+// promptOverrideDirs lists the user and project override directories for
+// root, lowest priority first.
+func promptOverrideDirs(root string) []pluginLayer {
+	var dirs []pluginLayer
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, pluginLayer{Source: PluginSourceUser, Dir: filepath.Join(homeDir, ".dun", "prompts")})
+	}
+	return append(dirs, pluginLayer{Source: PluginSourceProject, Dir: filepath.Join(root, filepath.FromSlash(PromptOverrideDir))})
+}
+
+// promptOverrides returns the user and project overrides of a plugin prompt,
+// lowest priority first. An override is found at <dir>/<plugin-id>/<path>,
+// or without the path's leading "prompts/". Project overrides come from the
+// repo the plugin was loaded for, so sub-project checks (whose root is the
+// sub-project) use them too.
+func promptOverrides(root string, plugin Plugin, promptPath string) ([]string, error) {
+	if plugin.Manifest.ID == "" || !isPromptPath(promptPath) {
+		return nil, nil
+	}
+	if plugin.repoRoot != "" {
+		root = plugin.repoRoot
+	}
+	candidates := []string{promptPath}
+	if trimmed := strings.TrimPrefix(promptPath, "prompts/"); trimmed != promptPath {
+		candidates = append(candidates, trimmed)
+	}
+	var overrides []string
+	for _, layer := range promptOverrideDirs(root) {
+		for _, candidate := range candidates {
+			raw, err := os.ReadFile(filepath.Join(layer.Dir, plugin.Manifest.ID, filepath.FromSlash(candidate)))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			overrides = append(overrides, string(raw))
+			break
+		}
+	}
+	return overrides, nil
+}
+
+// parsePromptTemplate parses a plugin prompt and its overrides into tmpl. The
+// highest-priority override becomes tmpl itself; each override can include
+// the layer below it (the user override or the plugin's own prompt) with
+// {{ template "base" . }}, also from inside its {{define}} and {{block}}
+// templates.
+func parsePromptTemplate(tmpl *template.Template, root string, plugin Plugin, promptPath string) error {
+	text, err := loadPromptTemplate(plugin, promptPath)
+	if err != nil {
+		return err
+	}
+	overrides, err := promptOverrides(root, plugin, promptPath)
+	if err != nil {
+		return err
+	}
+	layers := append([]string{text}, overrides...)
+	for i, layer := range layers {
+		name := tmpl.Name()
+		if i < len(layers)-1 {
+			name = fmt.Sprintf("base-%d", i)
+		}
+		t := tmpl
+		if name != tmpl.Name() {
+			t = tmpl.New(name)
+		}
+		before := templateTrees(tmpl)
+		if _, err := t.Parse(layer); err != nil {
+			return err
+		}
+		if i == 0 {
+			continue
+		}
+		// Rename in every tree this layer's Parse added or replaced.
+		for _, added := range tmpl.Templates() {
+			if added.Tree != nil && added.Tree != before[added.Name()] {
+				renameTemplateCalls(added.Tree.Root, "base", fmt.Sprintf("base-%d", i-1))
+			}
+		}
+	}
+	return nil
+}
+
+// templateTrees maps the names of the templates associated with tmpl to their
+// parse trees.
+func templateTrees(tmpl *template.Template) map[string]*parse.Tree {
+	trees := map[string]*parse.Tree{}
+	for _, t := range tmpl.Templates() {
+		trees[t.Name()] = t.Tree
+	}
+	return trees
+}
+
+// renameTemplateCalls points {{ template "from" }} calls in a parse tree at
+// another template.
+func renameTemplateCalls(node parse.Node, from string, to string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			renameTemplateCalls(child, from, to)
+		}
+	case *parse.TemplateNode:
+		if n.Name == from {
+			n.Name = to
+		}
+	case *parse.IfNode:
+		renameTemplateCalls(n.List, from, to)
+		renameTemplateCalls(n.ElseList, from, to)
+	case *parse.RangeNode:
+		renameTemplateCalls(n.List, from, to)
+		renameTemplateCalls(n.ElseList, from, to)
+	case *parse.WithNode:
+		renameTemplateCalls(n.List, from, to)
+		renameTemplateCalls(n.ElseList, from, to)
+	}
+}
@@ -0,0 +1,100 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func setupPromptOverride(t *testing.T) (root string, home string, plugin Plugin) {
+	t.Helper()
+	home = isolatePluginSources(t)
+	root = t.TempDir()
+	pluginDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(pluginDir, "prompts"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, filepath.Join(pluginDir, "prompts", "create-architecture.md"), "Base {{.CheckID}}")
+	plugin = Plugin{Manifest: Manifest{ID: "helix"}, FS: os.DirFS(pluginDir), Base: "."}
+	return root, home, plugin
+}
+
+func writePromptOverride(t *testing.T, dir string, rel string, content string) {
+	t.Helper()
+	path := filepath.Join(dir, "helix", filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, path, content)
+}
+
+func TestPromptOverrideExtendsBase(t *testing.T) {
+	root, home, plugin := setupPromptOverride(t)
+	writePromptOverride(t, filepath.Join(home, ".dun", "prompts"), "create-architecture.md", `User[{{ template "base" . }}]`)
+	writePromptOverride(t, filepath.Join(root, ".dun", "prompts"), "prompts/create-architecture.md",
+		`Project[{{ if .CheckID }}{{ template "base" . }}{{ end }}]`)
+
+	text, _, err := renderPromptText(root, plugin, AgentCheckConfig{Prompt: "prompts/create-architecture.md"}, "arch", nil, "auto")
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if text != "Project[User[Base arch]]" {
+		t.Fatalf("unexpected prompt %q", text)
+	}
+}
+
+func TestPromptOverrideReplacesBase(t *testing.T) {
+	root, _, plugin := setupPromptOverride(t)
+	config := AgentCheckConfig{Prompt: "prompts/create-architecture.md"}
+
+	text, _, err := renderPromptText(root, plugin, config, "arch", nil, "auto")
+	if err != nil || text != "Base arch" {
+		t.Fatalf("expected plugin prompt without overrides, got %q %v", text, err)
+	}
+
+	writePromptOverride(t, filepath.Join(root, ".dun", "prompts"), "create-architecture.md", "Ours for {{.CheckID}}")
+	text, _, err = renderPromptText(root, plugin, config, "arch", nil, "auto")
+	if err != nil || text != "Ours for arch" {
+		t.Fatalf("expected project override, got %q %v", text, err)
+	}
+
+	plugin.Manifest.ID = "other"
+	text, _, err = renderPromptText(root, plugin, config, "arch", nil, "auto")
+	if err != nil || text != "Base arch" {
+		t.Fatalf("expected override to apply only to its plugin, got %q %v", text, err)
+	}
+}
+
+func TestPromptOverrideUsesRepoRootForSubprojects(t *testing.T) {
+	root, _, plugin := setupPromptOverride(t)
+	plugin.repoRoot = root
+	writePromptOverride(t, filepath.Join(root, ".dun", "prompts"), "create-architecture.md", "Ours for {{.CheckID}}")
+
+	sub := filepath.Join(root, "services", "api")
+	text, _, err := renderPromptText(sub, plugin, AgentCheckConfig{Prompt: "prompts/create-architecture.md"}, "services/api:arch", nil, "auto")
+	if err != nil || text != "Ours for services/api:arch" {
+		t.Fatalf("expected repo override in a sub-project check, got %q %v", text, err)
+	}
+}
+
+func TestPromptOverrideCallsBaseFromDefinedTemplates(t *testing.T) {
+	root, _, plugin := setupPromptOverride(t)
+	writePromptOverride(t, filepath.Join(root, ".dun", "prompts"), "create-architecture.md",
+		`{{ define "wrapped" }}<{{ template "base" . }}>{{ end }}{{ block "body" . }}[{{ template "base" . }}]{{ end }}{{ template "wrapped" . }}`)
+
+	text, _, err := renderPromptText(root, plugin, AgentCheckConfig{Prompt: "prompts/create-architecture.md"}, "arch", nil, "auto")
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if text != "[Base arch]<Base arch>" {
+		t.Fatalf("unexpected prompt %q", text)
+	}
+}
+
+func TestPromptOverrideParseError(t *testing.T) {
+	root, _, plugin := setupPromptOverride(t)
+	writePromptOverride(t, filepath.Join(root, ".dun", "prompts"), "create-architecture.md", `{{ template "base" .`)
+	if _, _, err := renderPromptText(root, plugin, AgentCheckConfig{Prompt: "prompts/create-architecture.md"}, "arch", nil, "auto"); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
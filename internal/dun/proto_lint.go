@@ -0,0 +1,590 @@
+package dun
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var protoPackageName = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+
+// wellKnownProtoTypes lists the types of google/protobuf imports, which are
+// usually not present in the repository.
+var wellKnownProtoTypes = map[string][]string{
+	"google/protobuf/any.proto":        {"Any"},
+	"google/protobuf/duration.proto":   {"Duration"},
+	"google/protobuf/empty.proto":      {"Empty"},
+	"google/protobuf/field_mask.proto": {"FieldMask"},
+	"google/protobuf/struct.proto":     {"Struct", "Value", "ListValue", "NullValue"},
+	"google/protobuf/timestamp.proto":  {"Timestamp"},
+	"google/protobuf/wrappers.proto": {"DoubleValue", "FloatValue", "Int64Value", "UInt64Value",
+		"Int32Value", "UInt32Value", "BoolValue", "StringValue", "BytesValue"},
+}
+
+type protoToken struct {
+	Text string
+	Line int
+}
+
+type protoImport struct {
+	Path   string
+	Public bool
+	Line   int
+}
+
+type protoField struct {
+	Name   string
+	Number int
+	Line   int
+}
+
+type protoMessage struct {
+	Name     string
+	Line     int
+	Fields   []protoField
+	Reserved [][2]int
+}
+
+type protoRPC struct {
+	Service string
+	Name    string
+	Line    int
+}
+
+// protoFile is the descriptor-level content of a .proto file: enough to lint
+// it and to resolve imports and RPCs without protoc.
+type protoFile struct {
+	Package     string
+	PackageLine int
+	Imports     []protoImport
+	Messages    []protoMessage
+	RPCs        []protoRPC
+	// Names are the top-level messages, enums, services and extension fields.
+	Names []string
+	// Refs are the type and option names the file uses.
+	Refs []string
+}
+
+func runProtoLint(root string, def CheckDefinition, config ProtoLintConfig) (CheckResult, error) {
+	files, err := contractFiles(root, config.Files, func(path string) bool {
+		return filepath.Ext(path) == ".proto"
+	})
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if len(files) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "skip",
+			Signal: "no protobuf files",
+		}, nil
+	}
+	all, err := contractFiles(root, nil, func(path string) bool {
+		return filepath.Ext(path) == ".proto"
+	})
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	var issues []Issue
+	for _, file := range files {
+		parsed, err := parseProtoFile(filepath.Join(root, file))
+		if err != nil {
+			issues = append(issues, Issue{
+				ID:       "proto:" + file + ":0:parse",
+				Summary:  err.Error(),
+				Path:     file,
+				Severity: "fail",
+			})
+			continue
+		}
+		issues = append(issues, lintProtoFile(root, file, parsed, all)...)
+	}
+	return contractLintResult(def, "protobuf", len(files), issues), nil
+}
+
+// lintProtoFile reports field number reuse, unused imports and package naming.
+func lintProtoFile(root string, file string, parsed *protoFile, all []string) []Issue {
+	var issues []Issue
+	add := func(line int, rule string, severity string, summary string) {
+		issues = append(issues, Issue{
+			ID:       fmt.Sprintf("proto:%s:%d:%s", file, line, rule),
+			Summary:  summary,
+			Path:     file,
+			Line:     line,
+			Severity: severity,
+		})
+	}
+
+	switch {
+	case parsed.Package == "":
+		add(1, "package", "warn", "missing package declaration")
+	case !protoPackageName.MatchString(parsed.Package):
+		add(parsed.PackageLine, "package", "warn", fmt.Sprintf("package %q should be lower_snake_case, dot separated", parsed.Package))
+	}
+
+	for _, msg := range parsed.Messages {
+		used := map[int]string{}
+		for _, field := range msg.Fields {
+			if other, ok := used[field.Number]; ok {
+				add(field.Line, "field-number", "fail", fmt.Sprintf("%s: field %s reuses number %d of %s", msg.Name, field.Name, field.Number, other))
+				continue
+			}
+			used[field.Number] = field.Name
+			for _, r := range msg.Reserved {
+				if field.Number >= r[0] && field.Number <= r[1] {
+					add(field.Line, "field-number", "fail", fmt.Sprintf("%s: field %s uses reserved number %d", msg.Name, field.Name, field.Number))
+					break
+				}
+			}
+		}
+	}
+
+	for _, imp := range parsed.Imports {
+		if imp.Public {
+			continue
+		}
+		names, pkg, ok := protoImportNames(root, file, imp.Path, all)
+		if !ok {
+			continue
+		}
+		if !protoImportUsed(parsed.Refs, pkg, names) {
+			add(imp.Line, "unused-import", "warn", fmt.Sprintf("import %q is not used", imp.Path))
+		}
+	}
+	return issues
+}
+
+// protoImportNames returns the top-level names and package of an import,
+// found relative to the file, the root, or as a suffix of a proto file in the
+// tree. ok is false when the import cannot be resolved.
+func protoImportNames(root string, file string, path string, all []string) ([]string, string, bool) {
+	if names, ok := wellKnownProtoTypes[path]; ok {
+		return names, "google.protobuf", true
+	}
+	candidates := []string{filepath.Join(root, filepath.Dir(file), path), filepath.Join(root, path)}
+	for _, other := range all {
+		if strings.HasSuffix(other, "/"+path) {
+			candidates = append(candidates, filepath.Join(root, other))
+		}
+	}
+	for _, candidate := range candidates {
+		if !fileExists(candidate) {
+			continue
+		}
+		parsed, err := parseProtoFile(candidate)
+		if err != nil {
+			return nil, "", false
+		}
+		return parsed.Names, parsed.Package, true
+	}
+	return nil, "", false
+}
+
+func protoImportUsed(refs []string, pkg string, names []string) bool {
+	for _, ref := range refs {
+		ref = strings.TrimPrefix(ref, ".")
+		for _, name := range names {
+			full := name
+			if pkg != "" {
+				full = pkg + "." + name
+			}
+			if ref == name || ref == full || strings.HasPrefix(ref, name+".") || strings.HasPrefix(ref, full+".") || strings.HasSuffix(full, "."+ref) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseProtoFile reads and parses a .proto file.
+func parseProtoFile(path string) (*protoFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parseProto(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	return parsed, nil
+}
+
+// parseProto parses proto2/proto3 source into a protoFile.
+func parseProto(src string) (*protoFile, error) {
+	p := &protoParser{tokens: tokenizeProto(src), file: &protoFile{}}
+	if err := p.parseFile(); err != nil {
+		return nil, err
+	}
+	sort.Strings(p.file.Names)
+	return p.file, nil
+}
+
+// tokenizeProto splits source into identifiers, numbers, strings and
+// punctuation, dropping comments.
+func tokenizeProto(src string) []protoToken {
+	var tokens []protoToken
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				end = len(src) - i - 2
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				j = len(src) - 1
+			}
+			tokens = append(tokens, protoToken{Text: src[i : j+1], Line: line})
+			i = j + 1
+		case isProtoIdentChar(c) || c == '.':
+			j := i
+			for j < len(src) && (isProtoIdentChar(src[j]) || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, protoToken{Text: src[i:j], Line: line})
+			i = j
+		default:
+			tokens = append(tokens, protoToken{Text: string(c), Line: line})
+			i++
+		}
+	}
+	return tokens
+}
+
+func isProtoIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+type protoParser struct {
+	tokens []protoToken
+	pos    int
+	file   *protoFile
+}
+
+func (p *protoParser) peek() protoToken {
+	if p.pos >= len(p.tokens) {
+		return protoToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *protoParser) next() protoToken {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *protoParser) expect(text string) error {
+	tok := p.next()
+	if tok.Text != text {
+		return fmt.Errorf("line %d: expected %q, got %q", tok.Line, text, tok.Text)
+	}
+	return nil
+}
+
+// skipStatement skips to the end of the current statement or block.
+func (p *protoParser) skipStatement() {
+	depth := 0
+	for p.pos < len(p.tokens) {
+		tok := p.next()
+		switch tok.Text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth <= 0 {
+				return
+			}
+		case ";":
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+// option records the option name, which references an extension when it is
+// parenthesized, and skips the value.
+func (p *protoParser) option() {
+	if p.peek().Text == "(" {
+		p.next()
+		p.file.Refs = append(p.file.Refs, p.next().Text)
+	}
+	p.skipStatement()
+}
+
+func (p *protoParser) parseFile() error {
+	for p.pos < len(p.tokens) {
+		tok := p.next()
+		switch tok.Text {
+		case "syntax", "edition":
+			p.skipStatement()
+		case "package":
+			name := p.next()
+			p.file.Package = name.Text
+			p.file.PackageLine = name.Line
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case "import":
+			imp := protoImport{Line: tok.Line}
+			if mod := p.peek().Text; mod == "public" || mod == "weak" {
+				imp.Public = mod == "public"
+				p.next()
+			}
+			imp.Path = strings.Trim(p.next().Text, `"'`)
+			p.file.Imports = append(p.file.Imports, imp)
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case "option":
+			p.option()
+		case "message":
+			name := p.next().Text
+			p.file.Names = append(p.file.Names, name)
+			if err := p.parseMessage(name, tok.Line); err != nil {
+				return err
+			}
+		case "enum":
+			p.file.Names = append(p.file.Names, p.next().Text)
+			p.skipStatement()
+		case "service":
+			name := p.next().Text
+			p.file.Names = append(p.file.Names, name)
+			if err := p.parseService(name); err != nil {
+				return err
+			}
+		case "extend":
+			if err := p.parseExtend(); err != nil {
+				return err
+			}
+		case ";":
+		default:
+			return fmt.Errorf("line %d: unexpected %q", tok.Line, tok.Text)
+		}
+	}
+	return nil
+}
+
+// parseMessage parses a message body, including nested messages and oneofs.
+// Nested messages are recorded as "Outer.Inner".
+func (p *protoParser) parseMessage(name string, line int) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	msg := protoMessage{Name: name, Line: line}
+	if err := p.parseMessageBody(&msg, name); err != nil {
+		return err
+	}
+	p.file.Messages = append(p.file.Messages, msg)
+	return nil
+}
+
+func (p *protoParser) parseMessageBody(msg *protoMessage, name string) error {
+	for {
+		tok := p.next()
+		switch tok.Text {
+		case "":
+			return fmt.Errorf("message %s: unexpected end of file", name)
+		case "}":
+			return nil
+		case ";":
+		case "option":
+			p.option()
+		case "message":
+			inner := p.next().Text
+			if err := p.parseMessage(name+"."+inner, tok.Line); err != nil {
+				return err
+			}
+		case "enum":
+			p.next()
+			p.skipStatement()
+		case "extend":
+			if err := p.parseExtend(); err != nil {
+				return err
+			}
+		case "extensions":
+			p.skipStatement()
+		case "reserved":
+			msg.Reserved = append(msg.Reserved, p.parseReserved()...)
+		case "oneof":
+			p.next()
+			if err := p.expect("{"); err != nil {
+				return err
+			}
+			if err := p.parseMessageBody(msg, name); err != nil {
+				return err
+			}
+		default:
+			p.pos--
+			field, err := p.parseField()
+			if err != nil {
+				return err
+			}
+			msg.Fields = append(msg.Fields, field)
+		}
+	}
+}
+
+// parseField parses "[label] type name = number [options];", including
+// map<K, V> fields and proto2 groups.
+func (p *protoParser) parseField() (protoField, error) {
+	tok := p.next()
+	if tok.Text == "optional" || tok.Text == "required" || tok.Text == "repeated" {
+		tok = p.next()
+	}
+	if tok.Text == "map" && p.peek().Text == "<" {
+		p.next()
+		p.next()
+		if err := p.expect(","); err != nil {
+			return protoField{}, err
+		}
+		p.file.Refs = append(p.file.Refs, p.next().Text)
+		if err := p.expect(">"); err != nil {
+			return protoField{}, err
+		}
+	} else {
+		p.file.Refs = append(p.file.Refs, tok.Text)
+	}
+	name := p.next()
+	if err := p.expect("="); err != nil {
+		return protoField{}, err
+	}
+	number, err := strconv.ParseInt(p.next().Text, 0, 32)
+	if err != nil {
+		return protoField{}, fmt.Errorf("line %d: field %s: invalid number", name.Line, name.Text)
+	}
+	for p.peek().Text == "[" {
+		for p.pos < len(p.tokens) && p.peek().Text != "]" {
+			if p.next().Text == "(" {
+				p.file.Refs = append(p.file.Refs, p.next().Text)
+			}
+		}
+		p.next()
+	}
+	if p.peek().Text == "{" {
+		// proto2 group: the body is its own message.
+		p.skipStatement()
+	} else if err := p.expect(";"); err != nil {
+		return protoField{}, err
+	}
+	return protoField{Name: name.Text, Number: int(number), Line: name.Line}, nil
+}
+
+// parseReserved returns the numeric ranges of a reserved statement.
+func (p *protoParser) parseReserved() [][2]int {
+	var ranges [][2]int
+	for p.pos < len(p.tokens) {
+		tok := p.next()
+		if tok.Text == ";" {
+			return ranges
+		}
+		start, err := strconv.Atoi(tok.Text)
+		if err != nil {
+			continue
+		}
+		end := start
+		if p.peek().Text == "to" {
+			p.next()
+			if limit := p.next().Text; limit == "max" {
+				end = 536870911
+			} else if n, err := strconv.Atoi(limit); err == nil {
+				end = n
+			}
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges
+}
+
+func (p *protoParser) parseService(name string) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		tok := p.next()
+		switch tok.Text {
+		case "":
+			return fmt.Errorf("service %s: unexpected end of file", name)
+		case "}":
+			return nil
+		case "option":
+			p.option()
+		case "rpc":
+			rpc := p.next()
+			p.file.RPCs = append(p.file.RPCs, protoRPC{Service: name, Name: rpc.Text, Line: rpc.Line})
+			for p.pos < len(p.tokens) {
+				t := p.peek().Text
+				if t == ";" || t == "{" {
+					break
+				}
+				p.next()
+				if t != "(" && t != ")" && t != "returns" && t != "stream" {
+					p.file.Refs = append(p.file.Refs, t)
+				}
+			}
+			if p.peek().Text == "{" {
+				p.next()
+				for p.peek().Text != "}" && p.pos < len(p.tokens) {
+					if p.next().Text == "option" {
+						p.option()
+					}
+				}
+			}
+			p.next()
+		case ";":
+		default:
+			return fmt.Errorf("line %d: unexpected %q in service %s", tok.Line, tok.Text, name)
+		}
+	}
+}
+
+// parseExtend records the extended type as a reference and the extension
+// fields as names other files can use as options.
+func (p *protoParser) parseExtend() error {
+	p.file.Refs = append(p.file.Refs, p.next().Text)
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for p.peek().Text != "}" {
+		if p.pos >= len(p.tokens) {
+			return fmt.Errorf("extend: unexpected end of file")
+		}
+		if p.peek().Text == ";" {
+			p.next()
+			continue
+		}
+		field, err := p.parseField()
+		if err != nil {
+			return err
+		}
+		p.file.Names = append(p.file.Names, field.Name)
+	}
+	p.next()
+	return nil
+}
@@ -0,0 +1,115 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseProto(t *testing.T) {
+	src := `syntax = "proto3";
+/* block
+   comment */
+package acme.pets.v1;
+
+import "google/protobuf/timestamp.proto";
+import public "other.proto";
+
+option go_package = "example.com/pets";
+
+message Pet {
+  reserved 5, 10 to 12;
+  string name = 1 [(validate.rules).string.min_len = 1];
+  map<string, Tag> tags = 2;
+  oneof kind {
+    Dog dog = 3;
+    Cat cat = 4;
+  }
+  message Tag { string value = 1; }
+  enum Size { SIZE_UNSPECIFIED = 0; }
+}
+
+service PetService {
+  rpc GetPet(GetPetRequest) returns (Pet);
+  rpc Watch(stream WatchRequest) returns (stream Pet) {
+    option (google.api.http) = { get: "/v1/pets" };
+  }
+}
+`
+	parsed, err := parseProto(src)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if parsed.Package != "acme.pets.v1" || parsed.PackageLine != 4 {
+		t.Fatalf("unexpected package %q at %d", parsed.Package, parsed.PackageLine)
+	}
+	if len(parsed.Imports) != 2 || !parsed.Imports[1].Public {
+		t.Fatalf("unexpected imports %+v", parsed.Imports)
+	}
+	if len(parsed.Messages) != 2 || parsed.Messages[0].Name != "Pet.Tag" {
+		t.Fatalf("unexpected messages %+v", parsed.Messages)
+	}
+	pet := parsed.Messages[1]
+	if len(pet.Fields) != 4 || pet.Fields[3].Number != 4 || len(pet.Reserved) != 2 || pet.Reserved[1] != [2]int{10, 12} {
+		t.Fatalf("unexpected Pet %+v", pet)
+	}
+	if len(parsed.RPCs) != 2 || parsed.RPCs[1].Service != "PetService" || parsed.RPCs[1].Name != "Watch" {
+		t.Fatalf("unexpected rpcs %+v", parsed.RPCs)
+	}
+}
+
+func TestLintProtoFile(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "proto", "common"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, filepath.Join(root, "proto", "common", "money.proto"), "syntax = \"proto3\";\npackage common;\nmessage Money { int64 units = 1; }\n")
+	writeFile(t, filepath.Join(root, "proto", "api.proto"), `syntax = "proto3";
+package Acme.API;
+import "common/money.proto";
+import "google/protobuf/timestamp.proto";
+import "unknown/thing.proto";
+message Order {
+  reserved 3;
+  string id = 1;
+  string note = 1;
+  google.protobuf.Timestamp at = 3;
+}
+`)
+	res, err := runProtoLint(root, CheckDefinition{ID: "proto-lint"}, ProtoLintConfig{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	want := map[string]bool{
+		"proto:proto/api.proto:2:package":       false,
+		"proto:proto/api.proto:9:field-number":  false,
+		"proto:proto/api.proto:10:field-number": false,
+		"proto:proto/api.proto:3:unused-import": false,
+	}
+	for _, issue := range res.Issues {
+		if _, ok := want[issue.ID]; !ok {
+			t.Fatalf("unexpected issue %+v", issue)
+		}
+		want[issue.ID] = true
+	}
+	for id, seen := range want {
+		if !seen {
+			t.Fatalf("missing issue %s in %+v", id, res.Issues)
+		}
+	}
+	if res.Status != "fail" {
+		t.Fatalf("expected fail, got %s", res.Status)
+	}
+}
+
+func TestProtoLintReportsParseErrors(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "bad.proto"), "syntax = \"proto3\";\nmessage {\n")
+	res, err := runProtoLint(root, CheckDefinition{ID: "proto-lint"}, ProtoLintConfig{Files: []string{"*.proto"}})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 1 || res.Issues[0].ID != "proto:bad.proto:0:parse" {
+		t.Fatalf("expected parse failure, got %+v", res)
+	}
+}
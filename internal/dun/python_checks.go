@@ -0,0 +1,437 @@
+package dun
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// pythonEnv describes the interpreter the python checks run under.
+type pythonEnv struct {
+	Root   string
+	Venv   string // absolute virtualenv directory, if any
+	BinDir string
+}
+
+var mypyTextLine = regexp.MustCompile(`^(.+?):(\d+):(?:\d+:)? (error|warning|note): (.*?)(?:  \[([\w-]+)\])?$`)
+
+// detectPythonEnv finds a project virtualenv (.venv, then venv) or an active
+// VIRTUAL_ENV.
+func detectPythonEnv(root string) pythonEnv {
+	env := pythonEnv{Root: root}
+	candidates := []string{filepath.Join(root, ".venv"), filepath.Join(root, "venv")}
+	if active := os.Getenv("VIRTUAL_ENV"); active != "" {
+		candidates = append(candidates, active)
+	}
+	for _, dir := range candidates {
+		bin := filepath.Join(dir, pythonBinDirName())
+		if _, err := os.Stat(filepath.Join(bin, pythonExecutableName("python"))); err == nil {
+			env.Venv = dir
+			env.BinDir = bin
+			return env
+		}
+	}
+	return env
+}
+
+func pythonBinDirName() string {
+	if runtime.GOOS == "windows" {
+		return "Scripts"
+	}
+	return "bin"
+}
+
+func pythonExecutableName(name string) string {
+	if runtime.GOOS == "windows" {
+		return name + ".exe"
+	}
+	return name
+}
+
+// toolPath prefers the virtualenv's copy of a tool over PATH.
+func (e pythonEnv) toolPath(tool string) string {
+	if e.BinDir != "" {
+		local := filepath.Join(e.BinDir, pythonExecutableName(tool))
+		if _, err := os.Stat(local); err == nil {
+			return local
+		}
+	}
+	if path, err := exec.LookPath(tool); err == nil {
+		return path
+	}
+	return ""
+}
+
+// commandEnv activates the virtualenv for child processes.
+func (e pythonEnv) commandEnv(extra map[string]string) map[string]string {
+	env := map[string]string{}
+	if e.Venv != "" {
+		env["VIRTUAL_ENV"] = e.Venv
+		env["PATH"] = e.BinDir + string(os.PathListSeparator) + os.Getenv("PATH")
+	}
+	for k, v := range extra {
+		env[k] = v
+	}
+	return env
+}
+
+func (e pythonEnv) installHint(tool string) string {
+	switch {
+	case e.Venv != "":
+		python := filepath.Join(e.BinDir, pythonExecutableName("python"))
+		if rel, err := filepath.Rel(e.Root, python); err == nil && !strings.HasPrefix(rel, "..") {
+			python = rel
+		}
+		return python + " -m pip install " + tool
+	case fileExists(filepath.Join(e.Root, "uv.lock")):
+		return "uv add --dev " + tool
+	case fileExists(filepath.Join(e.Root, "poetry.lock")):
+		return "poetry add --group dev " + tool
+	default:
+		return "pip install " + tool
+	}
+}
+
+func pythonToolMissing(def CheckDefinition, env pythonEnv, tool string) CheckResult {
+	where := "on PATH"
+	if env.Venv != "" {
+		where = "in " + filepath.Base(env.Venv) + " or on PATH"
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "skip",
+		Signal: tool + " missing",
+		Detail: tool + " not found " + where,
+		Next:   env.installHint(tool),
+	}
+}
+
+type ruffDiagnostic struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Filename string `json:"filename"`
+	Location struct {
+		Row int `json:"row"`
+	} `json:"location"`
+	Fix *struct {
+		Message string `json:"message"`
+	} `json:"fix"`
+}
+
+func runPythonLint(root string, def CheckDefinition, config PythonCheckConfig) (CheckResult, error) {
+	env := detectPythonEnv(root)
+	ruff := env.toolPath("ruff")
+	if ruff == "" {
+		return pythonToolMissing(def, env, "ruff"), nil
+	}
+	run, err := runToolCommand(root, config.Timeout, env.commandEnv(config.Env), ruff, "check", "--output-format", "json", ".")
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if run.TimedOut {
+		return CheckResult{ID: def.ID, Status: "fail", Signal: "ruff timed out", Next: "ruff check ."}, nil
+	}
+	var diagnostics []ruffDiagnostic
+	if err := json.Unmarshal(run.Stdout, &diagnostics); err != nil {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "fail",
+			Signal: "ruff failed",
+			Detail: trimOutput(run.Combined()),
+			Next:   "ruff check .",
+		}, nil
+	}
+	if len(diagnostics) == 0 {
+		return CheckResult{ID: def.ID, Status: "pass", Signal: "ruff passed"}, nil
+	}
+
+	var issues []Issue
+	fixable := 0
+	for _, d := range diagnostics {
+		path := toolRelPath(root, d.Filename)
+		code := d.Code
+		if code == "" {
+			code = "syntax"
+		}
+		issue := Issue{
+			ID:       fmt.Sprintf("ruff:%s:%d:%s", path, d.Location.Row, code),
+			Summary:  code + ": " + d.Message,
+			Path:     path,
+			Line:     d.Location.Row,
+			Severity: "fail",
+		}
+		if d.Fix != nil {
+			fixable++
+			issue.Detail = "fix: " + d.Fix.Message
+		}
+		issues = append(issues, issue)
+	}
+	next := "ruff check ."
+	if fixable > 0 {
+		next = "ruff check --fix ."
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("ruff reported %d problems (%d fixable)", len(issues), fixable),
+		Detail: issues[0].Path + ": " + issues[0].Summary,
+		Next:   next,
+		Issues: issues,
+	}, nil
+}
+
+type mypyDiagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Message  string `json:"message"`
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+}
+
+func runPythonTypecheck(root string, def CheckDefinition, config PythonCheckConfig) (CheckResult, error) {
+	env := detectPythonEnv(root)
+	mypy := env.toolPath("mypy")
+	if mypy == "" {
+		return pythonToolMissing(def, env, "mypy"), nil
+	}
+	args := []string{"--output", "json"}
+	if env.Venv != "" {
+		args = append(args, "--python-executable", filepath.Join(env.BinDir, pythonExecutableName("python")))
+		if rel, err := filepath.Rel(root, env.Venv); err == nil && !strings.HasPrefix(rel, "..") {
+			args = append(args, "--exclude", "^"+regexp.QuoteMeta(filepath.ToSlash(rel))+"/")
+		}
+	}
+	args = append(args, ".")
+	run, err := runToolCommand(root, config.Timeout, env.commandEnv(config.Env), mypy, args...)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	// mypy before 1.11 has no JSON output; fall back to its text format.
+	if run.ExitCode == 2 && strings.Contains(string(run.Stderr), "unrecognized arguments") {
+		args = args[2:]
+		if run, err = runToolCommand(root, config.Timeout, env.commandEnv(config.Env), mypy, args...); err != nil {
+			return CheckResult{}, err
+		}
+	}
+	if run.TimedOut {
+		return CheckResult{ID: def.ID, Status: "fail", Signal: "mypy timed out", Next: "mypy ."}, nil
+	}
+
+	diagnostics := parseMypyOutput(run.Stdout)
+	var issues []Issue
+	for _, d := range diagnostics {
+		if d.Severity != "error" {
+			continue
+		}
+		path := filepath.ToSlash(d.File)
+		code := d.Code
+		if code == "" {
+			code = "error"
+		}
+		issues = append(issues, Issue{
+			ID:       fmt.Sprintf("mypy:%s:%d:%s", path, d.Line, code),
+			Summary:  code + ": " + d.Message,
+			Path:     path,
+			Line:     d.Line,
+			Severity: "fail",
+		})
+	}
+	if len(issues) == 0 {
+		if run.ExitCode != 0 {
+			return CheckResult{
+				ID:     def.ID,
+				Status: "fail",
+				Signal: "mypy failed",
+				Detail: trimOutput(run.Combined()),
+				Next:   "mypy .",
+			}, nil
+		}
+		return CheckResult{ID: def.ID, Status: "pass", Signal: "mypy passed"}, nil
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("%d mypy errors", len(issues)),
+		Detail: issues[0].Path + ": " + issues[0].Summary,
+		Next:   "mypy .",
+		Issues: issues,
+	}, nil
+}
+
+// parseMypyOutput reads JSON lines or, failing that, mypy's text format.
+func parseMypyOutput(output []byte) []mypyDiagnostic {
+	var out []mypyDiagnostic
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "{") {
+			var d mypyDiagnostic
+			if err := json.Unmarshal([]byte(line), &d); err == nil {
+				out = append(out, d)
+			}
+			continue
+		}
+		match := mypyTextLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		lineNo, _ := strconv.Atoi(match[2])
+		out = append(out, mypyDiagnostic{
+			File:     match[1],
+			Line:     lineNo,
+			Severity: match[3],
+			Message:  match[4],
+			Code:     match[5],
+		})
+	}
+	return out
+}
+
+type junitSuites struct {
+	Suites []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Tests int             `xml:"tests,attr"`
+	Cases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	File      string        `xml:"file,attr"`
+	Line      int           `xml:"line,attr"`
+	Failure   *junitFailure `xml:"failure"`
+	Error     *junitFailure `xml:"error"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// pytestNoTestsCollected is pytest's exit code when nothing was collected.
+const pytestNoTestsCollected = 5
+
+func runPythonTest(root string, def CheckDefinition, config PythonCheckConfig) (CheckResult, error) {
+	env := detectPythonEnv(root)
+	pytest := env.toolPath("pytest")
+	if pytest == "" {
+		return pythonToolMissing(def, env, "pytest"), nil
+	}
+
+	reportFile, err := os.CreateTemp("", "dun-pytest-*.xml")
+	if err != nil {
+		return CheckResult{}, err
+	}
+	reportPath := reportFile.Name()
+	reportFile.Close()
+	defer os.Remove(reportPath)
+
+	// xunit1 keeps the file and line attributes on each testcase.
+	args := []string{"--junitxml=" + reportPath, "-o", "junit_family=xunit1", "-q"}
+	run, err := runToolCommand(root, config.Timeout, env.commandEnv(config.Env), pytest, args...)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if run.TimedOut {
+		return CheckResult{ID: def.ID, Status: "fail", Signal: "pytest timed out", Next: "pytest"}, nil
+	}
+	if run.ExitCode == pytestNoTestsCollected {
+		return CheckResult{ID: def.ID, Status: "skip", Signal: "no tests collected"}, nil
+	}
+
+	data, _ := os.ReadFile(reportPath)
+	total, issues, err := parseJUnitReport(data)
+	if err != nil {
+		return toolExitResult(def, run, "pytest", "pytest"), nil
+	}
+	if len(issues) == 0 {
+		if run.ExitCode != 0 {
+			return toolExitResult(def, run, "pytest", "pytest"), nil
+		}
+		return CheckResult{ID: def.ID, Status: "pass", Signal: fmt.Sprintf("%d tests passed", total)}, nil
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("%d of %d tests failed", len(issues), total),
+		Detail: issues[0].Summary,
+		Next:   "pytest " + strings.TrimPrefix(issues[0].ID, "test:"),
+		Issues: issues,
+	}, nil
+}
+
+// parseJUnitReport returns the test count and one issue per failed or errored
+// test case, identified by its pytest node id.
+func parseJUnitReport(data []byte) (int, []Issue, error) {
+	var suites junitSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		var single junitSuite
+		if err := xml.Unmarshal(data, &single); err != nil {
+			return 0, nil, err
+		}
+		suites.Suites = []junitSuite{single}
+	}
+	total := 0
+	var issues []Issue
+	for _, suite := range suites.Suites {
+		total += suite.Tests
+		for _, tc := range suite.Cases {
+			failure := tc.Failure
+			if failure == nil {
+				failure = tc.Error
+			}
+			if failure == nil {
+				continue
+			}
+			nodeID := tc.Name
+			if tc.File != "" {
+				nodeID = filepath.ToSlash(tc.File) + "::" + pytestClassPath(tc) + tc.Name
+			}
+			detail := failure.Message
+			if text := strings.TrimSpace(failure.Text); text != "" {
+				detail = trimOutput([]byte(text))
+			}
+			issues = append(issues, Issue{
+				ID:       "test:" + nodeID,
+				Summary:  nodeID + " failed",
+				Path:     filepath.ToSlash(tc.File),
+				Line:     pytestLine(tc.Line),
+				Severity: "fail",
+				Detail:   detail,
+			})
+		}
+	}
+	return total, issues, nil
+}
+
+// pytestClassPath returns "Class::" for tests in a class. classname is the
+// dotted module path, followed by the class name when there is one.
+func pytestClassPath(tc junitTestCase) string {
+	module := strings.TrimSuffix(filepath.ToSlash(tc.File), ".py")
+	module = strings.ReplaceAll(module, "/", ".")
+	class := strings.TrimPrefix(tc.ClassName, module)
+	class = strings.TrimPrefix(class, ".")
+	if class == "" || class == tc.ClassName {
+		return ""
+	}
+	return strings.ReplaceAll(class, ".", "::") + "::"
+}
+
+// pytestLine converts JUnit's 0-based line numbers.
+func pytestLine(line int) int {
+	if line <= 0 {
+		return 0
+	}
+	return line + 1
+}
@@ -0,0 +1,156 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// pythonVenv creates .venv/bin with a python stub and the given tool stubs.
+func pythonVenv(t *testing.T, root string, tools map[string]string) string {
+	t.Helper()
+	bin := filepath.Join(root, ".venv", "bin")
+	if err := os.MkdirAll(bin, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	tools["python"] = "exit 0\n"
+	for name, script := range tools {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+			t.Fatalf("write stub: %v", err)
+		}
+	}
+	return bin
+}
+
+func TestDetectPythonEnvPrefersProjectVenv(t *testing.T) {
+	t.Setenv("VIRTUAL_ENV", "")
+	root := t.TempDir()
+	if env := detectPythonEnv(root); env.Venv != "" {
+		t.Fatalf("expected no venv, got %+v", env)
+	}
+	bin := pythonVenv(t, root, map[string]string{"ruff": "exit 0\n"})
+	env := detectPythonEnv(root)
+	if env.BinDir != bin || env.toolPath("ruff") != filepath.Join(bin, "ruff") {
+		t.Fatalf("expected venv tools, got %+v", env)
+	}
+	vars := env.commandEnv(map[string]string{"X": "1"})
+	if vars["VIRTUAL_ENV"] != filepath.Join(root, ".venv") || !strings.HasPrefix(vars["PATH"], bin) || vars["X"] != "1" {
+		t.Fatalf("unexpected env %v", vars)
+	}
+	if hint := env.installHint("mypy"); hint != ".venv/bin/python -m pip install mypy" {
+		t.Fatalf("unexpected hint %q", hint)
+	}
+}
+
+func TestPythonChecksSkipWhenToolsMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("VIRTUAL_ENV", "")
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "uv.lock"), "")
+
+	for _, run := range []func(string, CheckDefinition, PythonCheckConfig) (CheckResult, error){runPythonLint, runPythonTypecheck, runPythonTest} {
+		res, err := run(root, CheckDefinition{ID: "python"}, PythonCheckConfig{})
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		if res.Status != "skip" || !strings.HasPrefix(res.Next, "uv add --dev ") {
+			t.Fatalf("expected skip with uv hint, got %+v", res)
+		}
+	}
+}
+
+func TestPythonLintParsesRuffJSON(t *testing.T) {
+	t.Setenv("VIRTUAL_ENV", "")
+	root := t.TempDir()
+	output := `[{"code":"F401","message":"os imported but unused","filename":"` + filepath.Join(root, "pkg", "app.py") +
+		`","location":{"row":1,"column":8},"fix":{"message":"Remove unused import"}},` +
+		`{"code":"E501","message":"Line too long","filename":"` + filepath.Join(root, "pkg", "app.py") + `","location":{"row":20,"column":89},"fix":null}]`
+	pythonVenv(t, root, map[string]string{"ruff": "echo '" + output + "'\nexit 1\n"})
+
+	res, err := runPythonLint(root, CheckDefinition{ID: "python-lint"}, PythonCheckConfig{})
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	if res.Status != "fail" || res.Signal != "ruff reported 2 problems (1 fixable)" || res.Next != "ruff check --fix ." {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	first := res.Issues[0]
+	if first.ID != "ruff:pkg/app.py:1:F401" || first.Line != 1 || first.Detail != "fix: Remove unused import" {
+		t.Fatalf("unexpected issue %+v", first)
+	}
+}
+
+func TestPythonTypecheckParsesMypy(t *testing.T) {
+	t.Setenv("VIRTUAL_ENV", "")
+	root := t.TempDir()
+	argsFile := filepath.Join(root, "args.txt")
+	jsonLines := `{"file": "pkg/app.py", "line": 4, "column": 2, "message": "Incompatible return value type", "hint": null, "code": "return-value", "severity": "error"}
+{"file": "pkg/app.py", "line": 4, "column": 2, "message": "See docs", "hint": null, "code": null, "severity": "note"}`
+	pythonVenv(t, root, map[string]string{"mypy": "echo \"$*\" > " + argsFile + "\ncat <<'EOF'\n" + jsonLines + "\nEOF\nexit 1\n"})
+
+	res, err := runPythonTypecheck(root, CheckDefinition{ID: "python-typecheck"}, PythonCheckConfig{})
+	if err != nil {
+		t.Fatalf("typecheck: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 1 {
+		t.Fatalf("expected one error, got %s %+v", res.Status, res.Issues)
+	}
+	if issue := res.Issues[0]; issue.ID != "mypy:pkg/app.py:4:return-value" || issue.Line != 4 {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+	args, _ := os.ReadFile(argsFile)
+	want := "--output json --python-executable " + filepath.Join(root, ".venv", "bin", "python") + " --exclude ^\\.venv/ .\n"
+	if string(args) != want {
+		t.Fatalf("unexpected args %q", string(args))
+	}
+}
+
+func TestParseMypyTextOutput(t *testing.T) {
+	output := "pkg/app.py:12: error: Name \"x\" is not defined  [name-defined]\npkg/app.py:13:5: note: hint\nFound 1 error in 1 file\n"
+	got := parseMypyOutput([]byte(output))
+	if len(got) != 2 || got[0].Code != "name-defined" || got[0].Line != 12 || got[0].Message != `Name "x" is not defined` || got[1].Severity != "note" {
+		t.Fatalf("unexpected diagnostics %+v", got)
+	}
+}
+
+func TestPythonTestParsesJUnit(t *testing.T) {
+	t.Setenv("VIRTUAL_ENV", "")
+	root := t.TempDir()
+	report := `<?xml version="1.0" encoding="utf-8"?><testsuites><testsuite name="pytest" errors="0" failures="1" tests="3">` +
+		`<testcase classname="tests.test_app.TestApp" name="test_add" file="tests/test_app.py" line="9"><failure message="assert 3 == 4">tests/test_app.py:11: AssertionError</failure></testcase>` +
+		`<testcase classname="tests.test_app" name="test_ok" file="tests/test_app.py" line="2"/>` +
+		`<testcase classname="tests.test_app" name="test_skip" file="tests/test_app.py" line="4"><skipped message="later"/></testcase>` +
+		`</testsuite></testsuites>`
+	script := "for arg in \"$@\"; do case $arg in --junitxml=*) out=${arg#--junitxml=};; esac; done\n" +
+		"echo '" + report + "' > \"$out\"\nexit 1\n"
+	pythonVenv(t, root, map[string]string{"pytest": script})
+
+	res, err := runPythonTest(root, CheckDefinition{ID: "python-test"}, PythonCheckConfig{})
+	if err != nil {
+		t.Fatalf("test: %v", err)
+	}
+	if res.Status != "fail" || res.Signal != "1 of 3 tests failed" || len(res.Issues) != 1 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	issue := res.Issues[0]
+	if issue.ID != "test:tests/test_app.py::TestApp::test_add" || issue.Path != "tests/test_app.py" || issue.Line != 10 {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+	if issue.Detail != "tests/test_app.py:11: AssertionError" || res.Next != "pytest tests/test_app.py::TestApp::test_add" {
+		t.Fatalf("unexpected detail %q next %q", issue.Detail, res.Next)
+	}
+}
+
+func TestPythonTestNoTestsCollected(t *testing.T) {
+	t.Setenv("VIRTUAL_ENV", "")
+	root := t.TempDir()
+	pythonVenv(t, root, map[string]string{"pytest": "exit 5\n"})
+	res, err := runPythonTest(root, CheckDefinition{ID: "python-test"}, PythonCheckConfig{})
+	if err != nil {
+		t.Fatalf("test: %v", err)
+	}
+	if res.Status != "skip" {
+		t.Fatalf("expected skip, got %+v", res)
+	}
+}
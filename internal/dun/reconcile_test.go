@@ -362,7 +362,7 @@ func TestHelixReconciliationIncludesADRs(t *testing.T) {
 // TestHelixReconcileStackCheckActivates validates the helix-reconcile-stack check
 // is triggered when all required conditions are met.
 func TestHelixReconcileStackCheckActivates(t *testing.T) {
-	plan, err := PlanRepo(fixturePath(t, "../testdata/repos/helix-prd-changed"))
+	plan, err := PlanRepo(fixturePath(t, "../testdata/repos/helix-prd-changed"), DefaultOptions())
 	if err != nil {
 		t.Fatalf("plan repo: %v", err)
 	}
@@ -388,7 +388,7 @@ func TestHelixReconcileStackMultipleRuns(t *testing.T) {
 	var plans []Plan
 
 	for i := 0; i < runs; i++ {
-		plan, err := PlanRepo(root)
+		plan, err := PlanRepo(root, DefaultOptions())
 		if err != nil {
 			t.Fatalf("run %d: plan repo: %v", i, err)
 		}
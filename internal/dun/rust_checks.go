@@ -0,0 +1,383 @@
+package dun
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// cargoMetadata is the subset of `cargo metadata --no-deps` used to find
+// workspace members.
+type cargoMetadata struct {
+	Packages []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"packages"`
+	WorkspaceMembers []string `json:"workspace_members"`
+}
+
+// cargoMessage is one line of `cargo --message-format=json` output.
+type cargoMessage struct {
+	Reason  string           `json:"reason"`
+	Message *rustcDiagnostic `json:"message"`
+}
+
+type rustcDiagnostic struct {
+	Message string `json:"message"`
+	Code    *struct {
+		Code string `json:"code"`
+	} `json:"code"`
+	Level    string            `json:"level"`
+	Spans    []rustcSpan       `json:"spans"`
+	Children []rustcDiagnostic `json:"children"`
+}
+
+type rustcSpan struct {
+	FileName             string  `json:"file_name"`
+	LineStart            int     `json:"line_start"`
+	IsPrimary            bool    `json:"is_primary"`
+	Label                *string `json:"label"`
+	SuggestedReplacement *string `json:"suggested_replacement"`
+}
+
+// cargoMetadataFunc allows mocking in tests.
+var cargoMetadataFunc = cargoWorkspaceMembers
+
+var (
+	libtestFailedLine = regexp.MustCompile(`^test (\S+) \.\.\. FAILED$`)
+	libtestPanicLine  = regexp.MustCompile(`panicked at (\S+?):(\d+):\d+`)
+)
+
+// cargoWorkspaceMembers returns the names of the workspace members, sorted.
+func cargoWorkspaceMembers(root string) ([]string, error) {
+	cmd := exec.Command("cargo", "metadata", "--no-deps", "--format-version", "1")
+	cmd.Dir = root
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("cargo metadata: %w", err)
+	}
+	var meta cargoMetadata
+	if err := json.Unmarshal(output, &meta); err != nil {
+		return nil, fmt.Errorf("parse cargo metadata: %w", err)
+	}
+	members := map[string]bool{}
+	for _, id := range meta.WorkspaceMembers {
+		members[id] = true
+	}
+	var names []string
+	for _, pkg := range meta.Packages {
+		if members[pkg.ID] {
+			names = append(names, pkg.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// expandCargoWorkspace gives each workspace member its own check, with ID
+// "<check-id>:<member>". Single crates and explicit packages stay as they are.
+func expandCargoWorkspace(root string, spec Check, _ Options) []Check {
+	if len(spec.Packages) > 0 {
+		return []Check{spec}
+	}
+	members, err := cargoMetadataFunc(root)
+	if err != nil || len(members) < 2 {
+		return []Check{spec}
+	}
+	var out []Check
+	for _, member := range members {
+		check := spec
+		check.ID = spec.ID + ":" + member
+		check.Packages = []string{member}
+		if spec.Description != "" {
+			check.Description = spec.Description + " (" + member + ")"
+		}
+		out = append(out, check)
+	}
+	return out
+}
+
+func cargoPackageArgs(packages []string) []string {
+	if len(packages) == 0 {
+		return []string{"--workspace"}
+	}
+	var args []string
+	for _, pkg := range packages {
+		args = append(args, "-p", pkg)
+	}
+	return args
+}
+
+func cargoMissing(def CheckDefinition) (CheckResult, bool) {
+	if _, err := exec.LookPath("cargo"); err != nil {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "skip",
+			Signal: "cargo missing",
+			Detail: "cargo not found on PATH",
+			Next:   "Install Rust (https://rustup.rs)",
+		}, true
+	}
+	return CheckResult{}, false
+}
+
+func runRustCheck(root string, def CheckDefinition, config RustCheckConfig) (CheckResult, error) {
+	if res, missing := cargoMissing(def); missing {
+		return res, nil
+	}
+	args := append([]string{"check"}, cargoPackageArgs(config.Packages)...)
+	args = append(args, "--all-targets", "--message-format=json")
+	return runCargoDiagnostics(root, def, config, "cargo check", args)
+}
+
+func runRustClippy(root string, def CheckDefinition, config RustCheckConfig) (CheckResult, error) {
+	if res, missing := cargoMissing(def); missing {
+		return res, nil
+	}
+	if version, err := runToolCommand(root, config.Timeout, config.Env, "cargo", "clippy", "--version"); err != nil || version.ExitCode != 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "skip",
+			Signal: "clippy missing",
+			Detail: "cargo clippy is not installed",
+			Next:   "rustup component add clippy",
+		}, nil
+	}
+	args := append([]string{"clippy"}, cargoPackageArgs(config.Packages)...)
+	args = append(args, "--all-targets", "--message-format=json")
+	return runCargoDiagnostics(root, def, config, "cargo clippy", args)
+}
+
+func runCargoDiagnostics(root string, def CheckDefinition, config RustCheckConfig, label string, args []string) (CheckResult, error) {
+	command := toolCommandLine("cargo", strings.Replace(strings.Join(args, " "), " --message-format=json", "", 1))
+	run, err := runToolCommand(root, config.Timeout, config.Env, "cargo", args...)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if run.TimedOut {
+		return CheckResult{ID: def.ID, Status: "fail", Signal: label + " timed out", Next: command}, nil
+	}
+	issues := parseCargoMessages(run.Stdout)
+	if len(issues) == 0 {
+		return toolExitResult(def, run, command, label), nil
+	}
+	return cargoDiagnosticsResult(def, label, command, issues), nil
+}
+
+func cargoDiagnosticsResult(def CheckDefinition, label string, command string, issues []Issue) CheckResult {
+	failures := 0
+	for _, issue := range issues {
+		if issue.Severity == "fail" {
+			failures++
+		}
+	}
+	status := "warn"
+	if failures > 0 {
+		status = "fail"
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: status,
+		Signal: fmt.Sprintf("%s reported %d errors, %d warnings", label, failures, len(issues)-failures),
+		Detail: issues[0].Path + ": " + issues[0].Summary,
+		Next:   command,
+		Issues: issues,
+	}
+}
+
+// parseCargoMessages maps compiler messages to issues, located at the primary
+// span, with suggested replacements in Detail. Duplicates from building the
+// same file for several targets are dropped.
+func parseCargoMessages(output []byte) []Issue {
+	var issues []Issue
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var msg cargoMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil || msg.Reason != "compiler-message" || msg.Message == nil {
+			continue
+		}
+		diag := msg.Message
+		severity := ""
+		switch diag.Level {
+		case "error", "error: internal compiler error":
+			severity = "fail"
+		case "warning":
+			severity = "warn"
+		default:
+			continue
+		}
+		span := primarySpan(diag.Spans)
+		if span == nil {
+			// Summaries such as "aborting due to 2 previous errors".
+			continue
+		}
+		code := "rustc"
+		if diag.Code != nil && diag.Code.Code != "" {
+			code = diag.Code.Code
+		}
+		path := filepath.ToSlash(span.FileName)
+		id := fmt.Sprintf("rust:%s:%d:%s", path, span.LineStart, code)
+		key := id + "\x00" + diag.Message
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		issues = append(issues, Issue{
+			ID:       id,
+			Summary:  code + ": " + diag.Message,
+			Path:     path,
+			Line:     span.LineStart,
+			Severity: severity,
+			Detail:   rustSuggestions(diag),
+		})
+	}
+	return issues
+}
+
+func primarySpan(spans []rustcSpan) *rustcSpan {
+	for i := range spans {
+		if spans[i].IsPrimary {
+			return &spans[i]
+		}
+	}
+	return nil
+}
+
+// rustSuggestions renders the primary label and help/note children, with
+// their suggested replacements, one per line.
+func rustSuggestions(diag *rustcDiagnostic) string {
+	var lines []string
+	if span := primarySpan(diag.Spans); span != nil && span.Label != nil && *span.Label != "" {
+		lines = append(lines, *span.Label)
+	}
+	for _, child := range diag.Children {
+		if child.Level != "help" && child.Level != "note" {
+			continue
+		}
+		line := child.Level + ": " + child.Message
+		for _, span := range child.Spans {
+			if span.SuggestedReplacement != nil {
+				line += fmt.Sprintf(" (%s:%d: replace with `%s`)", filepath.ToSlash(span.FileName), span.LineStart, *span.SuggestedReplacement)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func runRustTest(root string, def CheckDefinition, config RustCheckConfig) (CheckResult, error) {
+	if res, missing := cargoMissing(def); missing {
+		return res, nil
+	}
+	args := append([]string{"test"}, cargoPackageArgs(config.Packages)...)
+	args = append(args, "--message-format=json")
+	command := toolCommandLine("cargo", append([]string{"test"}, cargoPackageArgs(config.Packages)...)...)
+	run, err := runToolCommand(root, config.Timeout, config.Env, "cargo", args...)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if run.TimedOut {
+		return CheckResult{ID: def.ID, Status: "fail", Signal: "cargo test timed out", Next: command}, nil
+	}
+
+	if diagnostics := parseCargoMessages(run.Stdout); run.ExitCode != 0 {
+		var compileErrors []Issue
+		for _, issue := range diagnostics {
+			if issue.Severity == "fail" {
+				compileErrors = append(compileErrors, issue)
+			}
+		}
+		if len(compileErrors) > 0 {
+			return cargoDiagnosticsResult(def, "cargo test", command, compileErrors), nil
+		}
+	}
+
+	passed, issues := parseLibtestOutput(run.Stdout)
+	if len(issues) == 0 {
+		if run.ExitCode != 0 {
+			return toolExitResult(def, run, command, "cargo test"), nil
+		}
+		return CheckResult{ID: def.ID, Status: "pass", Signal: fmt.Sprintf("%d tests passed", passed)}, nil
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("%d tests failed", len(issues)),
+		Detail: issues[0].Summary,
+		Next:   command + " " + strings.TrimPrefix(issues[0].ID, "test:"),
+		Issues: issues,
+	}, nil
+}
+
+// parseLibtestOutput counts passing tests and returns one issue per failed
+// test, located at its panic when the captured output names one.
+func parseLibtestOutput(output []byte) (int, []Issue) {
+	passed := 0
+	var failed []string
+	captured := map[string][]string{}
+	current := ""
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "{") {
+			continue
+		}
+		if strings.HasPrefix(line, "test ") && strings.HasSuffix(line, " ... ok") {
+			passed++
+			continue
+		}
+		if match := libtestFailedLine.FindStringSubmatch(line); match != nil {
+			failed = append(failed, match[1])
+			continue
+		}
+		if strings.HasPrefix(line, "---- ") && strings.HasSuffix(line, " stdout ----") {
+			current = strings.TrimSuffix(strings.TrimPrefix(line, "---- "), " stdout ----")
+			continue
+		}
+		if current != "" {
+			if line == "" && len(captured[current]) == 0 {
+				continue
+			}
+			if line == "" || line == "failures:" {
+				current = ""
+				continue
+			}
+			captured[current] = append(captured[current], line)
+		}
+	}
+
+	var issues []Issue
+	seen := map[string]bool{}
+	for _, name := range failed {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		issue := Issue{
+			ID:       "test:" + name,
+			Summary:  name + " failed",
+			Severity: "fail",
+			Detail:   strings.Join(captured[name], "\n"),
+		}
+		for _, line := range captured[name] {
+			if match := libtestPanicLine.FindStringSubmatch(line); match != nil {
+				issue.Path = filepath.ToSlash(match[1])
+				issue.Line, _ = strconv.Atoi(match[2])
+				break
+			}
+		}
+		issues = append(issues, issue)
+	}
+	return passed, issues
+}
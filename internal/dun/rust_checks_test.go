@@ -0,0 +1,182 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// stubCargo puts a cargo script on PATH; it records its arguments and prints
+// stdout for the given subcommand.
+func stubCargo(t *testing.T, outputs map[string]string, exitCode string) string {
+	t.Helper()
+	binDir := t.TempDir()
+	argsFile := filepath.Join(binDir, "args.txt")
+	script := "#!/bin/sh\necho \"$*\" >> " + argsFile + "\ncase \"$1 $2\" in\n"
+	for sub, output := range outputs {
+		outFile := filepath.Join(binDir, strings.ReplaceAll(sub, " ", "_")+".out")
+		if err := os.WriteFile(outFile, []byte(output), 0644); err != nil {
+			t.Fatalf("write output: %v", err)
+		}
+		script += "  \"" + sub + "\"*) cat " + outFile + "; exit " + exitCode + ";;\n"
+	}
+	script += "esac\nexit 0\n"
+	if err := os.WriteFile(filepath.Join(binDir, "cargo"), []byte(script), 0755); err != nil {
+		t.Fatalf("write stub: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return argsFile
+}
+
+const cargoErrorMessage = `{"reason":"compiler-artifact","package_id":"core 0.1.0"}
+{"reason":"compiler-message","package_id":"core 0.1.0","message":{"message":"mismatched types","code":{"code":"E0308"},"level":"error","spans":[{"file_name":"crates/core/src/lib.rs","line_start":4,"column_start":5,"is_primary":true,"label":"expected i32, found &str","suggested_replacement":null}],"children":[{"message":"try using a conversion method","level":"help","spans":[{"file_name":"crates/core/src/lib.rs","line_start":4,"column_start":5,"is_primary":true,"label":null,"suggested_replacement":"\"1\".parse().unwrap()"}],"children":[]}]}}
+{"reason":"compiler-message","package_id":"core 0.1.0","message":{"message":"mismatched types","code":{"code":"E0308"},"level":"error","spans":[{"file_name":"crates/core/src/lib.rs","line_start":4,"column_start":5,"is_primary":true,"label":"expected i32, found &str","suggested_replacement":null}],"children":[]}}
+{"reason":"compiler-message","package_id":"core 0.1.0","message":{"message":"aborting due to 1 previous error","code":null,"level":"error","spans":[],"children":[]}}
+`
+
+const clippyWarningMessage = `{"reason":"compiler-message","package_id":"core 0.1.0","message":{"message":"unneeded return statement","code":{"code":"clippy::needless_return"},"level":"warning","spans":[{"file_name":"src/lib.rs","line_start":9,"column_start":5,"is_primary":true,"label":null,"suggested_replacement":null}],"children":[{"message":"remove return","level":"help","spans":[{"file_name":"src/lib.rs","line_start":9,"column_start":5,"is_primary":true,"label":null,"suggested_replacement":"x"}],"children":[]}]}}
+`
+
+func TestRustCheckParsesCompilerMessages(t *testing.T) {
+	argsFile := stubCargo(t, map[string]string{"check -p": cargoErrorMessage}, "101")
+	res, err := runRustCheck(t.TempDir(), CheckDefinition{ID: "rust-check:core"}, RustCheckConfig{Packages: []string{"core"}})
+	if err != nil {
+		t.Fatalf("rust check: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 1 {
+		t.Fatalf("expected one deduplicated error, got %s %+v", res.Status, res.Issues)
+	}
+	issue := res.Issues[0]
+	if issue.ID != "rust:crates/core/src/lib.rs:4:E0308" || issue.Path != "crates/core/src/lib.rs" || issue.Line != 4 {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+	wantDetail := "expected i32, found &str\nhelp: try using a conversion method (crates/core/src/lib.rs:4: replace with `\"1\".parse().unwrap()`)"
+	if issue.Detail != wantDetail {
+		t.Fatalf("unexpected detail %q", issue.Detail)
+	}
+	if res.Next != "cargo check -p core --all-targets" {
+		t.Fatalf("unexpected next %q", res.Next)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if string(args) != "check -p core --all-targets --message-format=json\n" {
+		t.Fatalf("unexpected args %q", string(args))
+	}
+}
+
+func TestRustClippyWarnings(t *testing.T) {
+	stubCargo(t, map[string]string{"clippy --workspace": clippyWarningMessage}, "0")
+	res, err := runRustClippy(t.TempDir(), CheckDefinition{ID: "rust-clippy"}, RustCheckConfig{})
+	if err != nil {
+		t.Fatalf("clippy: %v", err)
+	}
+	if res.Status != "warn" || res.Signal != "cargo clippy reported 0 errors, 1 warnings" {
+		t.Fatalf("unexpected result %s %q", res.Status, res.Signal)
+	}
+	if issue := res.Issues[0]; issue.Summary != "clippy::needless_return: unneeded return statement" || !strings.Contains(issue.Detail, "replace with `x`") {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+}
+
+func TestRustChecksSkipWithoutCargo(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	res, err := runRustTest(t.TempDir(), CheckDefinition{ID: "rust-test"}, RustCheckConfig{})
+	if err != nil {
+		t.Fatalf("rust test: %v", err)
+	}
+	if res.Status != "skip" || res.Next != "Install Rust (https://rustup.rs)" {
+		t.Fatalf("expected skip, got %+v", res)
+	}
+}
+
+func TestRustTestParsesLibtestFailures(t *testing.T) {
+	output := `{"reason":"compiler-artifact","package_id":"core 0.1.0"}
+
+running 3 tests
+test tests::adds ... ok
+test tests::parses ... FAILED
+test tests::ignored ... ignored
+
+failures:
+
+---- tests::parses stdout ----
+
+thread 'tests::parses' panicked at crates/core/src/lib.rs:21:9:
+assertion left == right failed
+note: run with RUST_BACKTRACE=1 environment variable to display a backtrace
+
+failures:
+    tests::parses
+
+test result: FAILED. 1 passed; 1 failed; 1 ignored; 0 measured; 0 filtered out
+`
+	stubCargo(t, map[string]string{"test --workspace": output}, "101")
+	res, err := runRustTest(t.TempDir(), CheckDefinition{ID: "rust-test"}, RustCheckConfig{})
+	if err != nil {
+		t.Fatalf("rust test: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 1 {
+		t.Fatalf("expected one failure, got %s %+v", res.Status, res.Issues)
+	}
+	issue := res.Issues[0]
+	if issue.ID != "test:tests::parses" || issue.Path != "crates/core/src/lib.rs" || issue.Line != 21 {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+	if !strings.HasPrefix(issue.Detail, "thread 'tests::parses' panicked") {
+		t.Fatalf("unexpected detail %q", issue.Detail)
+	}
+	if res.Next != "cargo test --workspace tests::parses" {
+		t.Fatalf("unexpected next %q", res.Next)
+	}
+}
+
+func TestRustTestReportsCompileErrors(t *testing.T) {
+	stubCargo(t, map[string]string{"test --workspace": cargoErrorMessage}, "101")
+	res, err := runRustTest(t.TempDir(), CheckDefinition{ID: "rust-test"}, RustCheckConfig{})
+	if err != nil {
+		t.Fatalf("rust test: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 1 || res.Issues[0].ID != "rust:crates/core/src/lib.rs:4:E0308" {
+		t.Fatalf("expected compile error, got %+v", res)
+	}
+}
+
+func TestExpandCargoWorkspace(t *testing.T) {
+	orig := cargoMetadataFunc
+	t.Cleanup(func() { cargoMetadataFunc = orig })
+	cargoMetadataFunc = func(string) ([]string, error) { return []string{"api", "core"}, nil }
+
+	spec := Check{ID: "rust-test", Type: "rust-test", Description: "Run cargo test"}
+	got := expandCargoWorkspace(t.TempDir(), spec, Options{})
+	if len(got) != 2 || got[0].ID != "rust-test:api" || !reflect.DeepEqual(got[1].Packages, []string{"core"}) || got[1].Description != "Run cargo test (core)" {
+		t.Fatalf("unexpected expansion %+v", got)
+	}
+
+	cargoMetadataFunc = func(string) ([]string, error) { return []string{"solo"}, nil }
+	if got := expandCargoWorkspace(t.TempDir(), spec, Options{}); len(got) != 1 || got[0].ID != "rust-test" {
+		t.Fatalf("expected single crate unchanged, got %+v", got)
+	}
+}
+
+func TestBuildPlanExpandsCargoWorkspaceMembers(t *testing.T) {
+	orig := cargoMetadataFunc
+	t.Cleanup(func() { cargoMetadataFunc = orig })
+	cargoMetadataFunc = func(string) ([]string, error) { return []string{"api", "core"}, nil }
+
+	plugin := Plugin{Manifest: Manifest{ID: "rust", Checks: []Check{
+		{ID: "rust-check", Type: "rust-check"},
+		{ID: "git-status", Type: "git-status"},
+	}}}
+	plan, err := buildPlan(t.TempDir(), []Plugin{plugin}, Options{}, true)
+	if err != nil {
+		t.Fatalf("build plan: %v", err)
+	}
+	var ids []string
+	for _, pc := range plan {
+		ids = append(ids, pc.Check.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"rust-check:api", "rust-check:core", "git-status"}) {
+		t.Fatalf("unexpected plan %v", ids)
+	}
+}
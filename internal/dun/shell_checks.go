@@ -0,0 +1,204 @@
+package dun
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// shellSyntaxLine matches "script.sh: line 3: msg" (bash) and
+// "script.sh: 3: msg" (dash) diagnostics.
+var shellSyntaxLine = regexp.MustCompile(`^(.+?):\s*(?:line\s+)?(\d+):\s*(.+)$`)
+
+type shellcheckReport struct {
+	Comments []struct {
+		File    string `json:"file"`
+		Line    int    `json:"line"`
+		Level   string `json:"level"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"comments"`
+}
+
+func runShellSyntaxCheck(root string, def CheckDefinition, config ShellCheckConfig) (CheckResult, error) {
+	scripts, err := findShellScripts(root)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if len(scripts) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "skip",
+			Signal: "no shell scripts",
+		}, nil
+	}
+
+	var issues []Issue
+	for _, script := range scripts {
+		shell := scriptShell(filepath.Join(root, script))
+		run, err := runToolCommand(root, config.Timeout, config.Env, shell, "-n", script)
+		if err != nil {
+			return CheckResult{}, err
+		}
+		if run.ExitCode == 0 {
+			continue
+		}
+		issues = append(issues, parseShellSyntaxErrors(script, shell, run.Combined())...)
+	}
+
+	shellcheckNote := ""
+	if _, err := exec.LookPath("shellcheck"); err == nil {
+		found, err := runShellcheck(root, config, scripts)
+		if err != nil {
+			return CheckResult{}, err
+		}
+		issues = append(issues, found...)
+	} else {
+		shellcheckNote = "shellcheck not found; only syntax was checked"
+	}
+
+	if len(issues) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: fmt.Sprintf("%d shell scripts passed", len(scripts)),
+			Detail: shellcheckNote,
+		}, nil
+	}
+	status := "warn"
+	for _, issue := range issues {
+		if issue.Severity == "fail" {
+			status = "fail"
+			break
+		}
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: status,
+		Signal: fmt.Sprintf("%d shell script problems", len(issues)),
+		Detail: issues[0].Path + ": " + issues[0].Summary,
+		Next:   "Fix the reported lines; run shellcheck <script> for details.",
+		Issues: issues,
+	}, nil
+}
+
+// findShellScripts lists *.sh and *.bash files, skipping hidden, vendor,
+// testdata and node_modules directories and sub-projects.
+func findShellScripts(root string) ([]string, error) {
+	var scripts []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if skipTreeDir(root, path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".sh", ".bash":
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			scripts = append(scripts, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(scripts)
+	return scripts, err
+}
+
+// scriptShell picks bash for .bash files and bash shebangs, sh otherwise.
+func scriptShell(path string) string {
+	if filepath.Ext(path) == ".bash" {
+		return "bash"
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "sh"
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	if scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#!") && strings.Contains(line, "bash") {
+			return "bash"
+		}
+	}
+	return "sh"
+}
+
+func parseShellSyntaxErrors(script string, shell string, output []byte) []Issue {
+	var issues []Issue
+	for _, line := range strings.Split(string(output), "\n") {
+		match := shellSyntaxLine.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil || !strings.HasSuffix(match[1], script) {
+			continue
+		}
+		lineNo, _ := strconv.Atoi(match[2])
+		issues = append(issues, Issue{
+			ID:       fmt.Sprintf("sh:%s:%d", script, lineNo),
+			Summary:  shell + " -n: " + match[3],
+			Path:     script,
+			Line:     lineNo,
+			Severity: "fail",
+		})
+	}
+	if len(issues) == 0 {
+		issues = append(issues, Issue{
+			ID:       "sh:" + script,
+			Summary:  shell + " -n failed",
+			Path:     script,
+			Severity: "fail",
+			Detail:   trimOutput(output),
+		})
+	}
+	return issues
+}
+
+// runShellcheck reports shellcheck errors (fail) and warnings (warn).
+func runShellcheck(root string, config ShellCheckConfig, scripts []string) ([]Issue, error) {
+	args := append([]string{"--format", "json1", "--severity", "warning"}, scripts...)
+	run, err := runToolCommand(root, config.Timeout, config.Env, "shellcheck", args...)
+	if err != nil {
+		return nil, err
+	}
+	var report shellcheckReport
+	if err := json.Unmarshal(run.Stdout, &report); err != nil {
+		if run.ExitCode == 0 {
+			return nil, nil
+		}
+		return []Issue{{
+			ID:       "shellcheck",
+			Summary:  "shellcheck failed",
+			Severity: "warn",
+			Detail:   trimOutput(run.Combined()),
+		}}, nil
+	}
+	var issues []Issue
+	for _, c := range report.Comments {
+		severity := "warn"
+		if c.Level == "error" {
+			severity = "fail"
+		}
+		path := filepath.ToSlash(c.File)
+		issues = append(issues, Issue{
+			ID:       fmt.Sprintf("shellcheck:%s:%d:SC%d", path, c.Line, c.Code),
+			Summary:  fmt.Sprintf("SC%d: %s", c.Code, c.Message),
+			Path:     path,
+			Line:     c.Line,
+			Severity: severity,
+		})
+	}
+	return issues, nil
+}
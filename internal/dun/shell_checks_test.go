@@ -0,0 +1,104 @@
+package dun
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShellSyntaxCheckReportsSyntaxErrors(t *testing.T) {
+	// Keep sh and bash on PATH but hide any real shellcheck.
+	binDir := t.TempDir()
+	for _, shell := range []string{"sh", "bash"} {
+		path, err := exec.LookPath(shell)
+		if err != nil {
+			t.Skipf("%s not available", shell)
+		}
+		if err := os.Symlink(path, filepath.Join(binDir, shell)); err != nil {
+			t.Fatalf("symlink: %v", err)
+		}
+	}
+	t.Setenv("PATH", binDir)
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "scripts"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, filepath.Join(root, "scripts", "ok.sh"), "#!/bin/sh\necho ok\n")
+	writeFile(t, filepath.Join(root, "scripts", "bad.bash"), "#!/usr/bin/env bash\nif true; then\n  echo hi\n")
+
+	res, err := runShellSyntaxCheck(root, CheckDefinition{ID: "shell-syntax"}, ShellCheckConfig{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 1 {
+		t.Fatalf("expected one failure, got %+v", res)
+	}
+	issue := res.Issues[0]
+	if issue.Path != "scripts/bad.bash" || issue.Line == 0 || !strings.HasPrefix(issue.Summary, "bash -n: ") {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+
+	if err := os.Remove(filepath.Join(root, "scripts", "bad.bash")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	res, err = runShellSyntaxCheck(root, CheckDefinition{ID: "shell-syntax"}, ShellCheckConfig{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Status != "pass" || !strings.Contains(res.Detail, "shellcheck not found") {
+		t.Fatalf("expected pass without shellcheck, got %+v", res)
+	}
+}
+
+func TestShellSyntaxCheckSkipsWithoutScripts(t *testing.T) {
+	res, err := runShellSyntaxCheck(t.TempDir(), CheckDefinition{ID: "shell-syntax"}, ShellCheckConfig{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Status != "skip" {
+		t.Fatalf("expected skip, got %+v", res)
+	}
+}
+
+func TestShellSyntaxCheckUsesShellcheckJSON(t *testing.T) {
+	binDir := t.TempDir()
+	stub := `#!/bin/sh
+cat <<'JSON'
+{"comments":[{"file":"run.sh","line":2,"level":"warning","code":2086,"message":"Double quote to prevent globbing"},{"file":"run.sh","line":3,"level":"error","code":1073,"message":"Broken"}]}
+JSON
+exit 1
+`
+	if err := os.WriteFile(filepath.Join(binDir, "shellcheck"), []byte(stub), 0755); err != nil {
+		t.Fatalf("write stub: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "run.sh"), "#!/bin/sh\necho $1\n")
+
+	res, err := runShellSyntaxCheck(root, CheckDefinition{ID: "shell-syntax"}, ShellCheckConfig{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 2 {
+		t.Fatalf("expected shellcheck issues, got %+v", res)
+	}
+	if res.Issues[0].ID != "shellcheck:run.sh:2:SC2086" || res.Issues[0].Severity != "warn" || res.Issues[1].Severity != "fail" {
+		t.Fatalf("unexpected issues %+v", res.Issues)
+	}
+}
+
+func TestScriptShellFromShebang(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a.sh"), "#!/usr/bin/env bash\n")
+	writeFile(t, filepath.Join(root, "b.sh"), "#!/bin/sh\n")
+	if got := scriptShell(filepath.Join(root, "a.sh")); got != "bash" {
+		t.Fatalf("expected bash, got %s", got)
+	}
+	if got := scriptShell(filepath.Join(root, "b.sh")); got != "sh" {
+		t.Fatalf("expected sh, got %s", got)
+	}
+}
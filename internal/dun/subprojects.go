@@ -0,0 +1,106 @@
+package dun
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// subprojectMarkers are the files that make a directory below the repo root
+// a sub-project with checks of its own.
+var subprojectMarkers = []string{"go.mod", "package.json", "Cargo.toml", "pyproject.toml"}
+
+// DiscoverSubprojects returns the sub-project roots below root as slash
+// separated paths relative to root, in lexical order. Hidden, vendor,
+// testdata and dependency directories are not searched. A module that is a
+// member of a go.work, Cargo or npm workspace declared further up is covered
+// by that workspace's checks and is not a sub-project on its own.
+// Unreadable directories are skipped.
+func DiscoverSubprojects(root string) []string {
+	var subs []string
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() || p == root {
+			return nil
+		}
+		name := d.Name()
+		if isIgnoredGoDir(name) || name == "node_modules" || name == "target" {
+			return filepath.SkipDir
+		}
+		if isSubprojectDir(root, p) {
+			rel, _ := filepath.Rel(root, p)
+			subs = append(subs, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return subs
+}
+
+// isSubprojectDir reports whether dir, below root, is a sub-project that dun
+// checks on its own. Checks that walk the tree skip such directories, so
+// each file is checked once, by its nearest project.
+func isSubprojectDir(root string, dir string) bool {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, marker := range subprojectMarkers {
+		if fileExists(filepath.Join(dir, filepath.FromSlash(marker))) && !inParentWorkspace(root, rel, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipTreeDir reports whether a walk of root's files skips dir: hidden,
+// vendor, testdata and node_modules directories, and sub-projects.
+func skipTreeDir(root string, dir string) bool {
+	if dir == root {
+		return false
+	}
+	name := filepath.Base(dir)
+	return isIgnoredGoDir(name) || name == "node_modules" || isSubprojectDir(root, dir)
+}
+
+// inSubproject reports whether the slash separated path rel, relative to
+// root, lies inside a sub-project of root.
+func inSubproject(root string, rel string) bool {
+	for dir := path.Dir(rel); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if isSubprojectDir(root, filepath.Join(root, filepath.FromSlash(dir))) {
+			return true
+		}
+	}
+	return false
+}
+
+// inParentWorkspace reports whether the marker in rel belongs to a workspace
+// declared in one of its parent directories, up to and including root.
+func inParentWorkspace(root string, rel string, marker string) bool {
+	for dir := path.Dir(rel); ; dir = path.Dir(dir) {
+		if declaresWorkspace(filepath.Join(root, filepath.FromSlash(dir)), marker) {
+			return true
+		}
+		if dir == "." {
+			return false
+		}
+	}
+}
+
+func declaresWorkspace(dir string, marker string) bool {
+	switch marker {
+	case "go.mod":
+		return fileExists(filepath.Join(dir, "go.work"))
+	case "Cargo.toml":
+		raw, err := os.ReadFile(filepath.Join(dir, "Cargo.toml"))
+		return err == nil && strings.Contains(string(raw), "[workspace]")
+	case "package.json":
+		raw, err := os.ReadFile(filepath.Join(dir, "package.json"))
+		return err == nil && strings.Contains(string(raw), `"workspaces"`)
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package dun
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverSubprojects(t *testing.T) {
+	root := t.TempDir()
+	writeAPIDiffFiles(t, root, map[string]string{
+		"go.mod":                            "module example.com/root\n",
+		"services/api/go.mod":               "module example.com/api\n",
+		"services/api/internal/x.go":        "package internal\n",
+		"web/package.json":                  `{"workspaces": ["packages/*"]}`,
+		"web/packages/ui/package.json":      `{"name": "ui"}`,
+		"web/node_modules/dep/package.json": `{"name": "dep"}`,
+		"tools/.dun/config.yaml":            "version: \"1\"\n",
+		"crates/Cargo.toml":                 "[workspace]\nmembers = [\"a\"]\n",
+		"crates/a/Cargo.toml":               "[package]\nname = \"a\"\n",
+		"testdata/fixture/go.mod":           "module fixture\n",
+		".hidden/go.mod":                    "module hidden\n",
+		"docs/README.md":                    "# docs\n",
+	})
+
+	got := DiscoverSubprojects(root)
+	want := []string{"crates", "services/api", "web"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestDiscoverSubprojectsGoWorkspace(t *testing.T) {
+	root := t.TempDir()
+	writeAPIDiffFiles(t, root, map[string]string{
+		"go.work":        "go 1.22\nuse ./a\n",
+		"a/go.mod":       "module a\n",
+		"b/package.json": "{}",
+	})
+	if got := DiscoverSubprojects(root); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Fatalf("expected go.work members to be skipped, got %v", got)
+	}
+}
+
+func TestTreeChecksSkipSubprojects(t *testing.T) {
+	root := t.TempDir()
+	writeAPIDiffFiles(t, root, map[string]string{
+		"build.sh":             "#!/bin/sh\n",
+		"scripts/ci.sh":        "#!/bin/sh\n",
+		"svc/package.json":     "{}",
+		"svc/run.sh":           "#!/bin/sh\n",
+		"svc/Dockerfile":       "FROM alpine\n",
+		"svc/worker/go.mod":    "module example.com/worker\n",
+		"svc/worker/deploy.sh": "#!/bin/sh\n",
+	})
+
+	if got, _ := findShellScripts(root); !reflect.DeepEqual(got, []string{"build.sh", "scripts/ci.sh"}) {
+		t.Fatalf("expected root scripts only, got %v", got)
+	}
+	sub := filepath.Join(root, "svc")
+	if got, _ := findShellScripts(sub); !reflect.DeepEqual(got, []string{"run.sh"}) {
+		t.Fatalf("expected the nested sub-project to be left to itself, got %v", got)
+	}
+	if got, _ := findDockerfiles(root); len(got) != 0 {
+		t.Fatalf("expected the sub-project Dockerfile to be skipped, got %v", got)
+	}
+	if !inSubproject(root, "svc/worker/main.go") || inSubproject(root, "scripts/ci.sh") || inSubproject(sub, "run.sh") {
+		t.Fatalf("unexpected inSubproject results")
+	}
+}
+
+func TestCheckRepoRunsSubprojectChecks(t *testing.T) {
+	orig := loadPlugins
+	loadPlugins = func(string) ([]Plugin, error) {
+		return []Plugin{
+			{Manifest: Manifest{
+				ID:       "go",
+				Version:  "1",
+				Triggers: []Trigger{{Type: "path-exists", Value: "go.mod"}},
+				Checks: []Check{{
+					ID:    "readme",
+					Type:  "rule-set",
+					Rules: []Rule{{Type: "path-exists", Path: "README.md"}},
+				}},
+			}},
+			{Manifest: Manifest{
+				ID:      "repo",
+				Version: "1",
+				Checks:  []Check{{ID: "repo-check", Type: "command", Command: "echo ok"}},
+			}},
+		}, nil
+	}
+	t.Cleanup(func() { loadPlugins = orig })
+
+	root := t.TempDir()
+	writeAPIDiffFiles(t, root, map[string]string{
+		"go.mod":                 "module example.com/root\n",
+		"services/api/go.mod":    "module example.com/api\n",
+		"services/api/README.md": "# api\n",
+		"web/package.json":       "{}",
+	})
+
+	result, err := CheckRepo(root, Options{})
+	if err != nil {
+		t.Fatalf("check repo: %v", err)
+	}
+	statuses := map[string]string{}
+	for _, check := range result.Checks {
+		statuses[check.ID] = check.Status
+	}
+	want := map[string]string{"readme": "fail", "services/api:readme": "pass", "repo-check": "pass"}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Fatalf("expected %v, got %v", want, statuses)
+	}
+
+	plan, err := PlanRepo(root, DefaultOptions())
+	if err != nil {
+		t.Fatalf("plan repo: %v", err)
+	}
+	for _, check := range plan.Checks {
+		if check.ID == "services/api:readme" && check.Scope != "services/api" {
+			t.Fatalf("expected scope services/api, got %+v", check)
+		}
+	}
+}
+
+func TestLoadPluginsReadsProjectPluginsFromRoot(t *testing.T) {
+	isolatePluginSources(t)
+	root := t.TempDir()
+	writeTestPlugin(t, filepath.Join(root, ".dun", "plugins", "local"), "local", "1")
+	t.Chdir(t.TempDir())
+
+	plugins, err := LoadPlugins(root)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	found := false
+	for _, p := range plugins {
+		if p.Manifest.ID == "local" && p.Source == PluginSourceProject {
+			found = true
+		}
+		if p.repoRoot != root {
+			t.Fatalf("expected plugin %s to carry repo root %s, got %q", p.Manifest.ID, root, p.repoRoot)
+		}
+	}
+	if !found {
+		t.Fatalf("expected project plugin from %s outside the working directory", root)
+	}
+}
@@ -0,0 +1,237 @@
+package dun
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultTaskPhases maps conventional task runner targets to phases. Other
+// targets are only picked up when tasks.phases in config names them.
+var defaultTaskPhases = map[string]string{
+	"test":      "test",
+	"lint":      "test",
+	"check":     "test",
+	"vet":       "test",
+	"fmt-check": "build",
+}
+
+// diagnosticIssuePattern matches compiler and linter style "file:line: message"
+// output from whatever the target runs.
+const diagnosticIssuePattern = `(?m)^(?P<file>[^\s:]+\.[A-Za-z0-9]+):(?P<line>\d+)(?::\d+)?:\s*(?P<message>.+)$`
+
+// fileListIssuePattern matches the bare file paths format targets list,
+// skipping echoed commands and runner messages such as "make: *** ...".
+const fileListIssuePattern = `(?m)^(?P<message>(?P<file>[^\s:]+\.[A-Za-z0-9]+))\s*$`
+
+// languageProjectFiles mark projects whose builtin language plugins already
+// test and lint, so their task targets only run when tasks.enabled is set.
+var languageProjectFiles = []string{"go.mod", "package.json", "Cargo.toml", "pyproject.toml", "setup.cfg"}
+
+// taskRunner describes one task runner file format. Command runs a target
+// without echoing the recipe's commands.
+type taskRunner struct {
+	Name    string
+	Files   []string
+	Command string
+	parse   func(path string) ([]string, error)
+}
+
+var taskRunners = []taskRunner{
+	{Name: "make", Files: []string{"GNUmakefile", "Makefile", "makefile"}, Command: "make -s", parse: parseMakefileTargets},
+	{Name: "just", Files: []string{"justfile", "Justfile", ".justfile"}, Command: "just", parse: parseJustfileRecipes},
+	{Name: "task", Files: []string{"Taskfile.yml", "Taskfile.yaml", "taskfile.yml", "taskfile.yaml"}, Command: "task --silent", parse: parseTaskfileTasks},
+}
+
+var (
+	makeTargetLine = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_./-]*(?:\s+[A-Za-z0-9][A-Za-z0-9_./-]*)*)\s*::?(?:[^=]|$)`)
+	justRecipeLine = regexp.MustCompile(`^@?([A-Za-z0-9_-]+)((?:\s+[^:]*)?):(?:[^=]|$)`)
+)
+
+// expandTaskTargets turns a task-targets check into one command check per
+// recognized target of each task runner file in root, using the run's
+// tasks config.
+func expandTaskTargets(root string, spec Check, opts Options) []Check {
+	if !taskTargetsEnabled(root, opts.Tasks) {
+		return nil
+	}
+	phases := map[string]string{}
+	for target, phase := range defaultTaskPhases {
+		phases[target] = phase
+	}
+	for target, phase := range opts.Tasks.Phases {
+		phases[target] = phase
+	}
+	ignored := map[string]bool{}
+	for _, target := range opts.Tasks.Ignore {
+		ignored[target] = true
+	}
+
+	var out []Check
+	for _, runner := range taskRunners {
+		path := findTaskRunnerFile(root, runner.Files)
+		if path == "" {
+			continue
+		}
+		targets, err := runner.parse(path)
+		if err != nil {
+			continue
+		}
+		for _, target := range targets {
+			phase, ok := phases[target]
+			if !ok || phase == "" || ignored[target] || ignored[runner.Name+"-"+target] {
+				continue
+			}
+			out = append(out, taskTargetCheck(spec, runner, target, phase))
+		}
+	}
+	return out
+}
+
+// taskTargetsEnabled applies tasks.enabled, defaulting to off in projects a
+// language plugin already covers so targets like "make test" do not rerun
+// its checks.
+func taskTargetsEnabled(root string, cfg TasksConfig) bool {
+	if cfg.Enabled != nil {
+		return *cfg.Enabled
+	}
+	for _, name := range languageProjectFiles {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func findTaskRunnerFile(root string, names []string) string {
+	for _, name := range names {
+		path := filepath.Join(root, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+func taskTargetCheck(spec Check, runner taskRunner, target string, phase string) Check {
+	command := runner.Command + " " + target
+	check := Check{
+		ID:          runner.Name + "-" + target,
+		Description: "Run " + runner.Name + " " + target,
+		Type:        "command",
+		Phase:       phase,
+		Priority:    spec.Priority,
+		Command:     command,
+		Timeout:     spec.Timeout,
+		Env:         spec.Env,
+	}
+	// Format checks conventionally list the files that need formatting.
+	check.Parser = "regex"
+	if strings.HasPrefix(target, "fmt") || strings.HasPrefix(target, "format") {
+		check.IssuePattern = fileListIssuePattern
+	} else {
+		check.IssuePattern = diagnosticIssuePattern
+	}
+	return check
+}
+
+// parseMakefileTargets returns explicit rule targets, skipping special
+// (.PHONY), pattern (%) and variable assignment lines.
+func parseMakefileTargets(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	seen := map[string]bool{}
+	var targets []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '\t' || line[0] == ' ' || line[0] == '#' {
+			continue
+		}
+		if strings.Contains(line, ":=") || strings.Contains(line, "::=") || strings.HasPrefix(line, "define ") {
+			continue
+		}
+		match := makeTargetLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		for _, target := range strings.Fields(match[1]) {
+			if !seen[target] {
+				seen[target] = true
+				targets = append(targets, target)
+			}
+		}
+	}
+	return targets, scanner.Err()
+}
+
+// parseJustfileRecipes returns recipes that can run without arguments.
+func parseJustfileRecipes(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var recipes []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' || line[0] == '[' {
+			continue
+		}
+		first := strings.Fields(line)[0]
+		if first == "set" || first == "alias" || first == "export" || first == "import" || first == "mod" {
+			continue
+		}
+		match := justRecipeLine.FindStringSubmatch(line)
+		if match == nil || !justParamsOptional(match[2]) {
+			continue
+		}
+		recipes = append(recipes, match[1])
+	}
+	return recipes, scanner.Err()
+}
+
+// justParamsOptional reports whether every recipe parameter has a default or
+// is variadic with "*".
+func justParamsOptional(params string) bool {
+	for _, param := range strings.Fields(params) {
+		if !strings.Contains(param, "=") && !strings.HasPrefix(param, "*") {
+			return false
+		}
+	}
+	return true
+}
+
+type taskfile struct {
+	Tasks map[string]yaml.Node `yaml:"tasks"`
+}
+
+// parseTaskfileTasks returns the task names of a go-task Taskfile, sorted.
+func parseTaskfileTasks(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tf taskfile
+	if err := yaml.Unmarshal(data, &tf); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	var names []string
+	for name := range tf.Tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
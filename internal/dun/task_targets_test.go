@@ -0,0 +1,139 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseMakefileTargets(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "Makefile")
+	writeFile(t, path, `GO ?= go
+BIN := bin/app
+.PHONY: test lint
+
+# build the binary
+build: $(BIN)
+test lint:
+	$(GO) test ./...
+%.o: %.c
+	cc -c $<
+fmt-check:: 
+	gofmt -l .
+test: build
+`)
+	got, err := parseMakefileTargets(path)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"build", "test", "lint", "fmt-check"}) {
+		t.Fatalf("unexpected targets %v", got)
+	}
+}
+
+func TestParseJustfileRecipes(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "justfile")
+	writeFile(t, path, `set shell := ["bash", "-c"]
+version := "1.0"
+alias t := test
+
+# run tests
+test *args:
+    cargo test {{args}}
+
+[private]
+@lint: fmt
+    cargo clippy
+deploy env:
+    ./deploy {{env}}
+check target='all':
+    echo {{target}}
+`)
+	got, err := parseJustfileRecipes(path)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"test", "lint", "check"}) {
+		t.Fatalf("unexpected recipes %v", got)
+	}
+}
+
+func TestExpandTaskTargets(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "Makefile"), "test:\n\tgo test ./...\nlint:\n\tgolangci-lint run\nrelease:\n\t./release.sh\nci:\n\tmake test lint\n")
+	writeFile(t, filepath.Join(root, "Taskfile.yml"), "version: '3'\ntasks:\n  fmt-check:\n    cmds: [gofmt -l .]\n  test:\n    cmds: [go test ./...]\n")
+
+	spec := Check{ID: "task-targets", Type: "task-targets", Timeout: "10m"}
+	got := expandTaskTargets(root, spec, Options{})
+	var ids []string
+	for _, check := range got {
+		ids = append(ids, check.ID+"@"+check.Phase)
+	}
+	if !reflect.DeepEqual(ids, []string{"make-test@test", "make-lint@test", "task-fmt-check@build", "task-test@test"}) {
+		t.Fatalf("unexpected checks %v", ids)
+	}
+	lint := got[1]
+	if lint.Type != "command" || lint.Command != "make -s lint" || lint.Parser != "regex" || lint.Timeout != "10m" {
+		t.Fatalf("unexpected lint check %+v", lint)
+	}
+	if got[2].Command != "task --silent fmt-check" || got[2].IssuePattern != fileListIssuePattern {
+		t.Fatalf("unexpected fmt-check check %+v", got[2])
+	}
+
+	opts := Options{Tasks: TasksConfig{
+		Phases: map[string]string{"ci": "iterate", "lint": "build"},
+		Ignore: []string{"task-test", "fmt-check"},
+	}}
+	got = expandTaskTargets(root, spec, opts)
+	ids = nil
+	for _, check := range got {
+		ids = append(ids, check.ID+"@"+check.Phase)
+	}
+	if !reflect.DeepEqual(ids, []string{"make-test@test", "make-lint@build", "make-ci@iterate"}) {
+		t.Fatalf("unexpected configured checks %v", ids)
+	}
+}
+
+func TestExpandTaskTargetsSkipsLanguageProjects(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "Makefile"), "test:\n\tgo test ./...\n")
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/app\n")
+	spec := Check{ID: "task-targets", Type: "task-targets"}
+
+	if got := expandTaskTargets(root, spec, Options{}); len(got) != 0 {
+		t.Fatalf("expected no checks in a Go project, got %+v", got)
+	}
+	enabled := true
+	if got := expandTaskTargets(root, spec, Options{Tasks: TasksConfig{Enabled: &enabled}}); len(got) != 1 {
+		t.Fatalf("expected make-test when enabled, got %+v", got)
+	}
+	os.Remove(filepath.Join(root, "go.mod"))
+	disabled := false
+	if got := expandTaskTargets(root, spec, Options{Tasks: TasksConfig{Enabled: &disabled}}); len(got) != 0 {
+		t.Fatalf("expected no checks when disabled, got %+v", got)
+	}
+}
+
+func TestFileListParserSkipsRunnerOutput(t *testing.T) {
+	config := CommandConfig{Parser: "regex", IssuePattern: fileListIssuePattern}
+	output := "gofmt -l .\nmain.go\npkg/util.go\nmake: *** [Makefile:3: fmt-check] Error 1\n"
+	issues, _ := parseOutput(config, []byte(output))
+	var paths []string
+	for _, issue := range issues {
+		paths = append(paths, issue.Path)
+	}
+	if !reflect.DeepEqual(paths, []string{"main.go", "pkg/util.go"}) {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+}
+
+func TestRegexParserReadsLineGroup(t *testing.T) {
+	config := CommandConfig{Parser: "regex", IssuePattern: diagnosticIssuePattern}
+	issues, _ := parseOutput(config, []byte("ok\nsrc/app.py:12:4: E501 line too long\n"))
+	if len(issues) != 1 || issues[0].Path != "src/app.py" || issues[0].Line != 12 || issues[0].Summary != "E501 line too long" {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+}
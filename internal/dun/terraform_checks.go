@@ -0,0 +1,195 @@
+package dun
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// terraformValidateOutput is the `terraform validate -json` report.
+type terraformValidateOutput struct {
+	Valid       bool `json:"valid"`
+	Diagnostics []struct {
+		Severity string `json:"severity"`
+		Summary  string `json:"summary"`
+		Detail   string `json:"detail"`
+		Range    *struct {
+			Filename string `json:"filename"`
+			Start    struct {
+				Line int `json:"line"`
+			} `json:"start"`
+		} `json:"range"`
+	} `json:"diagnostics"`
+}
+
+func terraformPreflight(root string, def CheckDefinition) ([]string, CheckResult, bool, error) {
+	dirs, err := terraformDirs(root)
+	if err != nil {
+		return nil, CheckResult{}, false, err
+	}
+	if len(dirs) == 0 {
+		return nil, CheckResult{ID: def.ID, Status: "skip", Signal: "no Terraform files"}, false, nil
+	}
+	if _, err := exec.LookPath("terraform"); err != nil {
+		return nil, CheckResult{
+			ID:     def.ID,
+			Status: "skip",
+			Signal: "terraform missing",
+			Detail: "terraform not found on PATH",
+			Next:   "Install Terraform (https://developer.hashicorp.com/terraform/install)",
+		}, false, nil
+	}
+	return dirs, CheckResult{}, true, nil
+}
+
+// terraformDirs lists directories holding *.tf files, skipping hidden
+// directories such as .terraform and sub-projects.
+func terraformDirs(root string) ([]string, error) {
+	seen := map[string]bool{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if skipTreeDir(root, path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) == ".tf" {
+			rel, err := filepath.Rel(root, filepath.Dir(path))
+			if err != nil {
+				return err
+			}
+			seen[filepath.ToSlash(rel)] = true
+		}
+		return nil
+	})
+	var dirs []string
+	for dir := range seen {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs, err
+}
+
+func runTerraformFmt(root string, def CheckDefinition, config TerraformCheckConfig) (CheckResult, error) {
+	if _, res, ok, err := terraformPreflight(root, def); err != nil || !ok {
+		return res, err
+	}
+	command := "terraform fmt -recursive"
+	run, err := runToolCommand(root, config.Timeout, config.Env, "terraform", "fmt", "-check", "-recursive", "-list=true")
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if run.TimedOut || run.ExitCode == 0 {
+		return toolExitResult(def, run, command, "terraform fmt"), nil
+	}
+	var issues []Issue
+	for _, line := range strings.Split(string(run.Stdout), "\n") {
+		path := strings.TrimSpace(line)
+		if path == "" {
+			continue
+		}
+		path = toolRelPath(root, path)
+		issues = append(issues, Issue{
+			ID:       "terraform-fmt:" + path,
+			Summary:  "not formatted: " + path,
+			Path:     path,
+			Severity: "fail",
+		})
+	}
+	if len(issues) == 0 {
+		return toolExitResult(def, run, command, "terraform fmt"), nil
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: "fail",
+		Signal: fmt.Sprintf("%d Terraform files need formatting", len(issues)),
+		Detail: issues[0].Path,
+		Next:   command,
+		Issues: issues,
+	}, nil
+}
+
+// runTerraformValidate validates each directory with Terraform files.
+// Directories that were never initialized report warnings that point at
+// terraform init rather than failures.
+func runTerraformValidate(root string, def CheckDefinition, config TerraformCheckConfig) (CheckResult, error) {
+	dirs, res, ok, err := terraformPreflight(root, def)
+	if err != nil || !ok {
+		return res, err
+	}
+	var issues []Issue
+	for _, dir := range dirs {
+		run, err := runToolCommand(filepath.Join(root, dir), config.Timeout, config.Env, "terraform", "validate", "-json", "-no-color")
+		if err != nil {
+			return CheckResult{}, err
+		}
+		if run.TimedOut {
+			return CheckResult{ID: def.ID, Status: "fail", Signal: "terraform validate timed out", Next: "terraform validate"}, nil
+		}
+		var report terraformValidateOutput
+		if err := json.Unmarshal(run.Stdout, &report); err != nil {
+			if run.ExitCode == 0 {
+				continue
+			}
+			issues = append(issues, Issue{
+				ID:       "terraform:" + dir,
+				Summary:  "terraform validate failed in " + dir,
+				Path:     dir,
+				Severity: "fail",
+				Detail:   trimOutput(run.Combined()),
+			})
+			continue
+		}
+		for _, diag := range report.Diagnostics {
+			issue := Issue{
+				Summary: diag.Summary,
+				Path:    dir,
+				Detail:  diag.Detail,
+			}
+			if diag.Range != nil {
+				issue.Path = filepath.ToSlash(filepath.Join(dir, diag.Range.Filename))
+				issue.Line = diag.Range.Start.Line
+			}
+			switch {
+			case strings.Contains(diag.Detail, "terraform init"):
+				issue.Severity = "warn"
+				issue.Summary += " (run terraform init in " + dir + ")"
+			case diag.Severity == "error":
+				issue.Severity = "fail"
+			default:
+				issue.Severity = "warn"
+			}
+			issue.ID = fmt.Sprintf("terraform:%s:%d", issue.Path, issue.Line)
+			issues = append(issues, issue)
+		}
+	}
+	if len(issues) == 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "pass",
+			Signal: fmt.Sprintf("%d Terraform modules valid", len(dirs)),
+		}, nil
+	}
+	status := "warn"
+	for _, issue := range issues {
+		if issue.Severity == "fail" {
+			status = "fail"
+			break
+		}
+	}
+	return CheckResult{
+		ID:     def.ID,
+		Status: status,
+		Signal: fmt.Sprintf("%d Terraform diagnostics", len(issues)),
+		Detail: issues[0].Path + ": " + issues[0].Summary,
+		Next:   "terraform validate",
+		Issues: issues,
+	}, nil
+}
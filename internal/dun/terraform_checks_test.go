@@ -0,0 +1,93 @@
+package dun
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTerraformStub(t *testing.T, script string) {
+	t.Helper()
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "terraform"), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatalf("write stub: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestTerraformChecksSkip(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	root := t.TempDir()
+	res, err := runTerraformFmt(root, CheckDefinition{ID: "terraform-fmt"}, TerraformCheckConfig{})
+	if err != nil || res.Status != "skip" || res.Signal != "no Terraform files" {
+		t.Fatalf("expected skip without files, got %+v %v", res, err)
+	}
+	writeFile(t, filepath.Join(root, "main.tf"), "")
+	res, err = runTerraformValidate(root, CheckDefinition{ID: "terraform-validate"}, TerraformCheckConfig{})
+	if err != nil || res.Status != "skip" || res.Signal != "terraform missing" {
+		t.Fatalf("expected skip without terraform, got %+v %v", res, err)
+	}
+}
+
+func TestTerraformFmtReportsFiles(t *testing.T) {
+	writeTerraformStub(t, "echo main.tf\necho modules/net/vars.tf\nexit 3\n")
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "main.tf"), "")
+
+	res, err := runTerraformFmt(root, CheckDefinition{ID: "terraform-fmt"}, TerraformCheckConfig{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 2 || res.Issues[1].Path != "modules/net/vars.tf" || res.Next != "terraform fmt -recursive" {
+		t.Fatalf("expected fmt issues, got %+v", res)
+	}
+}
+
+func TestTerraformValidateParsesDiagnostics(t *testing.T) {
+	writeTerraformStub(t, `cat <<'JSON'
+{"valid":false,"diagnostics":[{"severity":"error","summary":"Unsupported argument","detail":"An argument named \"foo\" is not expected here.","range":{"filename":"main.tf","start":{"line":4}}},{"severity":"error","summary":"Missing required provider","detail":"Run terraform init to install it."}]}
+JSON
+exit 1
+`)
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "infra"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, filepath.Join(root, "infra", "main.tf"), "")
+
+	res, err := runTerraformValidate(root, CheckDefinition{ID: "terraform-validate"}, TerraformCheckConfig{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Status != "fail" || len(res.Issues) != 2 {
+		t.Fatalf("expected two diagnostics, got %+v", res)
+	}
+	if res.Issues[0].ID != "terraform:infra/main.tf:4" || res.Issues[0].Severity != "fail" {
+		t.Fatalf("unexpected issue %+v", res.Issues[0])
+	}
+	if res.Issues[1].Severity != "warn" || res.Issues[1].Path != "infra" {
+		t.Fatalf("expected init warning, got %+v", res.Issues[1])
+	}
+}
+
+func TestIACPluginActivatesOnNestedFiles(t *testing.T) {
+	for _, file := range []string{"infra/envs/prod/main.tf", "deploy/docker/api/Dockerfile"} {
+		root := t.TempDir()
+		path := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		writeFile(t, path, "")
+		plan, err := PlanRepo(root, DefaultOptions())
+		if err != nil {
+			t.Fatalf("plan: %v", err)
+		}
+		found := false
+		for _, check := range plan.Checks {
+			found = found || check.PluginID == "iac"
+		}
+		if !found {
+			t.Fatalf("expected iac checks for %s", file)
+		}
+	}
+}
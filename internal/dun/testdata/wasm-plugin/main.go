@@ -0,0 +1,49 @@
+// Command wasm-plugin is a WASI check type used by the wasm plugin tests.
+// Build with GOOS=wasip1 GOARCH=wasm.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type request struct {
+	Protocol int    `json:"protocol"`
+	Root     string `json:"root"`
+	Check    struct {
+		ID     string         `json:"id"`
+		Config map[string]any `json:"config"`
+	} `json:"check"`
+}
+
+func main() {
+	var req request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	switch req.Check.Config["mode"] {
+	case "loop":
+		for {
+		}
+	case "crash":
+		fmt.Println("not json")
+		os.Exit(3)
+	}
+
+	entries, err := os.ReadDir(req.Root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	writeErr := os.WriteFile(filepath.Join(req.Root, "written.txt"), []byte("x"), 0644)
+	json.NewEncoder(os.Stdout).Encode(map[string]any{
+		"protocol": req.Protocol,
+		"result": map[string]any{
+			"status": "pass",
+			"signal": fmt.Sprintf("%d entries, read-only=%v", len(entries), writeErr != nil),
+		},
+	})
+}
@@ -0,0 +1,93 @@
+package dun
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// toolRun is the outcome of running an external linter or test runner.
+type toolRun struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+	TimedOut bool
+}
+
+// Combined returns stdout followed by stderr.
+func (r toolRun) Combined() []byte {
+	return append(append([]byte{}, r.Stdout...), r.Stderr...)
+}
+
+// runToolCommand runs name with args in dir under the check timeout. A non-zero
+// exit is not an error: linters and test runners use it to report findings.
+func runToolCommand(dir string, timeout string, env map[string]string, name string, args ...string) (toolRun, error) {
+	return runToolCommandInput(dir, timeout, env, nil, name, args...)
+}
+
+// runToolCommandInput is runToolCommand with stdin.
+func runToolCommandInput(dir string, timeout string, env map[string]string, stdin []byte, name string, args ...string) (toolRun, error) {
+	config := CommandConfig{Timeout: timeout, Env: env}
+	limit := commandTimeout(config)
+	ctx, cancel := context.WithTimeout(context.Background(), limit)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = buildCommandEnv(config)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	run := toolRun{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}
+	if ctx.Err() == context.DeadlineExceeded {
+		run.TimedOut = true
+		run.ExitCode = -1
+		return run, nil
+	}
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return run, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	run.ExitCode = exitCodeFromError(err)
+	return run, nil
+}
+
+// toolCommandLine renders a command for the Next hint.
+func toolCommandLine(name string, args ...string) string {
+	return strings.TrimSpace(name + " " + strings.Join(args, " "))
+}
+
+// toolExitResult reports a tool run without parseable output by exit code.
+func toolExitResult(def CheckDefinition, run toolRun, command string, label string) CheckResult {
+	if run.TimedOut {
+		return CheckResult{ID: def.ID, Status: "fail", Signal: label + " timed out", Next: command}
+	}
+	if run.ExitCode != 0 {
+		return CheckResult{
+			ID:     def.ID,
+			Status: "fail",
+			Signal: label + " failed",
+			Detail: trimOutput(run.Combined()),
+			Next:   command,
+		}
+	}
+	return CheckResult{ID: def.ID, Status: "pass", Signal: label + " passed"}
+}
+
+// toolRelPath makes a tool-reported path relative to root.
+func toolRelPath(root string, path string) string {
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(root, path); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(path)
+}
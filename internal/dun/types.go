@@ -14,6 +14,12 @@ type Options struct {
 	AgentMode         string
 	AutomationMode    string
 	CoverageThreshold int
+	GoAffected        bool           // Run Go checks only on packages affected by changes
+	GoGenerateDrift   bool           // Run go-generate-drift, which runs go generate in a copy of the repo
+	ChangedBase       string         // Git ref to diff against for affected mode (default HEAD)
+	PluginIntegrity   string         // off|warn|fail when plugins drift from .dun/plugins.lock
+	Tasks             TasksConfig    // Task runner targets to turn into checks
+	Config            map[string]any // Raw config settings, for plugin check templates
 }
 
 type Result struct {
@@ -58,21 +64,63 @@ type CheckDefinition struct {
 	Priority    int
 	Conditions  []Rule
 	PluginID    string
+	Scope       string // Sub-project the check runs in; ID is then "Scope:<check id>"
 }
 
 type Plugin struct {
 	Manifest Manifest
 	FS       fs.FS
 	Base     string
+	Dir      string // On-disk plugin directory; empty for builtins
+	Source   string // builtin|cache|user|project; set by LoadPlugins
+
+	partials map[string]promptPartial // Prompt partials of the loaded plugin set
+	repoRoot string                   // Repo the set was loaded for, whose .dun/prompts apply
 }
 
 type Manifest struct {
-	ID          string    `yaml:"id"`
-	Version     string    `yaml:"version"`
-	Description string    `yaml:"description"`
-	Priority    int       `yaml:"priority"`
-	Triggers    []Trigger `yaml:"triggers"`
-	Checks      []Check   `yaml:"checks"`
+	ID          string          `yaml:"id"`
+	Version     string          `yaml:"version"`
+	Description string          `yaml:"description"`
+	Priority    int             `yaml:"priority"`
+	Triggers    []Trigger       `yaml:"triggers"`
+	Checks      []Check         `yaml:"checks"`
+	CheckTypes  []CheckTypeSpec `yaml:"check_types"`
+	Requires    Requirements    `yaml:"requires"`
+	Commands    []PluginCommand `yaml:"commands"`
+	// Partials maps a name to a template file in the plugin. Prompt
+	// templates of any plugin include it as {{ template "<id>/<name>" . }}.
+	Partials map[string]string `yaml:"partials"`
+}
+
+// Requirements are version constraints a plugin needs to load, such as
+// dun: ">=0.4" or plugins: {helix: "^2"}.
+type Requirements struct {
+	Dun     string            `yaml:"dun"`
+	Plugins map[string]string `yaml:"plugins"`
+}
+
+// CheckTypeSpec declares a check type implemented by an executable or a WASI
+// module shipped in the plugin directory, speaking the exec plugin protocol.
+type CheckTypeSpec struct {
+	Name     string   `yaml:"name"`
+	Exec     string   `yaml:"exec"`     // Path relative to the plugin directory
+	Wasm     string   `yaml:"wasm"`     // WASI module relative to the plugin directory
+	Args     []string `yaml:"args"`     // Extra arguments
+	Timeout  string   `yaml:"timeout"`  // Default timeout (default "5m")
+	Protocol int      `yaml:"protocol"` // Protocol version (default 1)
+}
+
+// PluginCommand is a CLI subcommand run as "dun <plugin-id> <name> [args]".
+// Run is a shell command rendered with text/template (.Root, .Args,
+// .PluginDir) that also receives the arguments as "$@"; Exec is an executable
+// in the plugin directory that receives them as arguments.
+type PluginCommand struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Usage       string `yaml:"usage"` // Argument synopsis shown in help
+	Run         string `yaml:"run"`
+	Exec        string `yaml:"exec"` // Path relative to the plugin directory
 }
 
 type Trigger struct {
@@ -94,6 +142,10 @@ type Check struct {
 	Command        string   `yaml:"command"`
 	Prompt         string   `yaml:"prompt"`
 	ResponseSchema string   `yaml:"response_schema"`
+	Fix            string   `yaml:"fix"`
+
+	// Free-form settings passed to exec plugin check types
+	Config map[string]any `yaml:"config"`
 
 	// Command check fields (US-012)
 	Parser       string            `yaml:"parser"`        // text|lines|json|json-lines|regex
@@ -106,6 +158,29 @@ type Check struct {
 	IssuePattern string            `yaml:"issue_pattern"` // Regex pattern for issues
 	IssueFields  IssueFieldMap     `yaml:"issue_fields"`  // Field mapping for JSON
 
+	// Go test fields
+	Race         bool   `yaml:"race"`          // Run with -race
+	Shuffle      string `yaml:"shuffle"`       // on|off|<seed>
+	Count        int    `yaml:"count"`         // Run each test N times
+	Failfast     bool   `yaml:"failfast"`      // Stop after first failure
+	FlakeRetries int    `yaml:"flake_retries"` // Re-run failing tests to detect flakes
+
+	// Go benchmark fields
+	Bench        string   `yaml:"bench"`         // -bench pattern (default ".")
+	Packages     []string `yaml:"packages"`      // Packages to benchmark (default ./...); cargo -p for rust checks
+	Tolerance    float64  `yaml:"tolerance"`     // Allowed median regression in percent (default 10)
+	BaselineFile string   `yaml:"baseline_file"` // default: .dun/bench/<check-id>.json
+
+	// Go build matrix fields
+	Targets   []BuildTarget `yaml:"targets"`    // GOOS/GOARCH/tags combinations
+	GoCommand string        `yaml:"go_command"` // build|vet (default build)
+
+	// Import-rules fields
+	ImportRules []ImportRule `yaml:"import_rules"`
+
+	// Contract lint fields (openapi-lint, proto-lint)
+	Files []string `yaml:"files"` // Globs to lint (default: discovered)
+
 	// Spec-binding fields (spec-enforcement checks)
 	Bindings     SpecBindings  `yaml:"bindings"`
 	BindingRules []BindingRule `yaml:"binding_rules"`
@@ -145,6 +220,27 @@ type IssueFieldMap struct {
 	Severity string `yaml:"severity"`
 }
 
+// ImportRule restricts Go package dependencies. Use Deny for "Package must not
+// import X" and Import with Only for "only these packages may import X".
+// Patterns are import paths, optionally relative to the main module, where
+// "..." matches anything and "*" one path element.
+type ImportRule struct {
+	Package    string   `yaml:"package"`    // Packages the rule applies to (default: all)
+	Deny       []string `yaml:"deny"`       // Imports these packages must not use
+	Allow      []string `yaml:"allow"`      // Packages exempt from this rule
+	Import     string   `yaml:"import"`     // Restricted import
+	Only       []string `yaml:"only"`       // Packages allowed to use Import
+	Transitive bool     `yaml:"transitive"` // Also flag indirect dependencies
+	Reason     string   `yaml:"reason"`     // Shown instead of the generated description
+}
+
+// BuildTarget is one go-build-matrix target. Builds run with CGO_ENABLED=0.
+type BuildTarget struct {
+	GOOS   string   `yaml:"goos"`
+	GOARCH string   `yaml:"goarch"`
+	Tags   []string `yaml:"tags"`
+}
+
 // BindingRule defines a rule for spec-binding checks.
 type BindingRule struct {
 	Type        string  `yaml:"type"`         // bidirectional-coverage, no-orphan-code, no-orphan-specs
@@ -185,7 +281,7 @@ type ContractsConfig struct {
 }
 
 type ContractRule struct {
-	Type string `yaml:"type"` // all-providers-implemented, all-consumers-satisfied, no-circular-dependencies
+	Type string `yaml:"type"` // all-providers-implemented, all-consumers-satisfied, no-circular-dependencies, consumer-operations-exist
 }
 
 type TrackingConfig struct {
@@ -0,0 +1,151 @@
+package dun
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// wasmGuestRoot is where a WASI plugin sees the repository, read-only.
+const wasmGuestRoot = "/repo"
+
+// wasmModules holds one runtime and compiled module per WASI plugin file, so
+// a module is compiled once per run however many checks use it. A file whose
+// content changed is recompiled.
+var wasmModules = struct {
+	sync.Mutex
+	byPath map[string]*wasmModule
+}{byPath: map[string]*wasmModule{}}
+
+type wasmModule struct {
+	hash     [sha256.Size]byte
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+}
+
+func loadWasmModule(path string) (*wasmModule, error) {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(code)
+
+	wasmModules.Lock()
+	defer wasmModules.Unlock()
+	if module, ok := wasmModules.byPath[path]; ok {
+		if module.hash == hash {
+			return module, nil
+		}
+		module.runtime.Close(context.Background())
+		delete(wasmModules.byPath, path)
+	}
+	ctx := context.Background()
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+	compiled, err := runtime.CompileModule(ctx, code)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("compile %s: %w", filepath.Base(path), err)
+	}
+	module := &wasmModule{hash: hash, runtime: runtime, compiled: compiled}
+	wasmModules.byPath[path] = module
+	return module, nil
+}
+
+// closeWasmModules closes every cached runtime, releasing compiled code.
+// Runs call it when their checks are done.
+func closeWasmModules() {
+	wasmModules.Lock()
+	defer wasmModules.Unlock()
+	for path, module := range wasmModules.byPath {
+		module.runtime.Close(context.Background())
+		delete(wasmModules.byPath, path)
+	}
+}
+
+// wasmCheckType runs a check type implemented by a WASI module. The module
+// speaks the exec plugin protocol on stdin/stdout, sees the repository
+// read-only at /repo and has no network or other host access.
+type wasmCheckType struct {
+	execCheckType
+}
+
+func (t wasmCheckType) Run(root string, def CheckDefinition, cfg CheckConfig, opts Options, _ Plugin) (CheckResult, error) {
+	config, ok := cfg.(execCheckConfig)
+	if !ok {
+		return CheckResult{}, fmt.Errorf("%s config missing", t.name)
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	input, err := t.request(wasmGuestRoot, def, config.Spec, opts)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	module, err := loadWasmModule(t.path)
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("load %s: %w", t.name, err)
+	}
+
+	timeout := t.timeout
+	if config.Spec.Timeout != "" {
+		timeout = config.Spec.Timeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout(CommandConfig{Timeout: timeout}))
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	moduleConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(append([]string{filepath.Base(t.path)}, t.args...)...).
+		WithEnv("DUN_PROTOCOL", strconv.Itoa(t.protocol)).
+		WithStdin(bytes.NewReader(input)).
+		WithStdout(&stdout).
+		WithStderr(&stderr).
+		WithFSConfig(wazero.NewFSConfig().WithReadOnlyDirMount(absRoot, wasmGuestRoot)).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithSysNanosleep()
+	for k, v := range config.Spec.Env {
+		moduleConfig = moduleConfig.WithEnv(k, v)
+	}
+
+	run := toolRun{}
+	instance, err := module.runtime.InstantiateModule(ctx, module.compiled, moduleConfig)
+	if instance != nil {
+		instance.Close(context.Background())
+	}
+	run.Stdout, run.Stderr = stdout.Bytes(), stderr.Bytes()
+	command := "wasm " + t.path
+	var exitErr *sys.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == sys.ExitCodeDeadlineExceeded:
+		return CheckResult{ID: def.ID, Status: "fail", Signal: t.name + " plugin timed out", Next: command}, nil
+	case errors.As(err, &exitErr):
+		run.ExitCode = int(exitErr.ExitCode())
+	default:
+		return CheckResult{
+			ID:     def.ID,
+			Status: "fail",
+			Signal: t.name + " plugin could not run",
+			Detail: err.Error(),
+			Next:   "Fix plugin " + t.pluginID + " (" + command + ")",
+		}, nil
+	}
+	return t.decodeResponse(def, run, command), nil
+}
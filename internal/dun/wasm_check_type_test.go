@@ -0,0 +1,138 @@
+package dun
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildWasmPlugin compiles testdata/wasm-plugin for wasip1 into a plugin
+// directory declaring it as check type typeName.
+func buildWasmPlugin(t *testing.T, typeName string) string {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	dir := t.TempDir()
+	cmd := exec.Command("go", "build", "-o", filepath.Join(dir, "check.wasm"), "./testdata/wasm-plugin")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm", "CGO_ENABLED=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("build wasm plugin: %v\n%s", err, out)
+	}
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), "id: wasm-test\nversion: \"1\"\ncheck_types:\n  - name: "+typeName+"\n    wasm: check.wasm\n")
+	t.Cleanup(func() { delete(checkRegistry, typeName) })
+	return dir
+}
+
+func runWasmType(t *testing.T, typeName string, root string, spec Check) CheckResult {
+	t.Helper()
+	handler, ok := LookupCheckType(typeName)
+	if !ok {
+		t.Fatalf("check type %s not registered", typeName)
+	}
+	cfg, err := handler.Decode(spec)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	res, err := handler.Run(root, CheckDefinition{ID: spec.ID}, cfg, Options{}, Plugin{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return res
+}
+
+func TestWasmCheckType(t *testing.T) {
+	dir := buildWasmPlugin(t, "wasm-sandbox")
+	loadCheckTypePlugin(t, dir)
+	if _, ok := checkRegistry["wasm-sandbox"].(wasmCheckType); !ok {
+		t.Fatalf("expected wasm check type, got %T", checkRegistry["wasm-sandbox"])
+	}
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a.txt"), "a")
+	writeFile(t, filepath.Join(root, "b.txt"), "b")
+
+	res := runWasmType(t, "wasm-sandbox", root, Check{ID: "sandboxed"})
+	if res.ID != "sandboxed" || res.Status != "pass" || res.Signal != "2 entries, read-only=true" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if fileExists(filepath.Join(root, "written.txt")) {
+		t.Fatalf("wasm plugin wrote to the repository")
+	}
+
+	res = runWasmType(t, "wasm-sandbox", root, Check{ID: "crash", Config: map[string]any{"mode": "crash"}})
+	if res.Status != "fail" || !strings.Contains(res.Signal, "invalid JSON (exit 3)") {
+		t.Fatalf("expected invalid JSON failure, got %+v", res)
+	}
+
+	res = runWasmType(t, "wasm-sandbox", root, Check{ID: "loop", Timeout: "200ms", Config: map[string]any{"mode": "loop"}})
+	if res.Status != "fail" || !strings.Contains(res.Signal, "timed out") {
+		t.Fatalf("expected timeout, got %+v", res)
+	}
+}
+
+func TestPluginCheckTypeNeedsExecOrWasm(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "plugin.yaml"), "id: both\nversion: \"1\"\ncheck_types:\n  - name: both-kinds\n    exec: run\n    wasm: run.wasm\n")
+	if _, err := loadPluginFromPath(dir); err == nil || !strings.Contains(err.Error(), "one of exec or wasm") {
+		t.Fatalf("expected exec/wasm error, got %v", err)
+	}
+}
+
+func TestPluginFilesMustStayInPluginDir(t *testing.T) {
+	root := t.TempDir()
+	shared := filepath.Join(root, "shared")
+	dir := filepath.Join(root, "escape")
+	for _, d := range []string{shared, dir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	writeFile(t, filepath.Join(shared, "run.sh"), "#!/bin/sh\n")
+	for _, manifest := range []string{
+		"check_types:\n  - name: escape-type\n    exec: ../shared/run.sh\n",
+		"check_types:\n  - name: escape-type\n    wasm: ../shared/run.sh\n",
+		"commands:\n  - name: run\n    exec: ../shared/run.sh\n",
+	} {
+		writeFile(t, filepath.Join(dir, "plugin.yaml"), "id: escape\nversion: \"1\"\n"+manifest)
+		if _, err := loadPluginFromPath(dir); err == nil || !strings.Contains(err.Error(), "outside the plugin directory") {
+			t.Fatalf("expected outside-directory error for %q, got %v", manifest, err)
+		}
+	}
+}
+
+func TestLoadWasmModuleRecompilesChangedFiles(t *testing.T) {
+	t.Cleanup(closeWasmModules)
+	path := filepath.Join(t.TempDir(), "check.wasm")
+	empty := "\x00asm\x01\x00\x00\x00"
+	writeFile(t, path, empty)
+
+	first, err := loadWasmModule(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if again, err := loadWasmModule(path); err != nil || again != first {
+		t.Fatalf("expected cached module, got %p (%v)", again, err)
+	}
+
+	// Same module plus a custom section.
+	writeFile(t, path, empty+"\x00\x06\x04dunx\x00")
+	changed, err := loadWasmModule(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if changed == first {
+		t.Fatal("expected a recompiled module after the file changed")
+	}
+	if _, err := first.runtime.CompileModule(context.Background(), []byte(empty)); err == nil {
+		t.Fatal("expected the replaced runtime to be closed")
+	}
+
+	closeWasmModules()
+	if len(wasmModules.byPath) != 0 {
+		t.Fatalf("expected empty cache, got %d modules", len(wasmModules.byPath))
+	}
+}
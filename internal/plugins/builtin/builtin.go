@@ -5,7 +5,7 @@ import (
 	"io/fs"
 )
 
-//go:embed helix/** git/** go/** beads/** security/**
+//go:embed helix/** git/** go/** node/** python/** rust/** tasks/** shell/** iac/** beads/** security/**
 var builtinFS embed.FS
 
 type Entry struct {
@@ -31,6 +31,36 @@ func Plugins() []Entry {
 			FS:   builtinFS,
 			Base: "go",
 		},
+		{
+			ID:   "node",
+			FS:   builtinFS,
+			Base: "node",
+		},
+		{
+			ID:   "python",
+			FS:   builtinFS,
+			Base: "python",
+		},
+		{
+			ID:   "rust",
+			FS:   builtinFS,
+			Base: "rust",
+		},
+		{
+			ID:   "tasks",
+			FS:   builtinFS,
+			Base: "tasks",
+		},
+		{
+			ID:   "shell",
+			FS:   builtinFS,
+			Base: "shell",
+		},
+		{
+			ID:   "iac",
+			FS:   builtinFS,
+			Base: "iac",
+		},
 		{
 			ID:   "beads",
 			FS:   builtinFS,
@@ -0,0 +1,31 @@
+package update
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DownloadTimeout bounds a whole Download, including reading the body.
+const DownloadTimeout = 5 * time.Minute
+
+var downloadClient HTTPClient = &http.Client{Timeout: DownloadTimeout}
+
+// Download fetches url and returns its body. Responses other than 200 OK are
+// errors. The caller closes the body.
+func Download(url string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
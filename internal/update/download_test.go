@@ -0,0 +1,40 @@
+package update
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("payload"))
+	}))
+	defer server.Close()
+
+	body, err := Download(server.URL + "/plugin.tar.gz")
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || string(data) != "payload" {
+		t.Fatalf("unexpected body %q: %v", data, err)
+	}
+
+	if _, err := Download(server.URL + "/missing"); err == nil {
+		t.Error("expected error for 404")
+	}
+}
+
+func TestDownloadClientHasTimeout(t *testing.T) {
+	client, ok := downloadClient.(*http.Client)
+	if !ok || client.Timeout != DownloadTimeout {
+		t.Fatalf("expected http.Client with %v timeout, got %#v", DownloadTimeout, downloadClient)
+	}
+}